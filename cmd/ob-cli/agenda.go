@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var agendaCmd = &cobra.Command{
+	Use:   "agenda",
+	Short: "Show tasks and dated notes by day",
+	Long: `Show open tasks that are due (📅) or scheduled (⏳) in a date range, grouped
+by day after any overdue tasks, together with notes whose frontmatter "date"
+falls in the range.
+
+With --ics, every dated task and note is written to stdout as an iCalendar
+file instead: tasks become VTODO entries and notes all-day VEVENT entries,
+ready to import or serve to a calendar client. With --output, the items
+are written with their day and kind, overdue tasks first.
+
+Examples:
+  ob-cli agenda
+  ob-cli agenda --week
+  ob-cli agenda --from 2024-03-01 --days 3
+  ob-cli agenda --ics > ~/calendars/notes.ics
+  ob-cli agenda --week -o tsv`,
+	Args: cobra.NoArgs,
+	RunE: runAgenda,
+}
+
+var agendaOpts app.AgendaOptions
+
+func init() {
+	agendaCmd.Flags().StringVarP(&agendaOpts.From, "from", "", "", "First day as YYYY-MM-DD (default today)")
+	agendaCmd.Flags().IntVarP(&agendaOpts.Days, "days", "", 1, "Number of days to show")
+	agendaCmd.Flags().BoolVarP(&agendaOpts.Week, "week", "w", false, "Show the next 7 days")
+	agendaCmd.Flags().BoolVarP(&agendaOpts.ICS, "ics", "", false, "Write an iCalendar file of all dated tasks and notes")
+	agendaCmd.Flags().StringVarP(&outputFlag, "output", "o", "", outputUsage)
+
+	rootCmd.AddCommand(agendaCmd)
+}
+
+func runAgenda(cmd *cobra.Command, args []string) error {
+	var err error
+	if agendaOpts.Output, err = app.ParseOutput(outputFlag); err != nil {
+		return err
+	}
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.Agenda(os.Stdout, agendaOpts)
+}
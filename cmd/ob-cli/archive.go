@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var archiveCmd = &cobra.Command{
+	Use:   "archive <note>...",
+	Short: "Move notes into the archive folder",
+	Long: `Move notes into the archive folder, keeping their path: projects/plan.md
+becomes archive/projects/plan.md. The folder is set per vault with
+vaults.<mode>.archive (default "archive"). Links that will no longer
+resolve to the notes are listed.
+
+Examples:
+  ob-cli archive projects/launch
+  ob-cli archive --git projects/launch.md`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeNotes,
+	RunE:              runArchive,
+}
+
+var archiveOpts app.MoveOptions
+
+func init() {
+	archiveCmd.Flags().BoolVarP(&archiveOpts.Git, "git", "g", false, "Stage the move in git (like git mv)")
+	rootCmd.AddCommand(archiveCmd)
+}
+
+func runArchive(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	for _, note := range args {
+		if err := obApp.ArchiveNote(note, archiveOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var captureCmd = &cobra.Command{
+	Use:   "capture [text...]",
+	Short: "Append text to a note without opening an editor",
+	Long: `Append a timestamped bullet to the inbox, today's daily note or a given note.
+Text is taken from the arguments, or from stdin when none are given (or "-").
+
+Examples:
+  ob-cli capture "call Bob re: budget"
+  ob-cli capture --daily --heading Log "deployed v2"
+  echo "read later: https://example.com" | ob-cli capture --to inbox.md`,
+	RunE: runCapture,
+}
+
+var (
+	captureTo          string
+	captureDaily       bool
+	captureHeading     string
+	captureNoTimestamp bool
+	captureCommit      bool
+)
+
+func init() {
+	captureCmd.Flags().StringVarP(&captureTo, "to", "t", "", "Note to append to (default: inbox)")
+	captureCmd.Flags().BoolVarP(&captureDaily, "daily", "D", false, "Append to today's daily note")
+	captureCmd.Flags().StringVarP(&captureHeading, "heading", "H", "", "Append under this heading (created when missing)")
+	captureCmd.Flags().BoolVarP(&captureNoTimestamp, "no-timestamp", "", false, "Do not prefix the bullet with the time")
+	captureCmd.Flags().BoolVarP(&captureCommit, "commit", "c", false, "Commit the note after appending")
+
+	captureCmd.RegisterFlagCompletionFunc("to", completeNote)
+
+	rootCmd.AddCommand(captureCmd)
+}
+
+func runCapture(cmd *cobra.Command, args []string) error {
+	text := strings.Join(args, " ")
+	if len(args) == 0 || text == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+		text = string(data)
+	}
+
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	return obApp.Capture(app.CaptureOptions{
+		Text:      text,
+		Target:    captureTo,
+		Daily:     captureDaily,
+		Heading:   captureHeading,
+		Timestamp: !captureNoTimestamp,
+		Commit:    captureCommit,
+	})
+}
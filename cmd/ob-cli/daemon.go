@@ -0,0 +1,43 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Watch the vault and keep its note, link and tag index hot",
+	Long: `Run in the foreground, watching the vault for changes and keeping an index of
+notes, links and tags in memory. Other ob-cli invocations for the same vault
+query the index over a unix socket instead of scanning the vault, and scan
+as usual when the daemon is not running.
+
+The daemon also fetches from the git remote periodically, so interactive
+runs skip their own background fetch while it runs.
+
+The socket lives under $XDG_RUNTIME_DIR/ob-cli/.
+
+Examples:
+  ob-cli daemon
+  ob-cli --mode tips daemon --fetch-interval 15m`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+var daemonOpts app.DaemonOptions
+
+func init() {
+	daemonCmd.Flags().DurationVarP(&daemonOpts.FetchInterval, "fetch-interval", "", 5*time.Minute, "Time between git fetches (0 disables)")
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.RunDaemon(daemonOpts)
+}
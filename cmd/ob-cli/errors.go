@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/plugin"
+)
+
+// Error formats for --error-format
+const (
+	errorFormatText = "text"
+	errorFormatJSON = "json"
+)
+
+var errorFormatFlag string
+
+// exitCodes maps each kind of failure to its documented exit code and the
+// kind reported by --error-format json. The first matching kind wins.
+var exitCodes = []struct {
+	kind error
+	name string
+	code int
+}{
+	{errs.ErrCancelled, "cancelled", 130},
+	{context.Canceled, "cancelled", 130},
+	{errs.ErrUsage, "usage", 2},
+	{errs.ErrVaultNotFound, "vault_not_found", 3},
+	{errs.ErrToolMissing, "tool_missing", 4},
+	{exec.ErrNotFound, "tool_missing", 4},
+	{errs.ErrEditorFailed, "editor_failed", 5},
+	{errs.ErrGitConflict, "git_conflict", 6},
+	{errs.ErrInvalidPath, "invalid_path", 7},
+	{errs.ErrHookFailed, "hook_failed", 8},
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&errorFormatFlag, "error-format", "", errorFormatText, "How to report errors on stderr: text or json")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return errs.New(errs.ErrUsage, "%w", err)
+	})
+}
+
+// checkErrorFormat validates --error-format before the command runs
+func checkErrorFormat() error {
+	if errorFormatFlag != errorFormatText && errorFormatFlag != errorFormatJSON {
+		return errs.New(errs.ErrUsage, "invalid error format %q: use text or json", errorFormatFlag)
+	}
+	return nil
+}
+
+// markUsageErrors makes argument validation errors of cmd and its
+// subcommands usage errors
+func markUsageErrors(cmd *cobra.Command) {
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			if err := validate(cmd, args); err != nil {
+				return errs.New(errs.ErrUsage, "%w", err)
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// exitCode returns the exit code and kind name for an error. A failed
+// plugin's exit status is passed through.
+func exitCode(err error) (int, string) {
+	var pluginErr *plugin.ExitError
+	if errors.As(err, &pluginErr) {
+		return pluginErr.Code, "plugin"
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.kind) {
+			return c.code, c.name
+		}
+	}
+	return 1, "error"
+}
+
+// reportError prints err to stderr in the --error-format and returns the
+// exit code. A cancelled run or a failed plugin prints nothing in text
+// format.
+func reportError(err error) int {
+	code, kind := exitCode(err)
+	if errorFormatFlag == errorFormatJSON {
+		json.NewEncoder(os.Stderr).Encode(struct {
+			Error    string `json:"error"`
+			Kind     string `json:"kind"`
+			ExitCode int    `json:"exit_code"`
+		}{err.Error(), kind, code})
+		return code
+	}
+
+	switch kind {
+	case "cancelled", "plugin": // A plugin reports its own errors
+	case "usage":
+		fmt.Fprintf(os.Stderr, "Error: %v\nRun 'ob-cli --help' for usage.\n", err)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	return code
+}
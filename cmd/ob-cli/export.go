@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/export"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the vault to other formats",
+}
+
+var exportHTMLCmd = &cobra.Command{
+	Use:   "html",
+	Short: "Render the vault as a static HTML site",
+	Long: `Render notes to a read-only static HTML site. Wikilinks and markdown links
+become relative links, embedded images and linked files are copied, every
+page lists its backlinks and tags.html indexes all tags. Notes with
+"publish: false" in their frontmatter are left out.
+
+The output only depends on the vault contents, so it can be committed and
+diffed. Files removed from the vault are not deleted from the output
+directory.
+
+Examples:
+  ob-cli export html --out site/
+  ob-cli export html --out site/ --path projects --title "Projects"`,
+	Args: cobra.NoArgs,
+	RunE: runExportHTML,
+}
+
+var exportOpts export.Options
+
+func init() {
+	exportHTMLCmd.Flags().StringVarP(&exportOpts.Out, "out", "o", "", "Output directory")
+	exportHTMLCmd.Flags().StringVarP(&exportOpts.Path, "path", "", "", "Only export notes under this folder")
+	exportHTMLCmd.Flags().StringVarP(&exportOpts.Title, "title", "", "", "Site title (default: vault directory name)")
+	exportHTMLCmd.MarkFlagRequired("out")
+
+	exportCmd.AddCommand(exportHTMLCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportHTML(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ExportHTML(exportOpts)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the note link graph or report its metrics",
+	Long: `Export how notes connect: notes are nodes, wikilinks and markdown links
+between them are edges, embeds are marked separately. Tags can be added as
+extra nodes. Links to missing notes and to attachments are left out.
+
+With --metrics a report is printed instead: note and link counts, connected
+components, orphan notes, the most connected notes and the notes with the
+most backlinks.
+
+Examples:
+  ob-cli graph | dot -Tsvg > notes.svg
+  ob-cli graph --format graphml --tags > notes.graphml
+  ob-cli graph --from "Project Plan" --depth 2
+  ob-cli graph --folder projects --metrics`,
+	Args: cobra.NoArgs,
+	RunE: runGraph,
+}
+
+var graphOpts app.GraphOptions
+
+func init() {
+	graphCmd.Flags().StringVarP(&graphOpts.Format, "format", "f", "dot", "Output format: dot, graphml or json")
+	graphCmd.Flags().BoolVarP(&graphOpts.Tags, "tags", "", false, "Include tags as nodes")
+	graphCmd.Flags().StringVarP(&graphOpts.Folder, "folder", "", "", "Only notes under this folder")
+	graphCmd.Flags().StringVarP(&graphOpts.Tag, "tag", "t", "", "Only notes with this #tag")
+	graphCmd.Flags().StringVarP(&graphOpts.Start, "from", "", "", "Only notes around this note")
+	graphCmd.Flags().IntVarP(&graphOpts.Depth, "depth", "", 1, "Link distance from --from")
+	graphCmd.Flags().BoolVarP(&graphOpts.Metrics, "metrics", "", false, "Report graph metrics instead")
+	graphCmd.Flags().IntVarP(&graphOpts.Top, "top", "", 10, "Entries in the metrics rankings")
+
+	rootCmd.AddCommand(graphCmd)
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.Graph(os.Stdout, graphOpts)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history <note>",
+	Short: "List the commits that changed a note",
+	Long: `List the commits touching a note, newest first, following renames. Commits
+from before a rename show the note's old path.
+
+With --pick, choose a commit with fzf while previewing the note as it was
+at that commit, and print its hash.
+
+Examples:
+  ob-cli history projects/plan.md
+  ob-cli show "projects/plan.md@$(ob-cli history --pick projects/plan.md)"`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeNote,
+	RunE:              runHistory,
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <note> [rev]",
+	Short: "Show changes to a note",
+	Long: `Show the uncommitted changes to a note, or all changes since a revision or
+date, following renames.
+
+Examples:
+  ob-cli diff projects/plan.md
+  ob-cli diff projects/plan.md HEAD~3
+  ob-cli diff projects/plan.md "2 weeks ago"`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeNote,
+	RunE:              runDiff,
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show <note>[@rev]",
+	Short: "Print a note as it was at a revision",
+	Long: `Print a note as it was at a revision or date. Without @rev, pick the
+revision with fzf.
+
+Examples:
+  ob-cli show projects/plan.md@HEAD~1
+  ob-cli show projects/plan.md@2024-03-01
+  ob-cli show projects/plan.md`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeNote,
+	RunE:              runShow,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <note>",
+	Short: "Restore an old version of a note",
+	Long: `Replace a note with its content at a revision or date, leaving the change
+uncommitted. Without --at, pick the revision with fzf. A note with
+uncommitted changes is only restored with --stash, which stashes them first
+(recover them with "git stash pop"); the go git backend has no stash.
+
+Examples:
+  ob-cli restore projects/plan.md --at HEAD~2
+  ob-cli restore projects/plan.md --at yesterday --stash
+  ob-cli restore projects/plan.md`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeNote,
+	RunE:              runRestore,
+}
+
+var (
+	historyPick bool
+	restoreOpts app.RestoreOptions
+)
+
+func init() {
+	historyCmd.Flags().BoolVarP(&historyPick, "pick", "p", false, "Pick a commit with fzf and print its hash")
+	restoreCmd.Flags().StringVarP(&restoreOpts.At, "at", "", "", "Revision or date to restore (default: pick with fzf)")
+	restoreCmd.Flags().BoolVarP(&restoreOpts.Stash, "stash", "", false, "Stash uncommitted changes to the note first")
+
+	rootCmd.AddCommand(historyCmd, diffCmd, showCmd, restoreCmd)
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if !historyPick {
+		return obApp.History(os.Stdout, args[0])
+	}
+	hash, err := obApp.PickRevision(args[0])
+	if err == nil && hash != "" {
+		fmt.Println(hash)
+	}
+	return err
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	rev := ""
+	if len(args) == 2 {
+		rev = args[1]
+	}
+	return obApp.Diff(os.Stdout, args[0], rev)
+}
+
+func runShow(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	note, rev := obApp.SplitRevision(args[0])
+	return obApp.Show(os.Stdout, note, rev)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.Restore(args[0], restoreOpts)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all notes, most relevant first",
+	Long: `List the vault's notes in frecency order, one path per line, or with their
+title, modification time, score, tags and git state with --output.
+
+Examples:
+  ob-cli list
+  ob-cli list -o json | jq '.[:10]'
+  ob-cli list -o 'template={{.Path}} {{.Score}}'`,
+	Args: cobra.NoArgs,
+	RunE: runList,
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the git status of the vault",
+	Long: `Show the vault's uncommitted changes as "git status --porcelain" does, or
+the changed notes with their git state with --output.
+
+Examples:
+  ob-cli status
+  ob-cli status -o tsv`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+// outputFlag is the --output of the listing commands: list, status,
+// search, tasks and agenda
+var outputFlag string
+
+const outputUsage = "Output format: json, jsonl, tsv or template=TEXT"
+
+func init() {
+	for _, cmd := range []*cobra.Command{rootCmd, listCmd, statusCmd} {
+		cmd.Flags().StringVarP(&outputFlag, "output", "o", "", outputUsage)
+	}
+	rootCmd.Flags().MarkHidden("output")
+
+	rootCmd.AddCommand(listCmd, statusCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	output, err := app.ParseOutput(outputFlag)
+	if err != nil {
+		return err
+	}
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ListFiles(output)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	output, err := app.ParseOutput(outputFlag)
+	if err != nil {
+		return err
+	}
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ShowGitStatus(output)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a markdown language server for wikilinks over stdio",
+	Long: `Run a Language Server Protocol server over stdio for markdown notes.
+
+Features:
+  - Completion of note names after [[, most relevant first, and of
+    headings after [[note#
+  - Go to definition on wikilinks and markdown links, including headings
+  - Find references to the note, link target or heading under the cursor
+  - Diagnostics for links to missing notes or headings
+  - Rename a note and rewrite every link to it (needs an editor that
+    supports file renames in workspace edits)
+
+Documents are served from the vault that contains them: the current notes
+directory, or the nearest folder with .obsidian or .git.
+
+Examples:
+  Neovim:   vim.lsp.start({ name = "ob-cli", cmd = { "ob-cli", "lsp" } })
+  Helix:    [language-server.ob-cli] command = "ob-cli", args = ["lsp"]`,
+	Args: cobra.NoArgs,
+	RunE: runLSP,
+}
+
+func init() {
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ServeLSP(os.Stdin, os.Stdout)
+}
@@ -1,12 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/shalomb/ob-cli/internal/app"
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
 var (
@@ -18,9 +27,18 @@ var (
 )
 
 func main() {
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	// The first SIGINT or SIGTERM cancels the running operation, which
+	// rolls back what it can; a second one kills ob-cli as usual
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	context.AfterFunc(ctx, stop)
+
+	rootCmd.SetArgs(addPlugins(os.Args[1:]))
+	markUsageErrors(rootCmd)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	finishTrace(err)
+	if err != nil {
+		os.Exit(reportError(err))
 	}
 }
 
@@ -36,15 +54,29 @@ Features:
 - Dual mode support (Tips/Obsidian)
 - Direct file access or fuzzy search
 
+Without a command, ob-cli opens a note like "ob-cli open".
+
 Examples:
   ob-cli                    # Interactive file selection
   ob-cli notes/daily.md     # Open specific file
   ob-cli project            # Search for files containing "project"
+  ob-cli open list          # Search for a term that is a command name
   ob-cli --mode=tips        # Use Tips mode
-  ob-cli --list             # List all files
-  ob-cli --status           # Show git status
-  ob-cli --sync             # Sync with remote`,
+  ob-cli new projects/plan  # Create a note and open it
+  ob-cli list               # List all files
+  ob-cli list -o json       # List notes with scores, tags and git state
+  ob-cli status             # Show git status
+  ob-cli sync               # Sync with remote
+  ob-cli push               # Push committed changes
+  ob-cli capture "idea"     # Append a note to the inbox
+  glow $(ob-cli pick)       # Print the selected path for other tools
+  ob-cli tasks --due today  # List tasks due today
+  ob-cli agenda --week      # Show the week's tasks and dated notes`,
 	Args: cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeNote,
+	PersistentPreRunE: prepareRun,
+	SilenceErrors: true, // Reported by main, with its exit code
+	SilenceUsage: true,
 	RunE: runObCli,
 }
 
@@ -53,21 +85,35 @@ var (
 	listFlag     bool
 	statusFlag   bool
 	syncFlag     bool
+	pushFlag     bool
 	versionFlag  bool
 	debugFlag    bool
+	traceFlag    string
+	configFlag   string
 )
 
+// operationFlags are the root flags replaced by the commands of the same
+// name
+var operationFlags = []string{"list", "status", "sync", "push"}
+
 func init() {
-	rootCmd.Flags().StringVarP(&modeFlag, "mode", "m", "auto", "Mode: tips, obsidian, or auto")
+	rootCmd.PersistentFlags().StringVarP(&modeFlag, "mode", "m", "auto", "Mode: tips, obsidian, or auto")
 	rootCmd.Flags().BoolVarP(&listFlag, "list", "l", false, "List all files")
 	rootCmd.Flags().BoolVarP(&statusFlag, "status", "s", false, "Show git status")
 	rootCmd.Flags().BoolVarP(&syncFlag, "sync", "", false, "Sync with remote (stash, pull, pop)")
+	rootCmd.Flags().BoolVarP(&pushFlag, "push", "", false, "Push committed changes to the remote")
+	// The operations became commands; the flags still work for scripts
+	for _, flag := range operationFlags {
+		rootCmd.Flags().MarkDeprecated(flag, fmt.Sprintf("use \"ob-cli %s\"", flag))
+	}
 	rootCmd.Flags().BoolVarP(&versionFlag, "version", "v", false, "Show version")
-	rootCmd.Flags().BoolVarP(&debugFlag, "debug", "d", false, "Enable debug output")
+	rootCmd.PersistentFlags().BoolVarP(&debugFlag, "debug", "d", false, "Enable debug output")
+	rootCmd.PersistentFlags().StringVarP(&traceFlag, "trace", "", "", "Write a Chrome trace of the run to this file")
+	rootCmd.PersistentFlags().StringVarP(&configFlag, "config", "", "", "Config file (default $XDG_CONFIG_HOME/ob-cli/config.yaml)")
 
 	// Bind flags to viper
-	viper.BindPFlag("mode", rootCmd.Flags().Lookup("mode"))
-	viper.BindPFlag("debug", rootCmd.Flags().Lookup("debug"))
+	viper.BindPFlag("mode", rootCmd.PersistentFlags().Lookup("mode"))
+	viper.BindPFlag("debug", rootCmd.PersistentFlags().Lookup("debug"))
 }
 
 func runObCli(cmd *cobra.Command, args []string) error {
@@ -81,32 +127,138 @@ func runObCli(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Handle the deprecated operation flags, which used to silently pick
+	// one when combined
+	var operations []string
+	for _, flag := range operationFlags {
+		if cmd.Flags().Changed(flag) {
+			operations = append(operations, "--"+flag)
+		}
+	}
+	if len(operations) > 1 {
+		return errs.New(errs.ErrUsage, "%s cannot be combined", strings.Join(operations, " and "))
+	}
+	switch {
+	case listFlag:
+		return runList(cmd, nil)
+	case statusFlag:
+		return runStatus(cmd, nil)
+	case syncFlag:
+		return runSync(cmd, nil)
+	case pushFlag:
+		return runPush(cmd, nil)
+	}
+
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	// Interactive mode or direct file access
+	var target string
+	if len(args) > 0 {
+		target = args[0]
+	}
+	if printFlag {
+		return obApp.Pick(target, pickOptions())
+	}
+	return obApp.RunInteractive(target)
+}
+
+// The trace timeline starts as ob-cli starts, though spans are only
+// recorded with --trace
+var (
+	tracer  = diag.NewTracer()
+	runSpan *diag.Span
+)
+
+// prepareRun checks the global flags and sets up diagnostics before any
+// command runs
+func prepareRun(cmd *cobra.Command, args []string) error {
+	if err := checkErrorFormat(); err != nil {
+		return err
+	}
+	setupDiagnostics(cmd, args)
+	return nil
+}
+
+// setupDiagnostics puts the logger, and with --trace the tracer, in the
+// command's context for every service to use
+func setupDiagnostics(cmd *cobra.Command, args []string) {
+	level := slog.LevelWarn
+	if debugFlag {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	ctx := diag.WithLogger(cmd.Context(), logger)
+	if traceFlag != "" {
+		ctx = diag.WithTracer(ctx, tracer)
+		runSpan = diag.Start(ctx, cmd.CommandPath(), "args", args)
+	}
+	cmd.SetContext(ctx)
+}
+
+// finishTrace writes the --trace file once the command has finished
+func finishTrace(err error) {
+	if runSpan == nil {
+		return
+	}
+	runSpan.End("error", err)
+	if err := tracer.WriteFile(traceFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
+
+// newApp loads the configuration and creates the app for the selected mode
+func newApp(ctx context.Context) (*app.App, error) {
+	if err := loadConfig(); err != nil {
+		return nil, err
+	}
+
 	// Create app configuration
 	config := &app.Config{
-		Mode:  modeFlag,
-		Debug: debugFlag,
+		Mode:       modeFlag,
+		Debug:      debugFlag,
+		GitBackend: viper.GetString("git.backend"),
 	}
+	if err := viper.UnmarshalKey("vaults", &config.Vaults); err != nil {
+		return nil, fmt.Errorf("invalid vaults configuration: %w", err)
+	}
+	if err := viper.UnmarshalKey("timeouts", &config.Timeouts); err != nil {
+		return nil, fmt.Errorf("invalid timeouts configuration: %w", err)
+	}
+	types, err := fileTypes()
+	if err != nil {
+		return nil, err
+	}
+	config.Types = types
 
 	// Create app instance
-	obApp, err := app.New(config)
+	obApp, err := app.New(ctx, config)
 	if err != nil {
-		return fmt.Errorf("failed to create app: %w", err)
+		return nil, fmt.Errorf("failed to create app: %w", err)
 	}
+	return obApp, nil
+}
 
-	// Handle different command modes
-	switch {
-	case listFlag:
-		return obApp.ListFiles()
-	case statusFlag:
-		return obApp.ShowGitStatus()
-	case syncFlag:
-		return obApp.SyncWithRemote()
-	default:
-		// Interactive mode or direct file access
-		var target string
-		if len(args) > 0 {
-			target = args[0]
+// loadConfig reads the optional config file. A missing default config file
+// is not an error; ob-cli works with zero configuration.
+func loadConfig() error {
+	if configFlag != "" {
+		viper.SetConfigFile(configFlag)
+	} else {
+		viper.SetConfigName("config")
+		if configDir, err := os.UserConfigDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(configDir, "ob-cli"))
+		}
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
 		}
-		return obApp.RunInteractive(target)
+		return fmt.Errorf("failed to read config: %w", err)
 	}
-}
\ No newline at end of file
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestHelp runs --help for every command, which fails when a command's
+// flags clash with the persistent ones
+func TestHelp(t *testing.T) {
+	var commands []*cobra.Command
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		commands = append(commands, cmd)
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+
+	rootCmd.SetOut(io.Discard)
+	defer rootCmd.SetOut(nil)
+	for _, cmd := range commands {
+		args := append(commandPath(cmd), "--help")
+		t.Run(cmd.CommandPath(), func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					t.Fatalf("%v panicked: %v", args, r)
+				}
+			}()
+			rootCmd.SetArgs(args)
+			if err := rootCmd.Execute(); err != nil {
+				t.Errorf("%v failed: %v", args, err)
+			}
+		})
+	}
+}
+
+// commandPath returns the arguments naming cmd below the root command
+func commandPath(cmd *cobra.Command) []string {
+	if !cmd.HasParent() {
+		return nil
+	}
+	return append(commandPath(cmd.Parent()), cmd.Name())
+}
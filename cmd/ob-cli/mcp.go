@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve the vault to AI agents over the Model Context Protocol",
+	Long: `Serve the vault as Model Context Protocol (MCP) tools over stdio, for agents
+and editors that launch MCP servers as a subprocess.
+
+Tools: search_notes, read_note, list_recent, create_note, append_note,
+list_backlinks and git_status. Writes are restricted to the vault and never
+overwrite an existing note.
+
+Example client configuration:
+  {"mcpServers": {"notes": {"command": "ob-cli", "args": ["--mode", "tips", "mcp"]}}}`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ServeMCP(os.Stdin, os.Stdout, Version)
+}
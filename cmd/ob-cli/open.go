@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var openCmd = &cobra.Command{
+	Use:   "open [file|search-term]",
+	Short: "Select a note with fzf and open it in the editor",
+	Long: `Open a note: a path opens (or creates) that note directly, anything else
+starts the fzf selection with it as the query. This is what ob-cli does
+without a command; use "ob-cli open <term>" to search for a term that is
+also a command name.
+
+Examples:
+  ob-cli open
+  ob-cli open notes/daily.md
+  ob-cli open list`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeNote,
+	RunE:              runOpen,
+}
+
+var newCmd = &cobra.Command{
+	Use:   "new <note>",
+	Short: "Create a note and open it in the editor",
+	Long: `Create a note, with its parent folders, and open it in the editor. The
+note must not exist yet. With --template, the note starts from a note in
+the templates folder.
+
+Examples:
+  ob-cli new projects/launch
+  ob-cli new --template meeting meetings/2024-03-01-standup`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: cobra.NoFileCompletions,
+	RunE:              runNew,
+}
+
+var newTemplate string
+
+func init() {
+	newCmd.Flags().StringVarP(&newTemplate, "template", "t", "", "Template to create the note from")
+	rootCmd.AddCommand(openCmd, newCmd)
+}
+
+func runOpen(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var target string
+	if len(args) > 0 {
+		target = args[0]
+	}
+	return obApp.RunInteractive(target)
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.NewNote(args[0], newTemplate)
+}
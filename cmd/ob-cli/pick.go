@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var pickCmd = &cobra.Command{
+	Use:   "pick [file|search-term]",
+	Short: "Select notes and print their paths instead of opening them",
+	Long: `Run the interactive selection (search-term fallback and file creation
+included) and print the selected path(s), for use in shell pipelines.
+
+Examples:
+  glow $(ob-cli pick)
+  ob-cli pick --multi -0 project | xargs -0 wc -w`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeNote,
+	RunE:              runPick,
+}
+
+var (
+	printFlag    bool
+	nullFlag     bool
+	relativeFlag bool
+	multiFlag    bool
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{rootCmd, pickCmd} {
+		cmd.Flags().BoolVarP(&nullFlag, "null", "0", false, "Separate printed paths with NUL instead of newline")
+		cmd.Flags().BoolVarP(&relativeFlag, "relative", "", false, "Print paths relative to the vault")
+		cmd.Flags().BoolVarP(&multiFlag, "multi", "", false, "Allow selecting several notes (TAB in fzf)")
+	}
+	rootCmd.Flags().BoolVarP(&printFlag, "print", "p", false, "Print the selected path(s) instead of opening the editor")
+
+	rootCmd.AddCommand(pickCmd)
+}
+
+func runPick(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	var target string
+	if len(args) > 0 {
+		target = args[0]
+	}
+	return obApp.Pick(target, pickOptions())
+}
+
+func pickOptions() app.PickOptions {
+	return app.PickOptions{
+		Relative: relativeFlag,
+		Null:     nullFlag,
+		Multi:    multiFlag,
+	}
+}
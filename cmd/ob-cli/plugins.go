@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/plugin"
+)
+
+var pluginsCmd = &cobra.Command{
+	Use:   "plugins",
+	Short: "List and describe external ob-cli-<name> commands",
+	Long: `Any executable named ob-cli-<name> on PATH runs as "ob-cli <name>", with
+the rest of the command line as its arguments. Built-in commands take
+precedence over plugins of the same name.
+
+Plugins get the vault in their environment:
+  OB_VAULT     Notes directory
+  OB_MODE      tips or obsidian
+  OB_GIT_ROOT  Top of the vault's git worktree, when it has one
+  OB_EDITOR    Editor command line
+  OB_CLI       The ob-cli executable
+
+"ob-cli plugins context" prints the same as JSON, for the vault of OB_MODE
+when a plugin runs it.`,
+}
+
+var pluginsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the plugins on PATH",
+	Args:  cobra.NoArgs,
+	RunE:  runPluginsList,
+}
+
+var pluginsContextCmd = &cobra.Command{
+	Use:   "context",
+	Short: "Print what plugins are told about the vault, as JSON",
+	Long: `Print the vault context given to plugins as JSON. Run from a plugin, it
+uses the plugin's OB_MODE unless --mode is given.
+
+Example:
+  "$OB_CLI" plugins context | jq -r .git_root`,
+	Args: cobra.NoArgs,
+	RunE: runPluginsContext,
+}
+
+// pluginArgs are the arguments after a plugin's name, which ob-cli passes
+// on without parsing
+var pluginArgs []string
+
+func init() {
+	pluginsCmd.AddCommand(pluginsListCmd, pluginsContextCmd)
+	rootCmd.AddCommand(pluginsCmd)
+}
+
+// addPlugins adds plugin commands for the command line args and returns
+// the arguments left for cobra to parse. Help and completion list every
+// plugin on PATH; otherwise PATH is only searched for the one named by the
+// first argument, when it isn't a built-in command.
+func addPlugins(args []string) []string {
+	rootCmd.InitDefaultHelpCmd()
+	rootCmd.InitDefaultCompletionCmd()
+	path := os.Getenv("PATH")
+
+	found, _, err := rootCmd.Find(args)
+	if err != nil {
+		return args
+	}
+	if found.Name() == "help" {
+		addPluginCommands(plugin.Discover(path))
+		return args
+	}
+	if found != rootCmd {
+		return args
+	}
+
+	i := firstArg(args)
+	if i < 0 {
+		for _, arg := range args {
+			if arg == "-h" || arg == "--help" {
+				addPluginCommands(plugin.Discover(path))
+			}
+		}
+		return args
+	}
+	if args[i] == cobra.ShellCompRequestCmd || args[i] == cobra.ShellCompNoDescRequestCmd {
+		addPluginCommands(plugin.Discover(path))
+		return args
+	}
+	if p, ok := plugin.Find(path, args[i]); ok {
+		addPluginCommands([]plugin.Plugin{p})
+		pluginArgs = args[i+1:]
+		return args[:i+1]
+	}
+	return args
+}
+
+// firstArg returns the index of the first argument that isn't a root flag
+// or a flag's value, or -1 if there is none
+func firstArg(args []string) int {
+	flags := rootCmd.Flags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			if i+1 < len(args) {
+				return i + 1
+			}
+			return -1
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			return i
+		case strings.Contains(arg, "="):
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		flag := flags.Lookup(name)
+		if flag == nil && !strings.HasPrefix(arg, "--") && len(name) == 1 {
+			flag = flags.ShorthandLookup(name)
+		}
+		if flag != nil && flag.NoOptDefVal == "" {
+			i++ // Skip the flag's value
+		}
+	}
+	return -1
+}
+
+// addPluginCommands adds a command for each plugin, under its own heading
+// in help. Built-in commands hide plugins of the same name.
+func addPluginCommands(plugins []plugin.Plugin) {
+	var commands []*cobra.Command
+	for _, p := range plugins {
+		if builtin(p.Name) {
+			continue
+		}
+		p := p
+		commands = append(commands, &cobra.Command{
+			Use:     p.Name,
+			Short:   "Plugin " + p.Path,
+			Long:    fmt.Sprintf("Run the plugin %s. Arguments after %q are passed to it unchanged.", p.Path, p.Name),
+			GroupID: "plugins",
+			Args:    cobra.ArbitraryArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runPlugin(cmd, p)
+			},
+		})
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	rootCmd.AddGroup(&cobra.Group{ID: "commands", Title: "Available Commands:"}, &cobra.Group{ID: "plugins", Title: "Plugin Commands:"})
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.GroupID == "" {
+			cmd.GroupID = "commands"
+		}
+	}
+	rootCmd.SetHelpCommandGroupID("commands")
+	rootCmd.SetCompletionCommandGroupID("commands")
+	rootCmd.AddCommand(commands...)
+}
+
+func builtin(name string) bool {
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func runPlugin(cmd *cobra.Command, p plugin.Plugin) error {
+	c, err := pluginContext(cmd)
+	if err != nil {
+		return err
+	}
+	return plugin.Run(cmd.Context(), p, c, pluginArgs)
+}
+
+// pluginContext describes the selected vault to plugins
+func pluginContext(cmd *cobra.Command) (plugin.Context, error) {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return plugin.Context{}, err
+	}
+	c := obApp.PluginContext()
+	c.Version = Version
+	if executable, err := os.Executable(); err == nil {
+		c.CLI = executable
+	}
+	return c, nil
+}
+
+func runPluginsList(cmd *cobra.Command, args []string) error {
+	for _, p := range plugin.Discover(os.Getenv("PATH")) {
+		if builtin(p.Name) {
+			fmt.Printf("%s\t%s\t(hidden by the built-in command)\n", p.Name, p.Path)
+			continue
+		}
+		fmt.Printf("%s\t%s\n", p.Name, p.Path)
+	}
+	return nil
+}
+
+func runPluginsContext(cmd *cobra.Command, args []string) error {
+	if mode := os.Getenv("OB_MODE"); mode != "" && !cmd.Flags().Changed("mode") {
+		modeFlag = mode
+	}
+	c, err := pluginContext(cmd)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var previewCmd = &cobra.Command{
+	Use:   "preview <file>",
+	Short: "Print a vault file as text, as the picker previews it",
+	Long: `Print a vault file for reading in a terminal: notes and other text as they
+are, canvases as their cards and the links between them, and images, PDFs
+and other binary files as their type, size and modification time. The
+picker runs it to preview files when listing more than notes.
+
+Examples:
+  ob-cli preview projects/board.canvas
+  ob-cli preview home.md | glow -`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeNote,
+	RunE:              runPreview,
+}
+
+func init() {
+	rootCmd.AddCommand(previewCmd)
+}
+
+func runPreview(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.Preview(args[0])
+}
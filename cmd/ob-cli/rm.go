@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <note>...",
+	Short: "Move notes to the trash",
+	Long: `Delete notes the way Obsidian does, following "trashOption" in
+.obsidian/app.json: into the system trash (the default), into the vault's
+.trash folder ("local"), or permanently ("none"). Links to the notes from
+other notes are listed before they break.
+
+Examples:
+  ob-cli rm drafts/old-idea
+  ob-cli rm --git projects/cancelled.md`,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeNotes,
+	RunE:              runRm,
+}
+
+var rmOpts app.MoveOptions
+
+func init() {
+	rmCmd.Flags().BoolVarP(&rmOpts.Git, "git", "g", false, "Stage the removal in git (like git rm)")
+	rootCmd.AddCommand(rmCmd)
+}
+
+func runRm(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	for _, note := range args {
+		if err := obApp.RemoveNote(note, rmOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
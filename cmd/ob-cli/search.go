@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <words>...",
+	Short: "Find notes containing every word",
+	Long: `List the notes whose path or content contains every word, ignoring case, in
+frecency order. Content matches print "<file>:<line>", a tab and the first
+matching line; path matches print the path. With --output, each match is
+written with the note's fields.
+
+Examples:
+  ob-cli search release plan
+  ob-cli search -n 5 -o json standup | jq -r '.[].path'`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+var searchLimit int
+
+func init() {
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 0, "Maximum number of notes (default: all)")
+	searchCmd.Flags().StringVarP(&outputFlag, "output", "o", "", outputUsage)
+
+	rootCmd.AddCommand(searchCmd)
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	output, err := app.ParseOutput(outputFlag)
+	if err != nil {
+		return err
+	}
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.Search(os.Stdout, strings.Join(args, " "), searchLimit, output)
+}
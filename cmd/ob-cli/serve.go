@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/api"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the vault over a local JSON HTTP API",
+	Long: `Serve a small REST API for launchers and editor plugins, backed by the same
+operations as the CLI.
+
+Endpoints:
+  GET  /api/files?limit=N        Notes in frecency order with scores
+  GET  /api/search?q=...&limit=N Notes matching every word of q
+  GET  /api/notes/{path}         Read a note
+  PUT  /api/notes/{path}         Write a note, creating it when missing
+  POST /api/notes                Create a note from content or a template
+  GET  /api/status               Git working tree status, behind/ahead
+  POST /api/sync                 Sync with the remote (stash, pull, pop)
+
+Requests must send "Authorization: Bearer <token>". The token comes from
+--token or OB_CLI_TOKEN; on TCP a random token is generated and printed
+when neither is set. Unix sockets are only accessible to the current user,
+so they need no token unless one is given.
+
+Examples:
+  ob-cli serve --addr 127.0.0.1:7777
+  ob-cli serve --socket $XDG_RUNTIME_DIR/ob-cli.sock`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	serveAddr   string
+	serveSocket string
+	serveToken  string
+)
+
+func init() {
+	serveCmd.Flags().StringVarP(&serveAddr, "addr", "", "127.0.0.1:7777", "TCP address to listen on")
+	serveCmd.Flags().StringVarP(&serveSocket, "socket", "", "", "Listen on this unix socket instead of TCP")
+	serveCmd.Flags().StringVarP(&serveToken, "token", "", "", "Bearer token clients must send (default: $OB_CLI_TOKEN)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("OB_CLI_TOKEN")
+	}
+	if token == "" && serveSocket == "" {
+		if token, err = api.NewToken(); err != nil {
+			return fmt.Errorf("failed to generate token: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Token: %s\n", token)
+	}
+
+	listener, err := api.Listen(serveAddr, serveSocket)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	defer listener.Close()
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", listener.Addr())
+
+	return obApp.Serve(listener, token)
+}
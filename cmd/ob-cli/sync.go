@@ -0,0 +1,55 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync with the remote (stash, pull, pop)",
+	Long: `Stash local changes, pull with rebase and restore the changes. A conflict
+or an interrupt rolls back to the local state before the sync. With --push,
+push committed changes afterwards.
+
+Examples:
+  ob-cli sync
+  ob-cli sync --push`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Push committed changes to the remote",
+	Args:  cobra.NoArgs,
+	RunE:  runPush,
+}
+
+var syncPush bool
+
+func init() {
+	syncCmd.Flags().BoolVarP(&syncPush, "push", "", false, "Push committed changes after syncing")
+	rootCmd.AddCommand(syncCmd, pushCmd)
+}
+
+func runSync(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := obApp.SyncWithRemote(); err != nil {
+		return err
+	}
+	if syncPush {
+		return obApp.Push()
+	}
+	return nil
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.Push()
+}
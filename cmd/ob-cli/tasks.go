@@ -0,0 +1,67 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "List checkbox tasks across the vault",
+	Long: `List "- [ ]" tasks from every note with their file and line. Obsidian Tasks
+plugin metadata is understood: 📅 due, ⏳ scheduled, 🛫 start, ✅ done dates,
+priorities (🔺 ⏫ 🔼 🔽 ⏬) and #tags.
+
+Examples:
+  ob-cli tasks --due overdue
+  ob-cli tasks --tag work --sort priority
+  ob-cli tasks --open
+  ob-cli tasks --output json | jq '.[] | select(.priority == "high")'
+  ob-cli tasks done projects/plan.md:12`,
+	Args: cobra.NoArgs,
+	RunE: runTasks,
+}
+
+var tasksDoneCmd = &cobra.Command{
+	Use:   "done <file:line>",
+	Short: "Toggle a task's checkbox in place",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runTasksDone,
+}
+
+var taskOpts app.TaskOptions
+
+func init() {
+	tasksCmd.Flags().StringVarP(&taskOpts.Status, "status", "", "open", "Task status: open, done or all")
+	tasksCmd.Flags().StringVarP(&taskOpts.Due, "due", "", "", "Due filter: today, overdue, week, none or YYYY-MM-DD")
+	tasksCmd.Flags().StringVarP(&taskOpts.Tag, "tag", "t", "", "Only tasks with this #tag")
+	tasksCmd.Flags().StringVarP(&taskOpts.Path, "path", "", "", "Only tasks in notes under this path")
+	tasksCmd.Flags().StringVarP(&taskOpts.Sort, "sort", "", "file", "Sort by file, due or priority")
+	tasksCmd.Flags().BoolVarP(&taskOpts.Open, "open", "o", false, "Pick a task and open its note at the task line")
+	tasksCmd.Flags().StringVarP(&outputFlag, "output", "", "", outputUsage)
+
+	tasksCmd.AddCommand(tasksDoneCmd)
+	rootCmd.AddCommand(tasksCmd)
+}
+
+func runTasks(cmd *cobra.Command, args []string) error {
+	var err error
+	if taskOpts.Output, err = app.ParseOutput(outputFlag); err != nil {
+		return err
+	}
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ListTasks(taskOpts)
+}
+
+func runTasksDone(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ToggleTask(args[0], time.Now())
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/app"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage trashed notes",
+	Long: `List, restore and permanently delete notes removed with "ob-cli rm" or
+Obsidian: the vault's .trash folder and the system trash entries that came
+from this vault.
+
+Examples:
+  ob-cli trash list
+  ob-cli trash restore projects/plan.md
+  ob-cli trash empty`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List trashed notes, most recent first",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <name|path>...",
+	Short: "Restore trashed notes to where they were",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runTrashRestore,
+}
+
+var trashEmptyCmd = &cobra.Command{
+	Use:   "empty",
+	Short: "Permanently delete trashed notes",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashEmpty,
+}
+
+var trashRestoreOpts app.MoveOptions
+
+func init() {
+	trashRestoreCmd.Flags().BoolVarP(&trashRestoreOpts.Git, "git", "g", false, "Stage the restored notes in git")
+
+	trashCmd.AddCommand(trashListCmd, trashRestoreCmd, trashEmptyCmd)
+	rootCmd.AddCommand(trashCmd)
+}
+
+func runTrashList(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.ListTrash(os.Stdout)
+}
+
+func runTrashRestore(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	for _, name := range args {
+		if err := obApp.RestoreTrash(name, trashRestoreOpts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runTrashEmpty(cmd *cobra.Command, args []string) error {
+	obApp, err := newApp(cmd.Context())
+	if err != nil {
+		return err
+	}
+	return obApp.EmptyTrash()
+}
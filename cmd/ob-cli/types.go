@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/filetype"
+)
+
+var (
+	typeFlag string
+	allFlag  bool
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{rootCmd, openCmd, pickCmd, listCmd} {
+		cmd.Flags().StringVarP(&typeFlag, "type", "", "", "File types to list: md, excalidraw, canvas, image, pdf, other or all (comma-separated)")
+		cmd.Flags().BoolVarP(&allFlag, "all", "", false, "List every file, like --type all")
+		cmd.RegisterFlagCompletionFunc("type", completeTypes)
+	}
+}
+
+// fileTypes returns the file types selected with --type or --all, or nil
+// for notes
+func fileTypes() (filetype.Set, error) {
+	switch {
+	case allFlag && typeFlag != "":
+		return nil, errs.New(errs.ErrUsage, "--type and --all cannot be combined")
+	case allFlag:
+		return filetype.All, nil
+	case typeFlag == "":
+		return nil, nil
+	}
+	types, err := filetype.Parse(typeFlag)
+	if err != nil {
+		return nil, errs.New(errs.ErrUsage, "invalid --type: %w", err)
+	}
+	return types, nil
+}
+
+func completeTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return append(filetype.Names, "all"), cobra.ShellCompDirectiveNoFileComp
+}
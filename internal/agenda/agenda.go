@@ -0,0 +1,119 @@
+package agenda
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// Kind says why an item is on the agenda
+type Kind string
+
+const (
+	KindDue       Kind = "due"
+	KindScheduled Kind = "scheduled"
+	KindNote      Kind = "note"
+)
+
+// Note is a note with a frontmatter date
+type Note struct {
+	File  string
+	Title string
+	Date  time.Time
+}
+
+// Item is a single agenda entry: a task that is due or scheduled, or a note
+// dated on that day
+type Item struct {
+	Date time.Time
+	Kind Kind
+	Task *markdown.Task // Set for KindDue and KindScheduled
+	Note *Note          // Set for KindNote
+}
+
+// Agenda holds the items of a date range grouped by day
+type Agenda struct {
+	From    time.Time // First day (inclusive)
+	To      time.Time // Last day (exclusive)
+	Overdue []Item    // Open tasks due before From
+	Days    []Day
+}
+
+// Day is the agenda of a single date
+type Day struct {
+	Date  time.Time
+	Items []Item
+}
+
+// Build collects the items that fall in [from, to). Only open tasks are
+// included; a task both due and scheduled appears on both days.
+func Build(tasks []markdown.Task, notes []Note, from, to time.Time) Agenda {
+	a := Agenda{From: from, To: to}
+	byDay := map[time.Time][]Item{}
+	inRange := func(d time.Time) bool {
+		return !d.IsZero() && !d.Before(from) && d.Before(to)
+	}
+
+	for i := range tasks {
+		task := &tasks[i]
+		if !task.Open() {
+			continue
+		}
+		if !task.Due.IsZero() && task.Due.Before(from) {
+			a.Overdue = append(a.Overdue, Item{Date: task.Due, Kind: KindDue, Task: task})
+		}
+		if inRange(task.Due) {
+			byDay[task.Due] = append(byDay[task.Due], Item{Date: task.Due, Kind: KindDue, Task: task})
+		}
+		if inRange(task.Scheduled) && !task.Scheduled.Equal(task.Due) {
+			byDay[task.Scheduled] = append(byDay[task.Scheduled], Item{Date: task.Scheduled, Kind: KindScheduled, Task: task})
+		}
+	}
+
+	for i := range notes {
+		note := &notes[i]
+		if inRange(note.Date) {
+			byDay[note.Date] = append(byDay[note.Date], Item{Date: note.Date, Kind: KindNote, Note: note})
+		}
+	}
+
+	for date, items := range byDay {
+		sortItems(items)
+		a.Days = append(a.Days, Day{Date: date, Items: items})
+	}
+	sort.Slice(a.Days, func(i, j int) bool {
+		return a.Days[i].Date.Before(a.Days[j].Date)
+	})
+	sortItems(a.Overdue)
+
+	return a
+}
+
+// sortItems orders items by date, then notes before tasks, then priority,
+// then location
+func sortItems(items []Item) {
+	rank := map[Kind]int{KindNote: 0, KindDue: 1, KindScheduled: 2}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.Kind != b.Kind {
+			return rank[a.Kind] < rank[b.Kind]
+		}
+		if a.Task != nil && b.Task != nil {
+			if a.Task.Priority != b.Task.Priority {
+				return a.Task.Priority > b.Task.Priority
+			}
+			if a.Task.File != b.Task.File {
+				return a.Task.File < b.Task.File
+			}
+			return a.Task.Line < b.Task.Line
+		}
+		if a.Note != nil && b.Note != nil {
+			return a.Note.File < b.Note.File
+		}
+		return false
+	})
+}
@@ -0,0 +1,59 @@
+package agenda
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+func date(day int) time.Time {
+	return time.Date(2024, 3, day, 0, 0, 0, 0, time.Local)
+}
+
+func TestBuild(t *testing.T) {
+	tasks := []markdown.Task{
+		{File: "a.md", Line: 1, Status: ' ', Description: "overdue", Due: date(1)},
+		{File: "a.md", Line: 2, Status: 'x', Description: "done", Due: date(5)},
+		{File: "a.md", Line: 3, Status: ' ', Description: "low", Due: date(5), Priority: markdown.PriorityLow},
+		{File: "b.md", Line: 1, Status: ' ', Description: "high", Due: date(5), Priority: markdown.PriorityHigh},
+		{File: "b.md", Line: 2, Status: ' ', Description: "planned", Scheduled: date(6), Due: date(20)},
+		{File: "b.md", Line: 3, Status: ' ', Description: "undated"},
+	}
+	notes := []Note{
+		{File: "standup.md", Title: "Standup", Date: date(5)},
+		{File: "old.md", Title: "Old", Date: date(1)},
+	}
+
+	ag := Build(tasks, notes, date(5), date(8))
+
+	if len(ag.Overdue) != 1 || ag.Overdue[0].Task.Description != "overdue" {
+		t.Fatalf("Overdue = %+v, expected the single overdue task", ag.Overdue)
+	}
+	if len(ag.Days) != 2 {
+		t.Fatalf("Days = %d, expected 2", len(ag.Days))
+	}
+
+	var got []string
+	for _, item := range ag.Days[0].Items {
+		if item.Note != nil {
+			got = append(got, item.Note.Title)
+		} else {
+			got = append(got, item.Task.Description)
+		}
+	}
+	expected := []string{"Standup", "high", "low"}
+	if len(got) != len(expected) {
+		t.Fatalf("day 1 items = %q, expected %q", got, expected)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("day 1 item %d = %q, expected %q", i, got[i], expected[i])
+		}
+	}
+
+	day2 := ag.Days[1]
+	if !day2.Date.Equal(date(6)) || len(day2.Items) != 1 || day2.Items[0].Kind != KindScheduled {
+		t.Errorf("day 2 = %+v, expected the scheduled task on March 6", day2)
+	}
+}
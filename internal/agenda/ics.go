@@ -0,0 +1,158 @@
+package agenda
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+const (
+	icsDate     = "20060102"
+	icsDateTime = "20060102T150405Z"
+	icsMaxLine  = 75 // Octets per line before folding (RFC 5545 3.1)
+)
+
+// icsPriority maps task priorities to RFC 5545 PRIORITY values
+// (1 highest, 5 medium, 9 lowest, 0 undefined)
+var icsPriority = map[markdown.Priority]int{
+	markdown.PriorityHighest: 1,
+	markdown.PriorityHigh:    3,
+	markdown.PriorityMedium:  5,
+	markdown.PriorityLow:     7,
+	markdown.PriorityLowest:  9,
+}
+
+// WriteICS writes an RFC 5545 calendar with a VTODO for every task that has
+// a due or scheduled date and a VEVENT for every dated note. UIDs are
+// derived from the note and task text, so they survive edits elsewhere in
+// the note and the output only changes when the vault does.
+func WriteICS(w io.Writer, tasks []markdown.Task, notes []Note, stamp time.Time) error {
+	iw := &icsWriter{w: w}
+	iw.line("BEGIN:VCALENDAR")
+	iw.line("VERSION:2.0")
+	iw.line("PRODID:-//ob-cli//ob-cli//EN")
+	iw.line("CALSCALE:GREGORIAN")
+
+	dtstamp := stamp.UTC().Format(icsDateTime)
+	seen := map[string]int{}
+
+	for _, task := range tasks {
+		if task.Due.IsZero() && task.Scheduled.IsZero() {
+			continue
+		}
+
+		key := task.File + "\x00" + task.Description
+		seen[key]++
+		iw.line("BEGIN:VTODO")
+		iw.line("UID:" + uid(fmt.Sprintf("%s\x00%d", key, seen[key])))
+		iw.line("DTSTAMP:" + dtstamp)
+		iw.line("SUMMARY:" + escapeText(task.Description))
+		iw.line("DESCRIPTION:" + escapeText(task.ID()))
+
+		start := task.Scheduled
+		if start.IsZero() {
+			start = task.Start
+		}
+		if !start.IsZero() && (task.Due.IsZero() || !start.After(task.Due)) {
+			iw.line("DTSTART;VALUE=DATE:" + start.Format(icsDate))
+		}
+		if !task.Due.IsZero() {
+			iw.line("DUE;VALUE=DATE:" + task.Due.Format(icsDate))
+		}
+
+		switch {
+		case task.Done():
+			iw.line("STATUS:COMPLETED")
+			if !task.Completed.IsZero() {
+				iw.line("COMPLETED:" + task.Completed.UTC().Format(icsDateTime))
+			}
+		case task.Status == '-':
+			iw.line("STATUS:CANCELLED")
+		case task.Status == '/':
+			iw.line("STATUS:IN-PROCESS")
+		default:
+			iw.line("STATUS:NEEDS-ACTION")
+		}
+		if p, ok := icsPriority[task.Priority]; ok {
+			iw.line(fmt.Sprintf("PRIORITY:%d", p))
+		}
+		if len(task.Tags) > 0 {
+			categories := make([]string, len(task.Tags))
+			for i, tag := range task.Tags {
+				categories[i] = escapeText(tag)
+			}
+			iw.line("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		iw.line("END:VTODO")
+	}
+
+	for _, note := range notes {
+		if note.Date.IsZero() {
+			continue
+		}
+		iw.line("BEGIN:VEVENT")
+		iw.line("UID:" + uid(note.File))
+		iw.line("DTSTAMP:" + dtstamp)
+		iw.line("DTSTART;VALUE=DATE:" + note.Date.Format(icsDate))
+		iw.line("DTEND;VALUE=DATE:" + note.Date.AddDate(0, 0, 1).Format(icsDate))
+		iw.line("SUMMARY:" + escapeText(note.Title))
+		iw.line("DESCRIPTION:" + escapeText(note.File))
+		iw.line("TRANSP:TRANSPARENT")
+		iw.line("END:VEVENT")
+	}
+
+	iw.line("END:VCALENDAR")
+	return iw.err
+}
+
+// icsWriter writes folded CRLF content lines, keeping the first error
+type icsWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (iw *icsWriter) line(s string) {
+	if iw.err != nil {
+		return
+	}
+	_, iw.err = io.WriteString(iw.w, foldLine(s)+"\r\n")
+}
+
+// foldLine splits a content line into 75-octet chunks joined by CRLF and
+// a space, never splitting a UTF-8 sequence
+func foldLine(s string) string {
+	if len(s) <= icsMaxLine {
+		return s
+	}
+
+	var b strings.Builder
+	limit := icsMaxLine
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		b.WriteString(s[:cut])
+		b.WriteString("\r\n ")
+		s = s[cut:]
+		limit = icsMaxLine - 1 // Continuation lines start with a space
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// escapeText escapes a TEXT value (RFC 5545 3.3.11)
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "").Replace(s)
+}
+
+func uid(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:]) + "@ob-cli"
+}
@@ -0,0 +1,72 @@
+package agenda
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+func TestWriteICS(t *testing.T) {
+	tasks := []markdown.Task{
+		{File: "work.md", Line: 4, Status: ' ', Description: "ship, then; rest", Due: date(5), Scheduled: date(4), Priority: markdown.PriorityHighest, Tags: []string{"work", "release"}},
+		{File: "work.md", Line: 9, Status: 'x', Description: "done", Due: date(2), Completed: date(3)},
+		{File: "work.md", Line: 12, Status: ' ', Description: "undated"},
+	}
+	notes := []Note{{File: "standup.md", Title: "Standup", Date: date(5)}}
+	stamp := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, tasks, notes, stamp); err != nil {
+		t.Fatalf("WriteICS() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"DTSTAMP:20240301T120000Z\r\n",
+		`SUMMARY:ship\, then\; rest` + "\r\n",
+		"DTSTART;VALUE=DATE:20240304\r\nDUE;VALUE=DATE:20240305\r\n",
+		"STATUS:NEEDS-ACTION\r\nPRIORITY:1\r\nCATEGORIES:work,release\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:",
+		"BEGIN:VEVENT\r\n",
+		"DTSTART;VALUE=DATE:20240305\r\nDTEND;VALUE=DATE:20240306\r\nSUMMARY:Standup\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "undated") {
+		t.Error("undated task should not be exported")
+	}
+	if n := strings.Count(out, "BEGIN:VTODO"); n != 2 {
+		t.Errorf("got %d VTODOs, expected 2", n)
+	}
+
+	// Output is stable so calendar clients see unchanged UIDs
+	var again bytes.Buffer
+	WriteICS(&again, tasks, notes, stamp)
+	if again.String() != out {
+		t.Error("WriteICS() output is not deterministic")
+	}
+}
+
+func TestFoldLine(t *testing.T) {
+	line := "SUMMARY:" + strings.Repeat("é", 60)
+	folded := foldLine(line)
+
+	for _, part := range strings.Split(folded, "\r\n") {
+		if len(part) > icsMaxLine {
+			t.Errorf("folded line has %d octets: %q", len(part), part)
+		}
+		if !strings.HasPrefix(part, "SUMMARY") && !strings.HasPrefix(part, " ") {
+			t.Errorf("continuation line %q does not start with a space", part)
+		}
+	}
+	if unfolded := strings.ReplaceAll(folded, "\r\n ", ""); unfolded != line {
+		t.Errorf("unfolded = %q, expected %q", unfolded, line)
+	}
+}
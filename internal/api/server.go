@@ -0,0 +1,150 @@
+// Package api serves JSON endpoints over HTTP with bearer token
+// authentication, on TCP or a unix socket
+package api
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Route binds a method and path to a handler. Paths ending in "/" match
+// every path below them.
+type Route struct {
+	Method string
+	Path   string
+	Status int // Success status; default 200 OK
+	Handle func(r *http.Request) (interface{}, error)
+}
+
+// Error is a handler error with the HTTP status to reply with. Other
+// errors are reported as 500 Internal Server Error.
+type Error struct {
+	Status int
+	Err    error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Errorf creates an Error with a formatted message
+func Errorf(status int, format string, args ...interface{}) error {
+	return &Error{Status: status, Err: fmt.Errorf(format, args...)}
+}
+
+// NewHandler routes requests to routes. When token is set, every request
+// must carry it as "Authorization: Bearer <token>".
+func NewHandler(routes []Route, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token != "" && !authorized(r, token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or invalid token")
+			return
+		}
+
+		pathFound := false
+		for _, route := range routes {
+			if !matches(route.Path, r.URL.Path) {
+				continue
+			}
+			pathFound = true
+			if route.Method != r.Method {
+				continue
+			}
+
+			result, err := route.Handle(r)
+			if err != nil {
+				status := http.StatusInternalServerError
+				var apiErr *Error
+				if errors.As(err, &apiErr) {
+					status = apiErr.Status
+				}
+				writeError(w, status, err.Error())
+				return
+			}
+			status := route.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			writeJSON(w, status, result)
+			return
+		}
+
+		if pathFound {
+			writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", r.Method))
+			return
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("no endpoint %s", r.URL.Path))
+	})
+}
+
+// Listen listens on a unix socket when socket is set, else on a TCP
+// address. Unix sockets are only accessible to the current user; a stale
+// socket left by a previous run is replaced.
+func Listen(addr, socket string) (net.Listener, error) {
+	if socket == "" {
+		return net.Listen("tcp", addr)
+	}
+
+	if conn, err := net.Dial("unix", socket); err == nil {
+		conn.Close()
+		return nil, fmt.Errorf("%s is in use", socket)
+	}
+	os.Remove(socket)
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// NewToken returns a random token for clients to authenticate with
+func NewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// DecodeJSON decodes a JSON request body into v
+func DecodeJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return Errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+func authorized(r *http.Request, token string) bool {
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+func matches(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(path, pattern) && len(path) > len(pattern)
+	}
+	return path == pattern
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
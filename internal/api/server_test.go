@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewHandler(t *testing.T) {
+	handler := NewHandler([]Route{
+		{Method: http.MethodGet, Path: "/api/ping", Handle: func(r *http.Request) (interface{}, error) {
+			return map[string]string{"pong": r.URL.Query().Get("q")}, nil
+		}},
+		{Method: http.MethodGet, Path: "/api/items/", Handle: func(r *http.Request) (interface{}, error) {
+			return nil, Errorf(http.StatusNotFound, "no item %s", strings.TrimPrefix(r.URL.Path, "/api/items/"))
+		}},
+		{Method: http.MethodPost, Path: "/api/items", Status: http.StatusCreated, Handle: func(r *http.Request) (interface{}, error) {
+			var body struct{ Name string }
+			if err := DecodeJSON(r, &body); err != nil {
+				return nil, err
+			}
+			return body, nil
+		}},
+		{Method: http.MethodPost, Path: "/api/fail", Handle: func(r *http.Request) (interface{}, error) {
+			return nil, fmt.Errorf("boom")
+		}},
+	}, "secret")
+
+	tests := []struct {
+		method, path, token, body string
+		status                    int
+		response                  string
+	}{
+		{"GET", "/api/ping?q=x", "secret", "", 200, `{"pong":"x"}`},
+		{"GET", "/api/ping", "", "", 401, `{"error":"missing or invalid token"}`},
+		{"GET", "/api/ping", "wrong", "", 401, `{"error":"missing or invalid token"}`},
+		{"POST", "/api/ping", "secret", "", 405, `{"error":"method POST not allowed"}`},
+		{"GET", "/api/other", "secret", "", 404, `{"error":"no endpoint /api/other"}`},
+		{"GET", "/api/items/a", "secret", "", 404, `{"error":"no item a"}`},
+		{"GET", "/api/items/", "secret", "", 404, `{"error":"no endpoint /api/items/"}`},
+		{"POST", "/api/items", "secret", `{"Name":"a"}`, 201, `{"Name":"a"}`},
+		{"POST", "/api/items", "secret", `{`, 400, `{"error":"invalid request body: unexpected EOF"}`},
+		{"POST", "/api/fail", "secret", "", 500, `{"error":"boom"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, expected %d", rec.Code, tt.status)
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.response {
+				t.Errorf("body = %s, expected %s", got, tt.response)
+			}
+		})
+	}
+}
+
+func TestListen_UnixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ob.sock")
+
+	l, err := Listen("", socket)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	if _, err := Listen("", socket); err == nil {
+		t.Error("Listen() on a socket in use succeeded")
+	}
+	l.Close()
+
+	// A stale socket file without a listener is replaced
+	os.WriteFile(socket, nil, 0600)
+	l, err = Listen("", socket)
+	if err != nil {
+		t.Fatalf("Listen() on a stale socket error = %v", err)
+	}
+	l.Close()
+}
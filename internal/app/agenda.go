@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/agenda"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/markdown"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+// AgendaOptions selects the date range shown by Agenda
+type AgendaOptions struct {
+	From   string    // First day as YYYY-MM-DD (default: today)
+	Days   int       // Number of days to show (default: 1)
+	Week   bool      // Show the 7 days starting at From
+	ICS    bool      // Write an iCalendar file of all dated items instead
+	Output Output    // Structured output format instead of days
+	Today  time.Time // Reference date (default: today)
+}
+
+// Agenda prints the open tasks due or scheduled in the selected range,
+// grouped by day after any overdue tasks, together with notes whose
+// frontmatter date falls in the range. With ICS set, all dated tasks and
+// notes are written as an iCalendar file to out instead. With an output
+// format, the items are written in it, overdue ones first.
+func (a *App) Agenda(out io.Writer, opts AgendaOptions) error {
+	if opts.ICS && opts.Output.Format != "" {
+		return errs.New(errs.ErrUsage, "--ics and --output cannot be combined")
+	}
+	if opts.Today.IsZero() {
+		opts.Today = time.Now()
+	}
+	opts.Today = startOfDay(opts.Today)
+
+	tasks, err := a.collectTasks()
+	if err != nil {
+		return err
+	}
+	notes := a.collectDatedNotes()
+
+	if opts.ICS {
+		return agenda.WriteICS(out, tasks, notes, time.Now())
+	}
+
+	from := opts.Today
+	if opts.From != "" {
+		from, err = time.ParseInLocation(markdown.DateLayout, opts.From, time.Local)
+		if err != nil {
+			return errs.New(errs.ErrUsage, "invalid date %q: use YYYY-MM-DD", opts.From)
+		}
+	}
+	days := opts.Days
+	if opts.Week {
+		days = 7
+	}
+	if days < 1 {
+		days = 1
+	}
+
+	ag := agenda.Build(tasks, notes, from, from.AddDate(0, 0, days))
+	if opts.Output.Format != "" {
+		return writeRecords(out, a.agendaItems(ag), opts.Output, agendaFields)
+	}
+	printAgenda(out, ag)
+	return nil
+}
+
+// agendaItems lists an agenda's items for a structured output format,
+// overdue tasks first, then each day's items
+func (a *App) agendaItems(ag agenda.Agenda) []model.AgendaItem {
+	type entry struct {
+		item    agenda.Item
+		overdue bool
+	}
+	var entries []entry
+	for _, item := range ag.Overdue {
+		entries = append(entries, entry{item, true})
+	}
+	for _, day := range ag.Days {
+		for _, item := range day.Items {
+			entries = append(entries, entry{item, false})
+		}
+	}
+
+	files := make([]string, len(entries))
+	for i, e := range entries {
+		if e.item.Note != nil {
+			files[i] = e.item.Note.File
+		} else {
+			files[i] = e.item.Task.File
+		}
+	}
+	notes := a.noteInfos(files)
+
+	items := make([]model.AgendaItem, len(entries))
+	for i, e := range entries {
+		item := model.AgendaItem{Day: isoDate(e.item.Date), Kind: string(e.item.Kind)}
+		if e.item.Note != nil {
+			item.NoteInfo = notes[files[i]]
+		} else {
+			item.TaskInfo = taskInfo(notes[files[i]], *e.item.Task)
+		}
+		if e.overdue {
+			item.Kind = model.AgendaOverdue
+		}
+		items[i] = item
+	}
+	return items
+}
+
+// collectDatedNotes returns the notes with a frontmatter date
+func (a *App) collectDatedNotes() []agenda.Note {
+	files, err := a.sortedFiles()
+	if err != nil {
+		return nil
+	}
+
+	var notes []agenda.Note
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(a.notesDir, file))
+		if err != nil {
+			continue
+		}
+		content := string(data)
+		fm, err := markdown.Frontmatter(content)
+		if err != nil {
+			a.logger().Debug("skipping invalid frontmatter", "file", file, "error", err)
+			continue
+		}
+		if date, ok := markdown.DateField(fm, "date"); ok {
+			notes = append(notes, agenda.Note{File: file, Title: markdown.Title(file, content), Date: date})
+		}
+	}
+	return notes
+}
+
+// printAgenda renders an agenda as day headings followed by indented items
+func printAgenda(out io.Writer, ag agenda.Agenda) {
+	if len(ag.Overdue) > 0 {
+		fmt.Fprintln(out, "Overdue")
+		for _, item := range ag.Overdue {
+			fmt.Fprintln(out, formatAgendaItem(item, true))
+		}
+	}
+	for _, day := range ag.Days {
+		if len(ag.Overdue) > 0 || day.Date.After(ag.Days[0].Date) {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintln(out, day.Date.Format("Mon 2006-01-02"))
+		for _, item := range day.Items {
+			fmt.Fprintln(out, formatAgendaItem(item, false))
+		}
+	}
+	if len(ag.Overdue) == 0 && len(ag.Days) == 0 {
+		fmt.Fprintln(out, "Nothing scheduled")
+	}
+}
+
+// formatAgendaItem renders an item as "  <kind>\t<id>\t<text>", adding
+// the date for items listed outside their day
+func formatAgendaItem(item agenda.Item, withDate bool) string {
+	if item.Note != nil {
+		return fmt.Sprintf("  %-9s\t%s\t%s", item.Kind, item.Note.File, item.Note.Title)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %-9s\t%s\t%s", item.Kind, item.Task.ID(), item.Task.Description)
+	if withDate {
+		fmt.Fprintf(&b, " (due %s)", item.Date.Format(markdown.DateLayout))
+	}
+	if item.Task.Priority != markdown.PriorityNone {
+		fmt.Fprintf(&b, " [%s]", item.Task.Priority)
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func newAgendaTestApp(t *testing.T) *App {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	notes := map[string]string{
+		"plan.md":    "- [ ] ship ⏫ 📅 2024-03-05\n- [ ] review 📅 2024-03-01\n- [ ] prepare ⏳ 2024-03-07 📅 2024-03-20\n",
+		"standup.md": "---\ndate: 2024-03-06\n---\n# Standup\n",
+		"broken.md":  "---\ndate: [oops\n---\n",
+	}
+	var files []string
+	for name, content := range notes {
+		os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+		files = append(files, name)
+	}
+
+	return &App{
+		config:     &Config{Mode: "tips"},
+		gitService: git.NewMockService(nil, "", 0, 0, nil),
+		editor:     editor.NewMockService([]string{}, 0, nil),
+		fzf:        fzf.NewMockService("", true, nil),
+		frecency:   frecency.NewMockService(files, nil),
+		notesDir:   tempDir,
+		mode:       "tips",
+	}
+}
+
+func TestApp_Agenda(t *testing.T) {
+	app := newAgendaTestApp(t)
+	today := time.Date(2024, 3, 5, 9, 0, 0, 0, time.Local)
+
+	var out bytes.Buffer
+	if err := app.Agenda(&out, AgendaOptions{Week: true, Today: today}); err != nil {
+		t.Fatalf("Agenda() error = %v", err)
+	}
+
+	expected := []string{
+		"Overdue",
+		"  due      \tplan.md:2\treview (due 2024-03-01)",
+		"",
+		"Tue 2024-03-05",
+		"  due      \tplan.md:1\tship [high]",
+		"",
+		"Wed 2024-03-06",
+		"  note     \tstandup.md\tStandup",
+		"",
+		"Thu 2024-03-07",
+		"  scheduled\tplan.md:3\tprepare",
+	}
+	if got := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n"); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Agenda() output:\n%s\nexpected:\n%s", out.String(), strings.Join(expected, "\n"))
+	}
+}
+
+func TestApp_Agenda_Range(t *testing.T) {
+	app := newAgendaTestApp(t)
+
+	var out bytes.Buffer
+	if err := app.Agenda(&out, AgendaOptions{From: "2024-03-10", Days: 2}); err != nil {
+		t.Fatalf("Agenda() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Overdue") || strings.Contains(out.String(), "Standup") {
+		t.Errorf("unexpected agenda for March 10-11:\n%s", out.String())
+	}
+
+	if err := app.Agenda(&out, AgendaOptions{From: "next week"}); err == nil {
+		t.Error("Agenda() expected error for invalid --from date")
+	}
+}
+
+func TestApp_Agenda_ICS(t *testing.T) {
+	app := newAgendaTestApp(t)
+
+	var out bytes.Buffer
+	if err := app.Agenda(&out, AgendaOptions{ICS: true}); err != nil {
+		t.Fatalf("Agenda() error = %v", err)
+	}
+	if n := strings.Count(out.String(), "BEGIN:VTODO"); n != 3 {
+		t.Errorf("got %d VTODOs, expected 3", n)
+	}
+	if n := strings.Count(out.String(), "BEGIN:VEVENT"); n != 1 {
+		t.Errorf("got %d VEVENTs, expected 1", n)
+	}
+}
+
+func TestApp_Agenda_Output(t *testing.T) {
+	app := newAgendaTestApp(t)
+	today := time.Date(2024, 3, 5, 9, 0, 0, 0, time.Local)
+
+	output, _ := ParseOutput(OutputTSV)
+	var out bytes.Buffer
+	if err := app.Agenda(&out, AgendaOptions{Week: true, Output: output, Today: today}); err != nil {
+		t.Fatalf("Agenda() error = %v", err)
+	}
+	expected := "2024-03-01\toverdue\tplan.md\t2\treview\t\n" +
+		"2024-03-05\tdue\tplan.md\t1\tship\thigh\n" +
+		"2024-03-06\tnote\tstandup.md\t\tStandup\t\n" +
+		"2024-03-07\tscheduled\tplan.md\t3\tprepare\t\n"
+	if out.String() != expected {
+		t.Errorf("Agenda() TSV =\n%q\nexpected\n%q", out.String(), expected)
+	}
+
+	if err := app.Agenda(&out, AgendaOptions{ICS: true, Output: output}); !errors.Is(err, errs.ErrUsage) {
+		t.Errorf("Agenda() with --ics and --output error = %v", err)
+	}
+}
@@ -1,55 +1,102 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/shalomb/ob-cli/internal/canvas"
+	"github.com/shalomb/ob-cli/internal/errs"
 	"github.com/shalomb/ob-cli/internal/git"
+	"github.com/shalomb/ob-cli/internal/diag"
 	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/filetype"
 	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/hook"
 	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/index"
+	"github.com/shalomb/ob-cli/internal/model"
 	"github.com/shalomb/ob-cli/internal/vault"
 )
 
 // Config holds application configuration
 type Config struct {
-	Mode  string // tips, obsidian, or auto
-	Debug bool
+	Mode       string // tips, obsidian, or auto
+	Debug      bool
+	GitBackend string                 // exec (default) or go
+	Vaults     map[string]VaultConfig // Per-vault settings keyed by mode
+	Timeouts   Timeouts
+	Types      filetype.Set // Files to list and pick; nil for notes
+}
+
+// VaultConfig holds settings for a single vault
+type VaultConfig struct {
+	Editor  editor.Profile      `mapstructure:"editor"`
+	Inbox   string              `mapstructure:"inbox"`   // Capture target, relative to the vault
+	Archive string              `mapstructure:"archive"` // Archive folder, relative to the vault
+	Hooks   map[string][]string `mapstructure:"hooks"`   // Shell commands by hook event
+	Open    map[string]string   `mapstructure:"open"`    // Opener commands by file type
+	// TrustHooks runs the executables in the vault's .ob-cli/hooks folder,
+	// which arrive with the vault's content from its remote
+	TrustHooks bool `mapstructure:"trust_hooks"`
 }
 
 // App represents the main application
 type App struct {
+	ctx        context.Context // Cancelled on SIGINT or SIGTERM; nil in tests
 	config     *Config
 	gitService git.Service
 	editor     editor.Service
+	opener     editor.Opener
 	fzf        fzf.Service
 	frecency   frecency.Service
+	index      *index.Client // Running daemon, if any; nil in tests
+	hooks      *hook.Runner  // nil in tests
 	notesDir   string
 	mode       string
 }
 
-// New creates a new App instance
-func New(config *Config) (*App, error) {
+// New creates a new App instance whose operations run under ctx
+func New(ctx context.Context, config *Config) (*App, error) {
 	// Determine mode and notes directory
-	mode, notesDir, err := determineModeAndDir(config.Mode)
+	span := diag.Start(ctx, "vault discover", "mode", config.Mode)
+	mode, notesDir, err := determineModeAndDir(ctx, config.Mode)
+	span.End("dir", notesDir, "error", err)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create services
-	gitService := git.NewService(notesDir)
-	editorService := editor.NewService() // No mode-specific config
+	gitService, err := git.NewBackend(config.GitBackend, notesDir)
+	if err != nil {
+		return nil, err
+	}
+	hooks := hook.NewRunner(notesDir, config.Vaults[mode].Hooks, config.Vaults[mode].TrustHooks)
+	profile := editorProfile(config, mode)
+	editorService := editor.NewService(profile)
+	if profile.Remote == nil || *profile.Remote {
+		// Reuse a running nvim instead of nesting a second editor, waiting
+		// for the note to be closed when post-edit hooks need to see it
+		wait := hooks.Has(ctx, hook.PostEdit)
+		editorService = editor.NewRemoteService(notesDir, profile.Remote != nil, wait, profile, editorService)
+	}
 	fzfService := fzf.NewService()
-	frecencyService := frecency.NewService(notesDir)
+	indexClient := index.NewClient(index.SocketPath(notesDir))
+	frecencyService := frecency.NewIndexedService(notesDir, indexClient)
 
 	return &App{
+		ctx:        ctx,
 		config:     config,
 		gitService: gitService,
 		editor:     editorService,
+		opener:     editor.NewOpener(config.Vaults[mode].Open),
 		fzf:        fzfService,
 		frecency:   frecencyService,
+		index:      indexClient,
+		hooks:      hooks,
 		notesDir:   notesDir,
 		mode:       mode,
 	}, nil
@@ -65,77 +112,203 @@ func (a *App) RunInteractive(target string) error {
 		// Otherwise, use as search term
 	}
 
-	// Start async git fetch
-	go a.gitService.FetchAsync()
-
-	// Get frecency-sorted file list
-	files, err := a.frecency.GetSortedFiles()
-	if err != nil {
-		return fmt.Errorf("failed to get file list: %w", err)
+	// Start async git fetch, unless the daemon already fetches periodically
+	if a.index == nil || !a.index.Running(a.baseContext()) {
+		a.fetchInBackground()
 	}
 
-	// Run fzf selection
-	selection, err := a.fzf.SelectFile(files, target)
+	// Run fzf selection on the frecency-sorted files
+	selections, err := a.selectFiles(target, false)
 	if err != nil {
-		return fmt.Errorf("fzf selection failed: %w", err)
+		return err
+	}
+	var selection string
+	if len(selections) > 0 {
+		selection = selections[0]
 	}
 
 	// Check git status if fetch completed
-	if err := a.checkGitStatus(); err != nil && a.config.Debug {
-		fmt.Fprintf(os.Stderr, "Git status check failed: %v\n", err)
+	if err := a.checkGitStatus(); err != nil {
+		a.logger().Debug("git status check failed", "error", err)
 	}
 
 	// Handle file creation or editing
 	return a.handleFileSelection(selection)
 }
 
-// ListFiles lists all files in a column format
-func (a *App) ListFiles() error {
-	files, err := a.frecency.GetSortedFiles()
+// NewNote creates a note, from template when set, and opens it in the
+// editor. Unlike selecting a missing note, it fails if the note exists.
+func (a *App) NewNote(file, template string) error {
+	var err error
+	if template != "" {
+		file, err = a.CreateFromTemplate(file, template, time.Now())
+	} else {
+		file, err = a.CreateNote(file, "")
+	}
+	if err != nil {
+		return err
+	}
+	// CreateNote leaves frecency alone for the tools; this note is the user's
+	fmt.Fprintf(os.Stderr, "Creating new file: %s\n", file)
+	if err := a.recordAccess(file); err != nil {
+		a.logger().Debug("failed to record access", "file", file, "error", err)
+	}
+	return a.openInEditor(file, 0)
+}
+
+// PickOptions controls how picked paths are printed
+type PickOptions struct {
+	Relative bool // Print vault-relative paths instead of absolute ones
+	Null     bool // Separate paths with NUL instead of newline
+	Multi    bool // Allow selecting several notes
+}
+
+// Pick runs the same selection as RunInteractive, including search-term
+// fallback and file creation, but prints the chosen path(s) instead of
+// opening the editor
+func (a *App) Pick(target string, opts PickOptions) error {
+	var selections []string
+	if target != "" && a.isDirectFile(target) {
+		selections = []string{target}
+	} else {
+		var err error
+		selections, err = a.selectFiles(target, opts.Multi)
+		if err != nil {
+			return err
+		}
+	}
+
+	separator := "\n"
+	if opts.Null {
+		separator = "\x00"
+	}
+	for _, selection := range selections {
+		fullPath, created, err := a.prepareFile(selection)
+		if err != nil {
+			return err
+		}
+		if created {
+			a.noteCreated(selection)
+		}
+
+		path := selection
+		if !opts.Relative {
+			if path, err = filepath.Abs(fullPath); err != nil {
+				return err
+			}
+		}
+		fmt.Print(path + separator)
+	}
+
+	return nil
+}
+
+// ListFiles lists all files of the selected types in a column format, or
+// as notes with their title, modification time, score, tags, git state,
+// type and canvas summary in a structured output format
+func (a *App) ListFiles(output Output) error {
+	notes, err := a.listedFiles()
 	if err != nil {
 		return fmt.Errorf("failed to get file list: %w", err)
 	}
+	if output.Format != "" {
+		a.describeNotes(notes, a.gitStates())
+		return writeNotes(os.Stdout, notes, output)
+	}
 
 	// Print files in column format
-	for _, file := range files {
-		fmt.Println(file)
+	for _, note := range notes {
+		fmt.Println(note.Path)
 	}
 
 	return nil
 }
 
-// ShowGitStatus shows git status
-func (a *App) ShowGitStatus() error {
-	status, err := a.gitService.GetStatus()
+// ShowGitStatus shows git status, or the changed files as notes in a
+// structured output format
+func (a *App) ShowGitStatus(output Output) error {
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	status, err := a.gitService.GetStatus(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get git status: %w", err)
 	}
 
-	fmt.Print(status)
-	return nil
+	if output.Format == "" {
+		fmt.Print(status)
+		return nil
+	}
+	notes := parseStatus(status)
+	for i := range notes {
+		if info, err := os.Stat(filepath.Join(a.notesDir, notes[i].Path)); err == nil && !info.IsDir() {
+			notes[i].Modified = info.ModTime()
+		}
+	}
+	a.describeNotes(notes, nil)
+	return writeNotes(os.Stdout, notes, output)
 }
 
-// SyncWithRemote syncs with remote repository
+// CompleteNotes returns the files of the selected types whose paths start
+// with prefix, most relevant first, for shell completion
+func (a *App) CompleteNotes(prefix string) ([]string, error) {
+	files, err := a.listedFiles()
+	if err != nil {
+		return nil, err
+	}
+	var notes []string
+	for _, file := range files {
+		if strings.HasPrefix(file.Path, prefix) {
+			notes = append(notes, file.Path)
+		}
+	}
+	return notes, nil
+}
+
+// SyncWithRemote syncs with remote repository. Interrupting it rolls back
+// to the local state before the sync.
 func (a *App) SyncWithRemote() error {
-	return a.gitService.SyncWithRemote()
+	if err := a.runHook(hook.Event{Name: hook.PreSync}); err != nil {
+		return err
+	}
+
+	ctx, cancel := a.syncContext()
+	defer cancel()
+	err := a.gitService.SyncWithRemote(ctx)
+
+	event := hook.Event{Name: hook.PostSync}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	a.runHook(event)
+	return err
+}
+
+// Push pushes committed changes to the remote repository
+func (a *App) Push() error {
+	ctx, cancel := a.syncContext()
+	defer cancel()
+	return a.gitService.Push(ctx)
 }
 
 // Private methods
 
 func (a *App) isDirectFile(target string) bool {
-	// Check if it looks like a file path (contains .md extension or has directory separators)
-	return strings.HasSuffix(target, ".md") || strings.Contains(target, "/")
+	// Check if it looks like a file path (has a known extension such as .md
+	// or .canvas, or directory separators)
+	return filetype.Of(target) != filetype.Other || strings.Contains(target, "/")
 }
 
 func (a *App) checkGitStatus() error {
-	behind, ahead, err := a.gitService.GetSyncStatus()
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	behind, ahead, err := a.gitService.GetSyncStatus(ctx)
 	if err != nil {
 		return err
 	}
 
 	if behind > 0 {
 		fmt.Printf("⚠️  Repository is %d commits behind origin\n", behind)
-		fmt.Println("   Run 'ob-cli --sync' to update")
+		fmt.Println("   Run 'ob-cli sync' to update")
 		fmt.Println()
 	}
 
@@ -152,47 +325,162 @@ func (a *App) handleFileSelection(selection string) error {
 		return nil // User cancelled
 	}
 
+	_, created, err := a.prepareFile(selection)
+	if err != nil {
+		return err
+	}
+	if created {
+		a.noteCreated(selection)
+	}
+
+	// Open file in the editor, or its opener
+	return a.openFile(selection)
+}
+
+// prepareFile resolves a selected note inside the vault, creates it when
+// missing and records the access for frecency. It returns the full path
+// and whether the note was created, for the caller to run the post-create
+// hooks once the note has its content.
+func (a *App) prepareFile(selection string) (string, bool, error) {
+	fullPath, err := a.notePath(selection)
+	if err != nil {
+		return "", false, err
+	}
+
 	// Check if file exists
-	fullPath := filepath.Join(a.notesDir, selection)
+	created := false
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if filetype.Binary(fullPath) {
+			return "", false, errs.New(errs.ErrInvalidPath, "%s does not exist; only text files are created", selection)
+		}
 		// Create file and parent directories
 		if err := a.createFileWithDirs(fullPath); err != nil {
-			return fmt.Errorf("failed to create file: %w", err)
+			return "", false, fmt.Errorf("failed to create file: %w", err)
 		}
-		fmt.Printf("Creating new file: %s\n", selection)
+		fmt.Fprintf(os.Stderr, "Creating new file: %s\n", selection)
+		created = true
 	}
 
-	// Open file in editor
-	return a.editor.OpenFile(selection)
+	if err := a.recordAccess(selection); err != nil {
+		a.logger().Debug("failed to record access", "file", selection, "error", err)
+	}
+
+	return fullPath, created, nil
+}
+
+// notePath resolves a vault-relative note path, refusing paths that
+// escape the vault, directly or through a symlink
+func (a *App) notePath(relPath string) (string, error) {
+	fullPath := filepath.Join(a.notesDir, relPath)
+	rel, err := filepath.Rel(a.notesDir, fullPath)
+	if err != nil || filepath.IsAbs(relPath) || !isLocal(rel) || !a.linksInVault(fullPath) {
+		return "", fmt.Errorf("path %s is %w", relPath, ErrOutsideVault)
+	}
+	return fullPath, nil
+}
+
+// linksInVault reports whether fullPath, a path under the vault, stays in
+// it once symlinks are resolved. Paths not created yet are checked from
+// their nearest existing parent; a dangling symlink fails.
+func (a *App) linksInVault(fullPath string) bool {
+	root, err := filepath.EvalSymlinks(a.notesDir)
+	if err != nil {
+		return true // The vault itself is missing; nothing is linked
+	}
+	for path := fullPath; ; path = filepath.Dir(path) {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			rel, err := filepath.Rel(root, resolved)
+			return err == nil && isLocal(rel)
+		}
+		if _, lerr := os.Lstat(path); lerr == nil || !os.IsNotExist(err) {
+			return false
+		}
+		if filepath.Dir(path) == path {
+			return false
+		}
+	}
+}
+
+// isLocal reports whether a relative path stays in its base directory
+func isLocal(rel string) bool {
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sortedFiles lists the vault's notes by frecency, bounded by the list
+// timeout
+func (a *App) sortedFiles() ([]string, error) {
+	ctx, cancel := a.listContext()
+	defer cancel()
+	return a.frecency.GetSortedFiles(ctx)
+}
+
+// scoredFiles returns the vault's notes with their frecency scores, most
+// relevant first
+func (a *App) scoredFiles() ([]model.NoteInfo, error) {
+	ctx, cancel := a.listContext()
+	defer cancel()
+	return a.frecency.GetScoredFiles(ctx)
+}
+
+// recordAccess records a note's access for frecency
+func (a *App) recordAccess(file string) error {
+	ctx, cancel := a.listContext()
+	defer cancel()
+	return a.frecency.RecordAccess(ctx, file)
 }
 
 func (a *App) createFileWithDirs(fullPath string) error {
-	// Create parent directories
-	dir := filepath.Dir(fullPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	// An empty canvas is not valid JSON: start it without cards
+	content := ""
+	if filetype.Of(fullPath) == filetype.Canvas {
+		content = canvas.Empty
+	}
+
+	// Leave the file alone if another process just created it
+	if err := createFile(fullPath, content); err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+// createFile creates a file with content and its parent directories. It
+// fails with an os.ErrExist error when the file already exists.
+func createFile(fullPath, content string) error {
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return err
 	}
 
-	// Create empty file
-	file, err := os.Create(fullPath)
+	file, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE|os.O_EXCL, 0644)
 	if err != nil {
 		return err
 	}
+	if content != "" {
+		// Locked so that appends waiting on the new file land after content
+		if err := lockFile(file); err != nil {
+			file.Close()
+			return err
+		}
+		if _, err := file.WriteString(content); err != nil {
+			file.Close()
+			return err
+		}
+	}
 	return file.Close()
 }
 
-func determineModeAndDir(mode string) (string, string, error) {
+func determineModeAndDir(ctx context.Context, mode string) (string, string, error) {
 	discoverer := vault.NewDiscoverer()
 	
 	switch mode {
 	case "tips":
-		vaultPath, err := discoverer.DiscoverTipsVault()
+		vaultPath, err := discoverer.DiscoverTipsVault(ctx)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to discover Tips vault: %w", err)
 		}
 		return "tips", vaultPath, nil
 	case "obsidian":
-		vaultPath, err := discoverer.DiscoverObsidianVault()
+		vaultPath, err := discoverer.DiscoverObsidianVault(ctx)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to discover Obsidian vault: %w", err)
 		}
@@ -200,20 +488,31 @@ func determineModeAndDir(mode string) (string, string, error) {
 	case "auto":
 		// Auto-detect based on command name or environment
 		if strings.Contains(os.Args[0], "tips") {
-			vaultPath, err := discoverer.DiscoverTipsVault()
+			vaultPath, err := discoverer.DiscoverTipsVault(ctx)
 			if err != nil {
 				return "", "", fmt.Errorf("failed to discover Tips vault: %w", err)
 			}
 			return "tips", vaultPath, nil
 		}
-		vaultPath, err := discoverer.DiscoverObsidianVault()
+		vaultPath, err := discoverer.DiscoverObsidianVault(ctx)
 		if err != nil {
 			return "", "", fmt.Errorf("failed to discover Obsidian vault: %w", err)
 		}
 		return "obsidian", vaultPath, nil
 	default:
-		return "", "", fmt.Errorf("invalid mode: %s", mode)
+		return "", "", errs.New(errs.ErrUsage, "invalid mode: %s", mode)
+	}
+}
+
+// editorProfile builds the editor profile for a mode: built-in defaults,
+// then the vault's configured profile, then the per-vault editor variable
+// (OBSIDIAN_EDITOR or TIPS_EDITOR)
+func editorProfile(config *Config, mode string) editor.Profile {
+	profile := editor.DefaultProfile(mode).Merge(config.Vaults[mode].Editor)
+	if command := os.Getenv(strings.ToUpper(mode) + "_EDITOR"); command != "" {
+		profile.Command = command
 	}
+	return profile
 }
 
 // SetNotesDir sets the notes directory (for testing)
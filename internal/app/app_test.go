@@ -1,12 +1,16 @@
 package app
 
 import (
+	"context"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/errs"
 	"github.com/shalomb/ob-cli/internal/frecency"
 	"github.com/shalomb/ob-cli/internal/fzf"
 	"github.com/shalomb/ob-cli/internal/git"
@@ -73,7 +77,7 @@ func TestApp_New(t *testing.T) {
 				t.Setenv("TIPS_VAULT", tempDir)
 			}
 			
-			app, err := New(tt.config)
+			app, err := New(context.Background(), tt.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -131,7 +135,7 @@ func TestApp_ListFiles(t *testing.T) {
 				mode:       "tips",
 			}
 
-			err := app.ListFiles()
+			err := app.ListFiles(Output{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ListFiles() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -185,7 +189,7 @@ func TestApp_ShowGitStatus(t *testing.T) {
 				mode:       "tips",
 			}
 
-			err := app.ShowGitStatus()
+			err := app.ShowGitStatus(Output{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ShowGitStatus() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -277,8 +281,8 @@ func TestApp_RunInteractive_DirectFile(t *testing.T) {
 	if len(mockEditor.OpenedFiles) != 1 {
 		t.Errorf("Expected 1 opened file, got %d", len(mockEditor.OpenedFiles))
 	}
-	if mockEditor.OpenedFiles[0] != "test-file.md" {
-		t.Errorf("Expected opened file 'test-file.md', got %s", mockEditor.OpenedFiles[0])
+	if mockEditor.OpenedFiles[0] != testFile {
+		t.Errorf("Expected opened file %s, got %s", testFile, mockEditor.OpenedFiles[0])
 	}
 }
 
@@ -320,8 +324,8 @@ func TestApp_RunInteractive_FileCreation(t *testing.T) {
 	if len(mockEditor.OpenedFiles) != 1 {
 		t.Errorf("Expected 1 opened file, got %d", len(mockEditor.OpenedFiles))
 	}
-	if mockEditor.OpenedFiles[0] != "new-file.md" {
-		t.Errorf("Expected opened file 'new-file.md', got %s", mockEditor.OpenedFiles[0])
+	if mockEditor.OpenedFiles[0] != expectedFile {
+		t.Errorf("Expected opened file %s, got %s", expectedFile, mockEditor.OpenedFiles[0])
 	}
 }
 
@@ -358,8 +362,8 @@ func TestApp_RunInteractive_WithSearchTerm(t *testing.T) {
 	if len(mockEditor.OpenedFiles) != 1 {
 		t.Errorf("Expected 1 opened file, got %d", len(mockEditor.OpenedFiles))
 	}
-	if mockEditor.OpenedFiles[0] != "project-notes.md" {
-		t.Errorf("Expected opened file 'project-notes.md', got %s", mockEditor.OpenedFiles[0])
+	if expected := filepath.Join(tempDir, "project-notes.md"); mockEditor.OpenedFiles[0] != expected {
+		t.Errorf("Expected opened file %s, got %s", expected, mockEditor.OpenedFiles[0])
 	}
 }
 
@@ -387,8 +391,8 @@ func TestApp_RunInteractive_UserCancels(t *testing.T) {
 
 	// Test user cancellation
 	err := app.RunInteractive("")
-	if err != nil {
-		t.Errorf("RunInteractive() with user cancellation error = %v", err)
+	if !errors.Is(err, errs.ErrCancelled) {
+		t.Errorf("RunInteractive() with user cancellation error = %v, want errs.ErrCancelled", err)
 	}
 
 	// Check that no files were opened
@@ -437,7 +441,239 @@ func TestApp_RunInteractive_NewFileWithDirectories(t *testing.T) {
 	if len(mockEditor.OpenedFiles) != 1 {
 		t.Errorf("Expected 1 opened file, got %d", len(mockEditor.OpenedFiles))
 	}
-	if mockEditor.OpenedFiles[0] != newFilePath {
-		t.Errorf("Expected opened file to be %s, got %s", newFilePath, mockEditor.OpenedFiles[0])
+	if mockEditor.OpenedFiles[0] != fullPath {
+		t.Errorf("Expected opened file to be %s, got %s", fullPath, mockEditor.OpenedFiles[0])
+	}
+}
+
+func TestEditorProfile(t *testing.T) {
+	level := 1
+	config := &Config{
+		Mode: "obsidian",
+		Vaults: map[string]VaultConfig{
+			"obsidian": {Editor: editor.Profile{Command: "vim", ConcealLevel: &level}},
+		},
+	}
+
+	profile := editorProfile(config, "obsidian")
+	if profile.Command != "vim" {
+		t.Errorf("Expected configured command 'vim', got %q", profile.Command)
+	}
+	if profile.ConcealLevel == nil || *profile.ConcealLevel != 1 {
+		t.Errorf("Expected configured conceallevel 1, got %v", profile.ConcealLevel)
+	}
+	if profile.Filetype != "markdown" {
+		t.Errorf("Expected default filetype 'markdown', got %q", profile.Filetype)
+	}
+
+	// The per-vault environment variable overrides the configured command
+	t.Setenv("OBSIDIAN_EDITOR", "code --wait")
+	if profile := editorProfile(config, "obsidian"); profile.Command != "code --wait" {
+		t.Errorf("Expected OBSIDIAN_EDITOR to override command, got %q", profile.Command)
+	}
+
+	// Tips mode falls back to its own defaults
+	if profile := editorProfile(config, "tips"); profile.Command != "" || *profile.ConcealLevel != 0 {
+		t.Errorf("Expected tips defaults, got %+v", profile)
+	}
+}
+
+func TestApp_Pick(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "existing.md"), []byte("# Existing"), 0644)
+
+	tests := []struct {
+		name      string
+		target    string
+		selection string
+		opts      PickOptions
+		expected  string
+		accessed  []string
+		wantErr   error
+	}{
+		{
+			name:      "search term selection prints absolute path",
+			target:    "exist",
+			selection: "existing.md",
+			expected:  filepath.Join(tempDir, "existing.md") + "\n",
+			accessed:  []string{"existing.md"},
+		},
+		{
+			name:     "direct file relative with NUL",
+			target:   "notes/new.md",
+			opts:     PickOptions{Relative: true, Null: true},
+			expected: "notes/new.md\x00",
+			accessed: []string{"notes/new.md"},
+		},
+		{
+			name:      "cancelled selection prints nothing",
+			selection: "",
+			expected:  "",
+			wantErr:   errs.ErrCancelled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frecencyService := frecency.NewMockService([]string{"existing.md"}, nil)
+			editorService := editor.NewMockService([]string{}, 0, nil)
+			app := &App{
+				config:     &Config{Mode: "tips", Debug: false},
+				gitService: git.NewMockService(nil, "", 0, 0, nil),
+				editor:     editorService,
+				fzf:        fzf.NewMockService(tt.selection, tt.selection == "", nil),
+				frecency:   frecencyService,
+				notesDir:   tempDir,
+				mode:       "tips",
+			}
+
+			output := captureStdout(t, func() {
+				if err := app.Pick(tt.target, tt.opts); !errors.Is(err, tt.wantErr) {
+					t.Errorf("Pick() error = %v, want %v", err, tt.wantErr)
+				}
+			})
+
+			if output != tt.expected {
+				t.Errorf("Expected output %q, got %q", tt.expected, output)
+			}
+			if accessed := frecencyService.(*frecency.MockService).Accessed; len(accessed) != len(tt.accessed) {
+				t.Errorf("Expected accesses %v, got %v", tt.accessed, accessed)
+			}
+			if opened := editorService.(*editor.MockService).OpenedFiles; len(opened) != 0 {
+				t.Errorf("Expected editor not to be opened, got %v", opened)
+			}
+		})
+	}
+
+	// Notes picked by typing a new name are created
+	if _, err := os.Stat(filepath.Join(tempDir, "notes", "new.md")); err != nil {
+		t.Errorf("Expected picked note to be created: %v", err)
+	}
+}
+
+func TestApp_RunInteractive_OutsideVault(t *testing.T) {
+	app := &App{
+		config:     &Config{Mode: "tips", Debug: false},
+		gitService: git.NewMockService(nil, "", 0, 0, nil),
+		editor:     editor.NewMockService([]string{}, 0, nil),
+		fzf:        fzf.NewMockService("", false, nil),
+		frecency:   frecency.NewMockService([]string{}, nil),
+		notesDir:   t.TempDir(),
+		mode:       "tips",
+	}
+
+	if err := app.RunInteractive("../escape.md"); err == nil {
+		t.Error("Expected error for path outside the vault, got nil")
+	}
+}
+
+func TestApp_notePath_Symlinks(t *testing.T) {
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "secret.md"), []byte("secret"), 0644)
+	notesDir := t.TempDir()
+	os.Symlink(outside, filepath.Join(notesDir, "linked"))
+	os.Symlink(filepath.Join(outside, "missing.md"), filepath.Join(notesDir, "dangling.md"))
+	os.Mkdir(filepath.Join(notesDir, "notes"), 0755)
+	os.Symlink("notes", filepath.Join(notesDir, "alias"))
+	app := &App{notesDir: notesDir}
+
+	tests := map[string]bool{
+		"linked/secret.md":   false,
+		"linked/new/note.md": false,
+		"dangling.md":        false,
+		"alias/note.md":      true,
+		"notes/new/note.md":  true,
+	}
+	for path, ok := range tests {
+		if _, err := app.notePath(path); (err == nil) != ok {
+			t.Errorf("notePath(%q) error = %v, want ok %v", path, err, ok)
+		}
+	}
+}
+
+func TestApp_NewNote(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	os.MkdirAll(filepath.Join(app.notesDir, "templates"), 0755)
+	os.WriteFile(filepath.Join(app.notesDir, "templates", "meeting.md"), []byte("# {{title}}\n"), 0644)
+	editorService := app.editor.(*editor.MockService)
+
+	captureStderr(t, func() {
+		if err := app.NewNote("meetings/standup", "meeting"); err != nil {
+			t.Fatalf("NewNote() error = %v", err)
+		}
+	})
+	content, _ := os.ReadFile(filepath.Join(app.notesDir, "meetings", "standup.md"))
+	if string(content) != "# standup\n" {
+		t.Errorf("NewNote() content = %q, want %q", content, "# standup\n")
 	}
-}
\ No newline at end of file
+	if want := filepath.Join(app.notesDir, "meetings", "standup.md"); len(editorService.OpenedFiles) != 1 || editorService.OpenedFiles[0] != want {
+		t.Errorf("NewNote() opened %v, want [%s]", editorService.OpenedFiles, want)
+	}
+
+	if err := app.NewNote("home", ""); !errors.Is(err, ErrNoteExists) {
+		t.Errorf("NewNote() on an existing note error = %v, want %v", err, ErrNoteExists)
+	}
+	if len(editorService.OpenedFiles) != 1 {
+		t.Errorf("NewNote() opened an existing note: %v", editorService.OpenedFiles)
+	}
+}
+
+func TestApp_CompleteNotes(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"", []string{"projects/plan.md", "home.md", "ideas.md"}},
+		{"pro", []string{"projects/plan.md"}},
+		{"h", []string{"home.md"}},
+		{"plan", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := app.CompleteNotes(tt.prefix)
+			if err != nil {
+				t.Fatalf("CompleteNotes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CompleteNotes(%q) = %v, want %v", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+// captureStdout returns everything fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stdout, fn)
+}
+
+// captureStderr returns everything fn writes to stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stderr, fn)
+}
+
+func capture(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	saved := *file
+	*file = w
+	defer func() { *file = saved }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-done
+}
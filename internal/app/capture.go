@@ -0,0 +1,312 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+const defaultInbox = "inbox.md"
+
+// CaptureOptions controls where and how captured text is appended
+type CaptureOptions struct {
+	Text      string    // Text to append; continuation lines are indented
+	Target    string    // Note path relative to the vault (default: the inbox)
+	Daily     bool      // Append to today's daily note instead of the inbox
+	Heading   string    // Append under this heading, created when missing
+	Timestamp bool      // Prefix the bullet with the capture time
+	Commit    bool      // Commit the note after appending
+	At        time.Time // Capture time (default: now)
+}
+
+// Capture appends a bullet to the inbox, today's daily note or a given note
+// without opening an editor
+func (a *App) Capture(opts CaptureOptions) error {
+	text := strings.TrimRight(opts.Text, "\r\n\t ")
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("nothing to capture")
+	}
+	if opts.At.IsZero() {
+		opts.At = time.Now()
+	}
+
+	target := opts.Target
+	switch {
+	case target != "":
+		if filepath.Ext(target) == "" {
+			target += ".md"
+		}
+	case opts.Daily:
+		target = a.dailyNotePath(opts.At)
+	default:
+		target = a.inboxPath()
+	}
+
+	fullPath, err := a.notePath(target)
+	if err != nil {
+		return err
+	}
+
+	created := false
+	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if err := a.createFileWithDirs(fullPath); err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		created = true
+	}
+
+	entry := captureEntry(text, opts.At, opts.Timestamp, !opts.Daily || opts.Target != "")
+	if err := appendLocked(fullPath, opts.Heading, entry); err != nil {
+		return fmt.Errorf("failed to capture to %s: %w", target, err)
+	}
+	if created {
+		a.noteCreated(target)
+	}
+	fmt.Fprintf(os.Stderr, "Captured to %s\n", target)
+
+	if opts.Commit {
+		ctx, cancel := a.gitContext()
+		defer cancel()
+		if err := a.gitService.CommitFiles(ctx, "capture: "+target, target); err != nil {
+			return fmt.Errorf("failed to commit %s: %w", target, err)
+		}
+	}
+
+	return nil
+}
+
+// inboxPath returns the configured inbox note for the current vault
+func (a *App) inboxPath() string {
+	if inbox := a.config.Vaults[a.mode].Inbox; inbox != "" {
+		return inbox
+	}
+	return defaultInbox
+}
+
+// dailyNotePath returns the daily note for a date, following the folder
+// and format of Obsidian's daily notes plugin when it is configured
+func (a *App) dailyNotePath(t time.Time) string {
+	settings := struct {
+		Folder string `json:"folder"`
+		Format string `json:"format"`
+	}{}
+	if data, err := os.ReadFile(filepath.Join(a.notesDir, ".obsidian", "daily-notes.json")); err == nil {
+		json.Unmarshal(data, &settings) // Fall back to defaults on bad JSON
+	}
+	if settings.Format == "" {
+		settings.Format = "YYYY-MM-DD"
+	}
+	return filepath.Join(settings.Folder, formatMoment(settings.Format, t)+".md")
+}
+
+// captureEntry renders captured text as a markdown bullet. Daily notes only
+// need the time; other notes get the full date.
+func captureEntry(text string, at time.Time, timestamp, withDate bool) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	prefix := "- "
+	if timestamp {
+		layout := "15:04"
+		if withDate {
+			layout = "2006-01-02 15:04"
+		}
+		prefix += at.Format(layout) + " "
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix + lines[0] + "\n")
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
+
+// appendLocked appends an entry to a note while holding an exclusive lock,
+// so that parallel captures never interleave. Only an entry under a heading
+// rewrites the note; others are appended to it.
+func appendLocked(path, heading, entry string) error {
+	if heading != "" {
+		return updateLocked(path, func(content string) string {
+			return insertEntry(content, heading, entry)
+		})
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("failed to lock: %w", err)
+	}
+	defer unlockFile(file)
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if size := info.Size(); size > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, size-1); err != nil {
+			return err
+		}
+		if last[0] != '\n' {
+			entry = "\n" + entry
+		}
+	}
+	_, err = file.WriteString(entry)
+	return err
+}
+
+// updateLocked rewrites a note with the result of update while holding an
+// exclusive lock on it
+func updateLocked(path string, update func(content string) string) error {
+	file, err := os.OpenFile(path, os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := lockFile(file); err != nil {
+		return fmt.Errorf("failed to lock: %w", err)
+	}
+	defer unlockFile(file)
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+
+	content := update(string(data))
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
+	_, err = file.WriteString(content)
+	return err
+}
+
+// insertEntry adds the entry at the end of the section under heading, or
+// at the end of the note. A missing heading is appended as a level 2 heading.
+func insertEntry(content, heading, entry string) string {
+	if heading == "" {
+		return joinBlock(content, entry)
+	}
+
+	title := strings.TrimSpace(strings.TrimLeft(heading, "#"))
+	lines := strings.SplitAfter(content, "\n")
+
+	// Headings are looked for outside frontmatter and code blocks
+	start, level, end := -1, 0, -1
+	markdown.EachLine(content, func(n int, line string) {
+		l, text := parseHeading(line)
+		if l == 0 || end >= 0 {
+			return
+		}
+		if start < 0 {
+			if strings.EqualFold(text, title) {
+				start, level = n-1, l
+			}
+			return
+		}
+		if l <= level {
+			end = n - 1
+		}
+	})
+
+	switch {
+	case start < 0:
+		return joinBlock(joinBlock(content, "")+"## "+title+"\n", entry)
+	case end >= 0:
+		// Insert before the next heading of the same or higher level
+		section := joinBlock(strings.Join(lines[:end], ""), entry)
+		return section + "\n" + strings.Join(lines[end:], "")
+	default:
+		return joinBlock(content, entry)
+	}
+}
+
+// joinBlock appends block after content, trimming trailing blank lines
+func joinBlock(content, block string) string {
+	content = strings.TrimRight(content, "\n")
+	if content == "" {
+		return block
+	}
+	if block == "" {
+		return content + "\n\n"
+	}
+	return content + "\n" + block
+}
+
+// parseHeading returns the level and text of an ATX heading line
+func parseHeading(line string) (int, string) {
+	line = strings.TrimRight(line, "\r\n")
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
+// momentTokens maps moment.js format tokens (used by Obsidian) to Go
+// layouts, longest first
+var momentTokens = []struct{ token, layout string }{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MMMM", "January"},
+	{"MMM", "Jan"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"dddd", "Monday"},
+	{"ddd", "Mon"},
+	{"DD", "02"},
+	{"D", "2"},
+	{"HH", "15"},
+	{"mm", "04"},
+	{"ss", "05"},
+}
+
+// formatMoment formats t with a moment.js format string. Text in square
+// brackets is copied literally.
+func formatMoment(format string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(format); {
+		if format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				b.WriteString(format[i+1 : i+end])
+				i += end + 1
+				continue
+			}
+		}
+
+		matched := false
+		for _, tok := range momentTokens {
+			if strings.HasPrefix(format[i:], tok.token) {
+				b.WriteString(t.Format(tok.layout))
+				i += len(tok.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[i])
+			i++
+		}
+	}
+	return b.String()
+}
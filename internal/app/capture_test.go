@@ -0,0 +1,164 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func newCaptureTestApp(t *testing.T, notesDir string) (*App, *git.MockService) {
+	t.Helper()
+	gitService := git.NewMockService(nil, "", 0, 0, nil)
+	return &App{
+		config:     &Config{Mode: "obsidian"},
+		gitService: gitService,
+		editor:     editor.NewMockService([]string{}, 0, nil),
+		fzf:        fzf.NewMockService("", false, nil),
+		frecency:   frecency.NewMockService([]string{}, nil),
+		notesDir:   notesDir,
+		mode:       "obsidian",
+	}, gitService.(*git.MockService)
+}
+
+func TestApp_Capture_Inbox(t *testing.T) {
+	tempDir := t.TempDir()
+	app, _ := newCaptureTestApp(t, tempDir)
+	at := time.Date(2024, 3, 5, 9, 7, 0, 0, time.Local)
+
+	if err := app.Capture(CaptureOptions{Text: "call Bob re: budget\n", Timestamp: true, At: at}); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if err := app.Capture(CaptureOptions{Text: "second\nwith detail", At: at}); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "inbox.md"))
+	if err != nil {
+		t.Fatalf("Expected inbox to be created: %v", err)
+	}
+	expected := "- 2024-03-05 09:07 call Bob re: budget\n- second\n  with detail\n"
+	if string(data) != expected {
+		t.Errorf("Expected inbox content %q, got %q", expected, string(data))
+	}
+
+	// Notes without a final newline get one before the entry
+	os.WriteFile(filepath.Join(tempDir, "inbox.md"), []byte("# Inbox"), 0644)
+	if err := app.Capture(CaptureOptions{Text: "third", At: at}); err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "inbox.md")); string(data) != "# Inbox\n- third\n" {
+		t.Errorf("Expected entry on its own line, got %q", string(data))
+	}
+}
+
+func TestApp_Capture_DailyNoteWithHeading(t *testing.T) {
+	tempDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tempDir, ".obsidian"), 0755)
+	os.WriteFile(filepath.Join(tempDir, ".obsidian", "daily-notes.json"),
+		[]byte(`{"folder": "Journal", "format": "YYYY/MM/[Day] DD"}`), 0644)
+
+	app, gitService := newCaptureTestApp(t, tempDir)
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.Local)
+
+	notePath := filepath.Join(tempDir, "Journal", "2024", "03", "Day 05.md")
+	os.MkdirAll(filepath.Dir(notePath), 0755)
+	os.WriteFile(notePath, []byte("# Today\n\n## Log\n- 08:00 start\n\n## Ideas\n- none\n"), 0644)
+
+	err := app.Capture(CaptureOptions{Text: "deployed", Daily: true, Heading: "## Log", Timestamp: true, Commit: true, At: at})
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(notePath)
+	expected := "# Today\n\n## Log\n- 08:00 start\n- 14:30 deployed\n\n## Ideas\n- none\n"
+	if string(data) != expected {
+		t.Errorf("Expected daily note content %q, got %q", expected, string(data))
+	}
+
+	if len(gitService.CommittedFiles) != 1 || gitService.CommittedFiles[0] != "Journal/2024/03/Day 05.md" {
+		t.Errorf("Expected daily note to be committed, got %v", gitService.CommittedFiles)
+	}
+}
+
+func TestApp_Capture_Errors(t *testing.T) {
+	app, _ := newCaptureTestApp(t, t.TempDir())
+
+	if err := app.Capture(CaptureOptions{Text: "  \n"}); err == nil {
+		t.Error("Expected error for empty capture, got nil")
+	}
+	if err := app.Capture(CaptureOptions{Text: "x", Target: "../outside.md"}); err == nil {
+		t.Error("Expected error for target outside the vault, got nil")
+	}
+}
+
+func TestApp_Capture_Parallel(t *testing.T) {
+	tempDir := t.TempDir()
+	app, _ := newCaptureTestApp(t, tempDir)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := app.Capture(CaptureOptions{Text: "entry", Target: "log.md", Heading: "Log"}); err != nil {
+				t.Errorf("Capture failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(filepath.Join(tempDir, "log.md"))
+	expected := "## Log\n"
+	for i := 0; i < 20; i++ {
+		expected += "- entry\n"
+	}
+	if string(data) != expected {
+		t.Errorf("Expected 20 intact entries, got %q", string(data))
+	}
+}
+
+func TestInsertEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		heading  string
+		expected string
+	}{
+		{"empty note", "", "", "- x\n"},
+		{"append trims blank lines", "# Note\n\ntext\n\n\n", "", "# Note\n\ntext\n- x\n"},
+		{"missing heading", "# Note\n", "Inbox", "# Note\n\n## Inbox\n- x\n"},
+		{"heading at end", "## Inbox\n- a\n", "inbox", "## Inbox\n- a\n- x\n"},
+		{"subheadings stay in section", "## A\n### B\n- b\n## C\n", "A", "## A\n### B\n- b\n- x\n\n## C\n"},
+		{"headings in code blocks", "## A\n```sh\n# Inbox\n## B\n```\n## Inbox\n", "Inbox", "## A\n```sh\n# Inbox\n## B\n```\n## Inbox\n- x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insertEntry(tt.content, tt.heading, "- x\n"); got != tt.expected {
+				t.Errorf("insertEntry() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatMoment(t *testing.T) {
+	at := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	tests := map[string]string{
+		"YYYY-MM-DD":           "2024-03-05",
+		"dddd, MMMM D":         "Tuesday, March 5",
+		"[Week of] YYYY-MM-DD": "Week of 2024-03-05",
+		"YY.M.D HH:mm":         "24.3.5 14:30",
+	}
+	for format, expected := range tests {
+		if got := formatMoment(format, at); got != expected {
+			t.Errorf("formatMoment(%q) = %q, expected %q", format, got, expected)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+)
+
+// Default limits for each kind of operation
+const (
+	defaultFetchTimeout = 30 * time.Second
+	defaultGitTimeout   = 10 * time.Second
+	defaultSyncTimeout  = 5 * time.Minute
+	defaultListTimeout  = 30 * time.Second
+	defaultHookTimeout  = time.Minute
+)
+
+// Timeouts bound how long each kind of operation may run. Zero uses the
+// default and a negative value means no limit. Pickers and editors wait on
+// the user, so they are never timed out.
+type Timeouts struct {
+	Fetch time.Duration `mapstructure:"fetch"` // Background git fetch
+	Git   time.Duration `mapstructure:"git"`   // Status, history, commits and other local git commands
+	Sync  time.Duration `mapstructure:"sync"`  // Sync and push, which talk to the remote
+	List  time.Duration `mapstructure:"list"`  // Listing and scoring the vault's notes
+	Hook  time.Duration `mapstructure:"hook"`  // Each lifecycle hook
+}
+
+// baseContext returns the context the app runs under, which main cancels
+// on SIGINT or SIGTERM
+func (a *App) baseContext() context.Context {
+	if a.ctx == nil {
+		return context.Background()
+	}
+	return a.ctx
+}
+
+// logger returns the logger the app's context carries
+func (a *App) logger() *slog.Logger {
+	return diag.Logger(a.baseContext())
+}
+
+// withTimeout derives a context from the app's, bounded by timeout or by
+// fallback when timeout is zero
+func (a *App) withTimeout(timeout, fallback time.Duration) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		timeout = fallback
+	}
+	if timeout < 0 {
+		return context.WithCancel(a.baseContext())
+	}
+	return context.WithTimeout(a.baseContext(), timeout)
+}
+
+func (a *App) timeouts() Timeouts {
+	if a.config == nil {
+		return Timeouts{}
+	}
+	return a.config.Timeouts
+}
+
+func (a *App) gitContext() (context.Context, context.CancelFunc) {
+	return a.withTimeout(a.timeouts().Git, defaultGitTimeout)
+}
+
+func (a *App) syncContext() (context.Context, context.CancelFunc) {
+	return a.withTimeout(a.timeouts().Sync, defaultSyncTimeout)
+}
+
+func (a *App) listContext() (context.Context, context.CancelFunc) {
+	return a.withTimeout(a.timeouts().List, defaultListTimeout)
+}
+
+func (a *App) hookContext() (context.Context, context.CancelFunc) {
+	return a.withTimeout(a.timeouts().Hook, defaultHookTimeout)
+}
+
+// untilDone runs serve until it returns or the app's context is done, for
+// servers that block reading their input
+func (a *App) untilDone(serve func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- serve() }()
+	select {
+	case err := <-done:
+		return err
+	case <-a.baseContext().Done():
+		return nil
+	}
+}
+
+// fetchInBackground starts a git fetch that may outlive the current
+// operation, bounded by the fetch timeout
+func (a *App) fetchInBackground() {
+	ctx, cancel := a.withTimeout(a.timeouts().Fetch, defaultFetchTimeout)
+	context.AfterFunc(ctx, cancel)
+	a.gitService.FetchAsync(ctx)
+}
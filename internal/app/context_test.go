@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func TestApp_withTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  time.Duration
+		wantNone bool
+		wantMax  time.Duration
+	}{
+		{"zero uses the fallback", 0, false, time.Minute},
+		{"configured timeout", time.Second, false, time.Second},
+		{"negative means no limit", -1, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{}
+			ctx, cancel := app.withTimeout(tt.timeout, time.Minute)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if ok == tt.wantNone {
+				t.Fatalf("Deadline() ok = %v, want %v", ok, !tt.wantNone)
+			}
+			if ok && time.Until(deadline) > tt.wantMax {
+				t.Errorf("deadline in %v, want at most %v", time.Until(deadline), tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestApp_withTimeout_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	app := &App{ctx: ctx}
+	cancel()
+
+	opCtx, opCancel := app.gitContext()
+	defer opCancel()
+	if !errors.Is(opCtx.Err(), context.Canceled) {
+		t.Errorf("Err() = %v, want context.Canceled", opCtx.Err())
+	}
+}
+
+func TestApp_Serve_Cancelled(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	app.ctx = ctx
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- app.Serve(l, "") }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() did not stop after cancel")
+	}
+}
+
+func TestApp_ServeMCP_Cancelled(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	app.ctx = ctx
+
+	// in never delivers anything, like an idle client's stdin
+	in, _ := net.Pipe()
+	defer in.Close()
+	done := make(chan error)
+	go func() { done <- app.ServeMCP(in, io.Discard, "test") }()
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeMCP() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeMCP() did not stop after cancel")
+	}
+}
+
+func TestApp_logger(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	app.gitService = git.NewMockService(errors.New("no upstream"), "", 0, 0, nil)
+	app.fzf = fzf.NewMockService("home.md", false, nil)
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	app.ctx = diag.WithLogger(context.Background(), logger)
+
+	if err := app.RunInteractive(""); err != nil {
+		t.Fatalf("RunInteractive() error = %v", err)
+	}
+	if log := buf.String(); !strings.Contains(log, `msg="git status check failed" error="no upstream"`) {
+		t.Errorf("log = %q", log)
+	}
+}
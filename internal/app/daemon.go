@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/api"
+	"github.com/shalomb/ob-cli/internal/index"
+)
+
+// DaemonOptions controls the background daemon
+type DaemonOptions struct {
+	FetchInterval time.Duration // Time between git fetches; zero disables fetching
+}
+
+// RunDaemon indexes the vault, keeps the index current as files change and
+// serves it on the vault's daemon socket until the app's context is done. It also runs
+// the background git fetch periodically, so interactive runs need not.
+func (a *App) RunDaemon(opts DaemonOptions) error {
+	socket := index.SocketPath(a.notesDir)
+	if err := os.MkdirAll(filepath.Dir(socket), 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	idx := index.New(a.notesDir)
+	if err := idx.Build(); err != nil {
+		return fmt.Errorf("failed to index %s: %w", a.notesDir, err)
+	}
+
+	listener, err := api.Listen("", socket)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socket, err)
+	}
+	server := &http.Server{Handler: index.Handler(idx), ReadHeaderTimeout: 10 * time.Second}
+	defer server.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	failed := make(chan error, 2)
+	go func() { failed <- index.Watch(idx, stop) }()
+	go func() { failed <- server.Serve(listener) }()
+	if opts.FetchInterval > 0 {
+		go a.fetchPeriodically(opts.FetchInterval, stop)
+	}
+
+	fmt.Fprintf(os.Stderr, "Indexed %d notes in %s\nListening on %s\n", len(idx.Notes()), a.notesDir, socket)
+	select {
+	case <-a.baseContext().Done():
+		return nil
+	case err := <-failed:
+		return err
+	}
+}
+
+// fetchPeriodically runs the background git fetch now and every interval
+// until stop is closed
+func (a *App) fetchPeriodically(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		a.fetchInBackground()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
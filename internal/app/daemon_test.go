@@ -0,0 +1,58 @@
+//go:build unix
+
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/index"
+)
+
+func TestApp_RunDaemon(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	app, _ := newNotesTestApp(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	app.ctx = ctx
+
+	done := make(chan error)
+	go func() { done <- app.RunDaemon(DaemonOptions{}) }()
+
+	client := index.NewClient(index.SocketPath(app.notesDir))
+	deadline := time.Now().Add(5 * time.Second)
+	for !client.Running(context.Background()) {
+		if time.Now().After(deadline) {
+			t.Fatal("daemon did not start")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Backlinks come from the daemon's index once it is running
+	app.index = client
+	os.WriteFile(filepath.Join(app.notesDir, "ideas.md"), []byte("Part of [[plan]]\n"), 0644)
+	deadline = time.Now().Add(5 * time.Second)
+	for {
+		backlinks, err := app.Backlinks("projects/plan.md")
+		if err != nil {
+			t.Fatalf("Backlinks() error = %v", err)
+		}
+		if len(backlinks) == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("backlinks = %+v", backlinks)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("RunDaemon() error = %v", err)
+	}
+	if client.Running(context.Background()) {
+		t.Error("daemon still running after cancel")
+	}
+}
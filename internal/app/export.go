@@ -0,0 +1,23 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/shalomb/ob-cli/internal/export"
+)
+
+// ExportHTML renders the vault, or the folder in opts.Path, as a static
+// HTML site in opts.Out
+func (a *App) ExportHTML(opts export.Options) error {
+	files, err := a.sortedFiles()
+	if err != nil {
+		return fmt.Errorf("failed to get file list: %w", err)
+	}
+
+	result, err := export.HTML(a.notesDir, files, opts)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	fmt.Printf("Exported %d notes and %d attachments to %s\n", result.Pages, result.Assets, opts.Out)
+	return nil
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/export"
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func TestApp_ExportHTML(t *testing.T) {
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "note.md"), []byte("# Note\n"), 0644)
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{"exports vault", nil, false},
+		{"file list error", errors.New("walk failed"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{
+				config:     &Config{Mode: "tips"},
+				gitService: git.NewMockService(nil, "", 0, 0, nil),
+				editor:     editor.NewMockService([]string{}, 0, nil),
+				fzf:        fzf.NewMockService("", true, nil),
+				frecency:   frecency.NewMockService([]string{"note.md"}, tt.err),
+				notesDir:   tempDir,
+				mode:       "tips",
+			}
+			out := filepath.Join(t.TempDir(), "site")
+
+			var err error
+			captureStdout(t, func() { err = app.ExportHTML(export.Options{Out: out}) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportHTML() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, statErr := os.Stat(filepath.Join(out, "note.html")); !tt.wantErr && statErr != nil {
+				t.Errorf("note.html not written: %v", statErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/canvas"
+	"github.com/shalomb/ob-cli/internal/filetype"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/hook"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+// markers label file types in the picker
+var markers = map[string]string{
+	filetype.Markdown:   "md",
+	filetype.Excalidraw: "draw",
+	filetype.Canvas:     "canvas",
+	filetype.Image:      "image",
+	filetype.PDF:        "pdf",
+	filetype.Other:      "file",
+}
+
+// listedFiles returns the vault's files of the selected types, most
+// relevant first
+func (a *App) listedFiles() ([]model.NoteInfo, error) {
+	ctx, cancel := a.listContext()
+	defer cancel()
+	return a.frecency.GetFiles(ctx, a.config.Types)
+}
+
+// selectFiles runs fzf on the vault's files with query, returning one
+// selection, or several when multi is set. Notes alone are listed by path;
+// other types are marked, canvases described and the highlighted file
+// previewed.
+func (a *App) selectFiles(query string, multi bool) ([]string, error) {
+	files, err := a.listedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+
+	var selections []string
+	if a.config.Types.NotesOnly() {
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = file.Path
+		}
+		if multi {
+			selections, err = a.fzf.SelectFiles(a.baseContext(), paths, query)
+		} else {
+			var selection string
+			selection, err = a.fzf.SelectFile(a.baseContext(), paths, query)
+			if selection != "" {
+				selections = []string{selection}
+			}
+		}
+	} else {
+		entries := make([]fzf.Entry, len(files))
+		for i, file := range files {
+			fileType := filetype.Of(file.Path)
+			entries[i] = fzf.Entry{Marker: markers[fileType], Path: file.Path}
+			if fileType == filetype.Canvas {
+				entries[i].Description = a.canvasSummary(file.Path)
+			}
+		}
+		selections, err = a.fzf.SelectEntries(a.baseContext(), entries, query, multi, a.previewCommand())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fzf selection failed: %w", err)
+	}
+	return selections, nil
+}
+
+// previewCommand is the fzf preview of the highlighted file
+func (a *App) previewCommand() string {
+	return a.selfCommand("preview {2}")
+}
+
+// selfCommand is a shell command running this executable with args, with
+// the vault set so it skips discovery. fzf previews call back into ob-cli
+// with it, so they use the configured services, such as the git backend.
+func (a *App) selfCommand(args string) string {
+	executable, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s_VAULT=%s %s --mode %s %s",
+		strings.ToUpper(a.mode), shellQuote(a.notesDir), shellQuote(executable), a.mode, args)
+}
+
+// openFile opens a vault file after the pre-open hooks: with the opener
+// configured for its type, else text in the editor and binary files, such
+// as images and PDFs, with the system's opener
+func (a *App) openFile(file string) error {
+	path := filepath.Join(a.notesDir, file)
+	fileType := filetype.Of(file)
+	if a.config.Vaults[a.mode].Open[fileType] == "" && !filetype.Binary(path) {
+		return a.openInEditor(file, 0)
+	}
+
+	if err := a.runHook(hook.Event{Name: hook.PreOpen, Note: file}); err != nil {
+		return err
+	}
+	return a.opener.Open(a.baseContext(), path, fileType)
+}
+
+// Preview prints a vault file for reading in a terminal: text as it is, a
+// canvas as its cards and links, and other files as their type and size
+func (a *App) Preview(file string) error {
+	path, err := a.notePath(file)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", file)
+	}
+
+	fileType := filetype.Of(file)
+	if filetype.Binary(path) {
+		fmt.Printf("%s: %s, %d bytes, modified %s\n", file, fileType, info.Size(), info.ModTime().Format("2006-01-02 15:04"))
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if fileType == filetype.Canvas {
+		c, err := canvas.Parse(data)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		fmt.Print(c.Text())
+		return nil
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
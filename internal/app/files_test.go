@@ -0,0 +1,169 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/canvas"
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/filetype"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+const testCanvas = `{"nodes": [{"id": "a", "type": "text", "text": "Idea"}, {"id": "b", "type": "file", "file": "home.md"}],
+	"edges": [{"id": "1", "fromNode": "a", "toNode": "b"}]}`
+
+// newFilesTestApp returns a notes test app whose vault also holds a canvas,
+// an image and a PDF
+func newFilesTestApp(t *testing.T) *App {
+	t.Helper()
+	app, frecencyService := newNotesTestApp(t)
+	files := []struct{ name, content string }{
+		{"board.canvas", testCanvas},
+		{"img/cat.png", "\x89PNG\x00"},
+		{"docs/spec.pdf", "%PDF-1.7\x00"},
+	}
+	for _, file := range files {
+		path := filepath.Join(app.notesDir, file.name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(file.content), 0644)
+		frecencyService.Files = append(frecencyService.Files, file.name)
+	}
+	return app
+}
+
+func TestApp_ListFiles_Types(t *testing.T) {
+	app := newFilesTestApp(t)
+
+	out := captureStdout(t, func() {
+		if err := app.ListFiles(Output{}); err != nil {
+			t.Fatalf("ListFiles() error = %v", err)
+		}
+	})
+	if strings.Contains(out, "board.canvas") {
+		t.Errorf("ListFiles() without types listed %q", out)
+	}
+
+	app.config.Types = filetype.Set{filetype.Canvas: true, filetype.PDF: true}
+	out = captureStdout(t, func() {
+		if err := app.ListFiles(Output{Format: OutputJSON}); err != nil {
+			t.Fatalf("ListFiles() error = %v", err)
+		}
+	})
+	var notes []model.NoteInfo
+	if err := json.Unmarshal([]byte(out), &notes); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("ListFiles() listed %+v, want the canvas and the PDF", notes)
+	}
+	if notes[0].Path != "board.canvas" || notes[0].Type != filetype.Canvas || notes[0].Summary != "2 cards, 1 link" || notes[0].Title != "board" {
+		t.Errorf("canvas = %+v", notes[0])
+	}
+	if notes[1].Type != filetype.PDF || notes[1].Summary != "" {
+		t.Errorf("PDF = %+v", notes[1])
+	}
+}
+
+func TestApp_RunInteractive_Types(t *testing.T) {
+	tests := []struct {
+		selection string
+		open      map[string]string
+		editor    bool // Opened in the editor rather than the opener
+	}{
+		{"home.md", nil, true},
+		{"board.canvas", nil, true},
+		{"board.canvas", map[string]string{filetype.Canvas: "obsidian-open"}, false},
+		{"img/cat.png", nil, false},
+		{"docs/spec.pdf", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selection, func(t *testing.T) {
+			app := newFilesTestApp(t)
+			app.config.Types = filetype.All
+			app.config.Vaults = map[string]VaultConfig{"tips": {Open: tt.open}}
+			app.fzf = fzf.NewMockService(tt.selection, false, nil)
+			editorService := app.editor.(*editor.MockService)
+			opener := app.opener.(*editor.MockOpener)
+
+			if err := app.RunInteractive(""); err != nil {
+				t.Fatalf("RunInteractive() error = %v", err)
+			}
+			if tt.editor {
+				if len(editorService.OpenedFiles) != 1 || len(opener.OpenedFiles) != 0 {
+					t.Errorf("editor opened %v, opener %v", editorService.OpenedFiles, opener.OpenedFiles)
+				}
+				return
+			}
+			if len(editorService.OpenedFiles) != 0 || len(opener.OpenedFiles) != 1 {
+				t.Fatalf("editor opened %v, opener %v", editorService.OpenedFiles, opener.OpenedFiles)
+			}
+			if want := filepath.Join(app.notesDir, tt.selection); opener.OpenedFiles[0] != want || opener.OpenedTypes[0] != filetype.Of(want) {
+				t.Errorf("opener opened %s as %s, want %s", opener.OpenedFiles[0], opener.OpenedTypes[0], want)
+			}
+		})
+	}
+}
+
+func TestApp_RunInteractive_MissingBinary(t *testing.T) {
+	app := newFilesTestApp(t)
+	err := app.RunInteractive("img/dog.png")
+	if !errors.Is(err, errs.ErrInvalidPath) {
+		t.Errorf("RunInteractive() error = %v, want %v", err, errs.ErrInvalidPath)
+	}
+	if _, statErr := os.Stat(filepath.Join(app.notesDir, "img", "dog.png")); statErr == nil {
+		t.Error("an empty image was created")
+	}
+}
+
+func TestApp_RunInteractive_NewCanvas(t *testing.T) {
+	app := newFilesTestApp(t)
+	captureStderr(t, func() {
+		if err := app.RunInteractive("boards/new.canvas"); err != nil {
+			t.Fatalf("RunInteractive() error = %v", err)
+		}
+	})
+	data, err := os.ReadFile(filepath.Join(app.notesDir, "boards", "new.canvas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := canvas.Parse(data); err != nil || string(data) != canvas.Empty {
+		t.Errorf("new canvas = %q, want %q", data, canvas.Empty)
+	}
+}
+
+func TestApp_Preview(t *testing.T) {
+	app := newFilesTestApp(t)
+
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"home.md", "# Home\n"},
+		{"board.canvas", "2 cards, 1 link\n\nCards:\n  [text] Idea\n  [file] home.md\n\nLinks:\n  Idea → home.md\n"},
+		{"docs/spec.pdf", "docs/spec.pdf: pdf, 9 bytes, modified "},
+	}
+	for _, tt := range tests {
+		out := captureStdout(t, func() {
+			if err := app.Preview(tt.file); err != nil {
+				t.Errorf("Preview(%s) error = %v", tt.file, err)
+			}
+		})
+		if !strings.HasPrefix(out, tt.want) {
+			t.Errorf("Preview(%s) = %q, want %q", tt.file, out, tt.want)
+		}
+	}
+
+	if err := app.Preview("missing.md"); err == nil {
+		t.Error("Preview() of a missing file succeeded")
+	}
+	if err := app.Preview("../outside.md"); !errors.Is(err, ErrOutsideVault) {
+		t.Errorf("Preview() outside the vault error = %v", err)
+	}
+}
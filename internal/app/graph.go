@@ -0,0 +1,107 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/graph"
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// GraphOptions selects the notes, format and report of Graph
+type GraphOptions struct {
+	graph.Options
+	Format  string // dot (default), graphml or json
+	Metrics bool   // Report metrics instead of the graph
+	Top     int    // Entries in the ranked metrics lists (default 10)
+}
+
+// Graph writes the note graph, or a report of its metrics, to out
+func (a *App) Graph(out io.Writer, opts GraphOptions) error {
+	notes, err := a.collectGraphNotes()
+	if err != nil {
+		return err
+	}
+
+	g, err := graph.Build(notes, opts.Options)
+	if err != nil {
+		return err
+	}
+
+	if !opts.Metrics {
+		return graph.Write(out, g, opts.Format)
+	}
+
+	top := opts.Top
+	if top <= 0 {
+		top = 10
+	}
+	metrics := graph.ComputeMetrics(g, top)
+	switch opts.Format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(metrics)
+	case "", "dot":
+		printMetrics(out, metrics)
+		return nil
+	}
+	return errs.New(errs.ErrUsage, "invalid format %q for metrics: use json or leave unset", opts.Format)
+}
+
+// collectGraphNotes parses the links and tags of every note in the vault
+func (a *App) collectGraphNotes() ([]graph.Note, error) {
+	files, err := a.sortedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+
+	notes := make([]graph.Note, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(a.notesDir, file))
+		if err != nil {
+			continue // Skip notes that vanished or cannot be read
+		}
+		content := string(data)
+		notes = append(notes, graph.Note{
+			File:  file,
+			Title: markdown.Title(file, content),
+			Links: markdown.ParseLinks(content),
+			Tags:  markdown.NoteTags(content),
+		})
+	}
+	return notes, nil
+}
+
+// printMetrics renders a metrics report as text
+func printMetrics(out io.Writer, m graph.Metrics) {
+	fmt.Fprintf(out, "Notes:      %d\n", m.Notes)
+	fmt.Fprintf(out, "Links:      %d\n", m.Links)
+	largest := 0
+	if len(m.Components) > 0 {
+		largest = m.Components[0]
+	}
+	fmt.Fprintf(out, "Components: %d (largest %d notes)\n", len(m.Components), largest)
+	fmt.Fprintf(out, "Orphans:    %d\n", len(m.Orphans))
+
+	sections := []struct {
+		title string
+		ranks []graph.Rank
+	}{
+		{"Most connected", m.Hubs},
+		{"Most backlinks", m.Backlinked},
+	}
+	for _, s := range sections {
+		if len(s.ranks) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "\n%s:\n", s.title)
+		for _, r := range s.ranks {
+			fmt.Fprintf(out, "  %4d  %s\n", r.Count, r.ID)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+	"github.com/shalomb/ob-cli/internal/graph"
+)
+
+func newGraphTestApp(t *testing.T) *App {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	notes := map[string]string{
+		"hub.md":    "# Hub\n[[a]] [[b]] #work\n",
+		"a.md":      "[[hub]] ![[b]]\n",
+		"b.md":      "# B\n",
+		"lonely.md": "# Lonely\n",
+	}
+	var files []string
+	for name, content := range notes {
+		os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644)
+		files = append(files, name)
+	}
+
+	return &App{
+		config:     &Config{Mode: "tips"},
+		gitService: git.NewMockService(nil, "", 0, 0, nil),
+		editor:     editor.NewMockService([]string{}, 0, nil),
+		fzf:        fzf.NewMockService("", true, nil),
+		frecency:   frecency.NewMockService(files, nil),
+		notesDir:   tempDir,
+		mode:       "tips",
+	}
+}
+
+func TestApp_Graph(t *testing.T) {
+	app := newGraphTestApp(t)
+
+	var out bytes.Buffer
+	if err := app.Graph(&out, GraphOptions{Format: "dot", Options: graph.Options{Tags: true}}); err != nil {
+		t.Fatalf("Graph() error = %v", err)
+	}
+	for _, want := range []string{
+		`"hub.md" [label="Hub"];`,
+		`"a.md" -> "b.md" [style=dashed];`,
+		`"hub.md" -> "#work" [style=dotted, arrowhead=none];`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Graph() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestApp_Graph_Metrics(t *testing.T) {
+	app := newGraphTestApp(t)
+
+	var out bytes.Buffer
+	if err := app.Graph(&out, GraphOptions{Metrics: true}); err != nil {
+		t.Fatalf("Graph() error = %v", err)
+	}
+	for _, want := range []string{"Notes:      4", "Components: 2 (largest 3 notes)", "Orphans:    1", "Most backlinks:\n     2  b.md"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("metrics report missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := app.Graph(&out, GraphOptions{Metrics: true, Format: "graphml"}); err == nil {
+		t.Error("Graph() expected error for graphml metrics")
+	}
+}
@@ -0,0 +1,240 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+// RestoreOptions controls restoring a note from git
+type RestoreOptions struct {
+	At    string // Revision or date; picked with fzf when empty
+	Stash bool   // Stash uncommitted changes to the note instead of refusing
+}
+
+// History prints the commits touching a note, newest first, following
+// renames
+func (a *App) History(out io.Writer, file string) error {
+	file, commits, err := a.noteHistory(file)
+	if err != nil {
+		return err
+	}
+	for _, c := range commits {
+		line := fmt.Sprintf("%s  %s  %s  %s", c.ShortHash, c.Date.Format("2006-01-02"), c.Author, c.Subject)
+		if c.Path != file {
+			line += "  (" + c.Path + ")"
+		}
+		fmt.Fprintln(out, line)
+	}
+	return nil
+}
+
+// PickRevision lets the user pick a commit touching a note with fzf,
+// previewing the note as it was at each commit, and returns its hash
+// (empty when cancelled)
+func (a *App) PickRevision(file string) (string, error) {
+	file, commits, err := a.noteHistory(file)
+	if err != nil {
+		return "", err
+	}
+
+	lines := make([]string, len(commits))
+	for i, c := range commits {
+		lines[i] = strings.Join([]string{c.ShortHash, c.Date.Format("2006-01-02 15:04"), c.Author, c.Subject, c.Hash, c.Path}, "\t")
+	}
+	// Shown by "ob-cli show", through the configured git backend; fzf
+	// quotes {5}, which the shell joins to the quoted note
+	preview := a.selfCommand("show " + shellQuote(file+"@") + "{5}")
+	selection, err := a.fzf.SelectLine(a.baseContext(), lines, preview)
+	if err != nil || selection == "" {
+		return "", err
+	}
+	fields := strings.Split(selection, "\t")
+	if len(fields) < 5 {
+		return "", fmt.Errorf("unexpected selection %q", selection)
+	}
+	return fields[4], nil
+}
+
+// Diff prints the changes to a note since rev, or its uncommitted changes
+// when rev is empty
+func (a *App) Diff(out io.Writer, file, rev string) error {
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	file, err := a.historyPath(file)
+	if err != nil {
+		return err
+	}
+	if rev != "" {
+		if rev, err = a.gitService.ResolveRevision(ctx, rev); err != nil {
+			return err
+		}
+	}
+	diff, err := a.gitService.Diff(ctx, file, rev)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, diff)
+	return nil
+}
+
+// Show prints a note as it was at rev, picking the revision with fzf when
+// rev is empty
+func (a *App) Show(out io.Writer, file, rev string) error {
+	file, rev, err := a.noteRevision(file, rev)
+	if err != nil || rev == "" {
+		return err
+	}
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	content, err := a.gitService.Show(ctx, file, rev)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, content)
+	return nil
+}
+
+// Restore replaces a note with its content at a revision or date. The
+// restored content is left uncommitted. Uncommitted changes to the note
+// are stashed when asked, otherwise the restore is refused.
+func (a *App) Restore(file string, opts RestoreOptions) error {
+	// Refused before picking a revision, rather than once it is picked
+	if opts.Stash && a.config.GitBackend == git.BackendGo {
+		return errs.New(errs.ErrUsage, "restore --stash needs the exec git backend: the go backend has no stash")
+	}
+	file, rev, err := a.noteRevision(file, opts.At)
+	if err != nil || rev == "" {
+		return err
+	}
+	fullPath, err := a.notePath(file)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	content, err := a.gitService.Show(ctx, file, rev)
+	if err != nil {
+		return err
+	}
+
+	changed, err := a.gitService.HasChanges(ctx, file)
+	if err != nil {
+		return err
+	}
+	if changed {
+		if !opts.Stash {
+			return fmt.Errorf("%s has uncommitted changes; commit them or use --stash", file)
+		}
+		if err := a.gitService.StashFile(ctx, file, "ob-cli restore "+file); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Stashed uncommitted changes to %s\n", file)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", file, err)
+	}
+	if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	fmt.Fprintf(os.Stderr, "Restored %s from %s\n", file, shortHash(rev))
+	return nil
+}
+
+// noteRevision resolves a note and a revision of it, picking one with fzf
+// when rev is empty. The revision is empty when the pick was cancelled.
+func (a *App) noteRevision(file, rev string) (string, string, error) {
+	file, err := a.historyPath(file)
+	if err != nil {
+		return "", "", err
+	}
+	if rev == "" {
+		rev, err = a.PickRevision(file)
+		return file, rev, err
+	}
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	rev, err = a.gitService.ResolveRevision(ctx, rev)
+	return file, rev, err
+}
+
+// SplitRevision splits a "<note>@<rev>" argument. A note whose name has an
+// "@" is kept whole, and revisions such as HEAD@{1} are kept whole by
+// splitting at the first "@" after an existing note; for a note that no
+// longer exists, at the first "@".
+func (a *App) SplitRevision(arg string) (string, string) {
+	if a.noteExists(arg) {
+		return arg, ""
+	}
+	for i := 1; i < len(arg); i++ {
+		if arg[i] == '@' && a.noteExists(arg[:i]) {
+			return arg[:i], arg[i+1:]
+		}
+	}
+	if i := strings.Index(arg, "@"); i > 0 {
+		return arg[:i], arg[i+1:]
+	}
+	return arg, ""
+}
+
+// noteExists reports whether a vault path, with or without ".md", is a file
+func (a *App) noteExists(file string) bool {
+	fullPath, err := a.notePath(file)
+	if err != nil {
+		return false
+	}
+	for _, path := range []string{fullPath, withNoteExt(fullPath)} {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// noteHistory returns a note's commits, failing when there are none
+func (a *App) noteHistory(file string) (string, []git.Commit, error) {
+	file, err := a.historyPath(file)
+	if err != nil {
+		return "", nil, err
+	}
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	commits, err := a.gitService.History(ctx, file)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(commits) == 0 {
+		return "", nil, fmt.Errorf("%s has no git history", file)
+	}
+	return file, commits, nil
+}
+
+// historyPath resolves a note path for git, which may name a note that no
+// longer exists
+func (a *App) historyPath(file string) (string, error) {
+	if _, err := a.notePath(file); err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(a.notesDir, file)); err != nil {
+		file = withNoteExt(file)
+	}
+	return filepath.ToSlash(filepath.Clean(file)), nil
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
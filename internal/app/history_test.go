@@ -0,0 +1,133 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+// newHistoryTestApp returns an app whose git history of projects/plan.md
+// has two commits, the older one from before a rename
+func newHistoryTestApp(t *testing.T) (*App, *git.MockService) {
+	t.Helper()
+	app, _ := newNotesTestApp(t)
+	gitService := app.gitService.(*git.MockService)
+	gitService.Commits = []git.Commit{
+		{Hash: "bbbbbbbbbb", ShortHash: "bbbbbbb", Author: "Ann", Date: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), Subject: "Edit plan", Path: "projects/plan.md"},
+		{Hash: "aaaaaaaaaa", ShortHash: "aaaaaaa", Author: "Bob", Date: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Subject: "Add plan", Path: "plan.md"},
+	}
+	gitService.Contents = map[string]string{"aaaaaaaaaa": "# Old plan\n", "bbbbbbbbbb": "# Plan\n"}
+	return app, gitService
+}
+
+func TestApp_History(t *testing.T) {
+	app, _ := newHistoryTestApp(t)
+
+	var out bytes.Buffer
+	if err := app.History(&out, "projects/plan"); err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	expected := "bbbbbbb  2024-03-01  Ann  Edit plan\naaaaaaa  2024-01-01  Bob  Add plan  (plan.md)\n"
+	if out.String() != expected {
+		t.Errorf("History() = %q, expected %q", out.String(), expected)
+	}
+
+	if err := app.History(&out, "../outside.md"); err == nil {
+		t.Error("History() outside the vault succeeded")
+	}
+}
+
+func TestApp_ShowAndPickRevision(t *testing.T) {
+	app, _ := newHistoryTestApp(t)
+
+	var out bytes.Buffer
+	if err := app.Show(&out, "projects/plan.md", "aaaaaaaaaa"); err != nil || out.String() != "# Old plan\n" {
+		t.Errorf("Show() = %q, %v", out.String(), err)
+	}
+	if err := app.Show(&out, "projects/plan.md", "nope"); err == nil {
+		t.Error("Show() at an unknown revision succeeded")
+	}
+
+	picker := fzf.NewMockService("aaaaaaa\t2024-01-01 09:00\tBob\tAdd plan\taaaaaaaaaa\tplan.md", false, nil)
+	app.fzf = picker
+	out.Reset()
+	if err := app.Show(&out, "projects/plan.md", ""); err != nil || out.String() != "# Old plan\n" {
+		t.Errorf("Show() with picked revision = %q, %v", out.String(), err)
+	}
+	// The preview calls back into ob-cli rather than running git
+	if preview := picker.(*fzf.MockService).Preview; !strings.HasSuffix(preview, " show 'projects/plan.md@'{5}") || strings.Contains(preview, "git ") {
+		t.Errorf("preview = %q", preview)
+	}
+
+	app.fzf = fzf.NewMockService("", true, nil)
+	out.Reset()
+	if err := app.Show(&out, "projects/plan.md", ""); !errors.Is(err, errs.ErrCancelled) || out.Len() != 0 {
+		t.Errorf("Show() with cancelled pick = %q, %v", out.String(), err)
+	}
+}
+
+func TestApp_SplitRevision(t *testing.T) {
+	app, _ := newHistoryTestApp(t)
+	os.WriteFile(filepath.Join(app.notesDir, "me@work.md"), []byte("x"), 0644)
+
+	tests := []struct{ arg, note, rev string }{
+		{"projects/plan.md@HEAD~1", "projects/plan.md", "HEAD~1"},
+		{"projects/plan@HEAD@{1}", "projects/plan", "HEAD@{1}"},
+		{"me@work.md", "me@work.md", ""},
+		{"me@work@2024-03-01", "me@work", "2024-03-01"},
+		{"deleted.md@HEAD@{2}", "deleted.md", "HEAD@{2}"},
+		{"projects/plan.md", "projects/plan.md", ""},
+	}
+	for _, tt := range tests {
+		if note, rev := app.SplitRevision(tt.arg); note != tt.note || rev != tt.rev {
+			t.Errorf("SplitRevision(%q) = %q, %q, expected %q, %q", tt.arg, note, rev, tt.note, tt.rev)
+		}
+	}
+}
+
+func TestApp_Restore(t *testing.T) {
+	app, gitService := newHistoryTestApp(t)
+	path := filepath.Join(app.notesDir, "projects", "plan.md")
+
+	gitService.Changed = true
+	err := app.Restore("projects/plan.md", RestoreOptions{At: "aaaaaaaaaa"})
+	if err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("Restore() with uncommitted changes error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); !strings.HasPrefix(string(data), "# Plan\nShip") {
+		t.Errorf("note changed by a refused restore: %q", data)
+	}
+
+	captureStderr(t, func() { err = app.Restore("projects/plan.md", RestoreOptions{At: "aaaaaaaaaa", Stash: true}) })
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if !reflect.DeepEqual(gitService.StashedFiles, []string{"projects/plan.md"}) {
+		t.Errorf("stashed files = %v", gitService.StashedFiles)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "# Old plan\n" {
+		t.Errorf("restored content = %q", data)
+	}
+
+	if err := app.Restore("projects/plan.md", RestoreOptions{At: "yesterday"}); err == nil {
+		t.Error("Restore() at an unknown revision succeeded")
+	}
+
+	gitService.StashedFiles = nil
+	app.config.GitBackend = git.BackendGo
+	if err := app.Restore("projects/plan.md", RestoreOptions{At: "aaaaaaaaaa", Stash: true}); !errors.Is(err, errs.ErrUsage) {
+		t.Errorf("Restore() --stash on the go backend error = %v, want %v", err, errs.ErrUsage)
+	}
+	if len(gitService.StashedFiles) != 0 {
+		t.Errorf("stashed files = %v on the go backend", gitService.StashedFiles)
+	}
+}
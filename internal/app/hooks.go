@@ -0,0 +1,68 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/shalomb/ob-cli/internal/hook"
+)
+
+// runHook runs the vault's hooks for e, filling in the vault, the mode and
+// the note's full path. Only pre- hooks return errors.
+func (a *App) runHook(e hook.Event) error {
+	if a.hooks == nil {
+		return nil
+	}
+	e.Vault, e.Mode = a.notesDir, a.mode
+	if e.Note != "" {
+		e.Path = filepath.Join(a.notesDir, e.Note)
+	}
+	ctx, cancel := a.hookContext()
+	defer cancel()
+	return a.hooks.Run(ctx, e)
+}
+
+// noteCreated runs the post-create hooks for a new note
+func (a *App) noteCreated(file string) {
+	a.runHook(hook.Event{Name: hook.PostCreate, Note: file})
+}
+
+// openInEditor opens a note in the editor, at line unless it is 0, after
+// the pre-open hooks. The editor gets the note's full path, as it runs in
+// the working directory. The post-edit hooks run when the editor returns
+// and the note's content changed; a running nvim is waited on for them.
+func (a *App) openInEditor(file string, line int) error {
+	if err := a.runHook(hook.Event{Name: hook.PreOpen, Note: file}); err != nil {
+		return err
+	}
+
+	path := filepath.Join(a.notesDir, file)
+	before := fileHash(path)
+	var err error
+	if line > 0 {
+		err = a.editor.OpenFileAt(a.baseContext(), path, line)
+	} else {
+		err = a.editor.OpenFile(a.baseContext(), path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if after := fileHash(path); after != before {
+		a.runHook(hook.Event{Name: hook.PostEdit, Note: file, OldHash: before, NewHash: after})
+	}
+	return nil
+}
+
+// fileHash returns the hex SHA-256 of a file's content, or "" if it can't
+// be read
+func fileHash(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/git"
+	"github.com/shalomb/ob-cli/internal/hook"
+)
+
+// newHooksTestApp returns a notes test app whose hooks append their event
+// and note to the vault's "log" file
+func newHooksTestApp(t *testing.T, commands map[string][]string) *App {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks need a POSIX shell")
+	}
+	app, _ := newNotesTestApp(t)
+	for _, event := range []string{hook.PreOpen, hook.PostEdit, hook.PostCreate, hook.PreSync, hook.PostSync} {
+		if commands[event] == nil {
+			commands[event] = []string{`echo "$OB_HOOK $OB_NOTE" >> log`}
+		}
+	}
+	app.hooks = hook.NewRunner(app.notesDir, commands, false)
+	return app
+}
+
+func hookLog(t *testing.T, app *App) string {
+	t.Helper()
+	data, _ := os.ReadFile(filepath.Join(app.notesDir, "log"))
+	return string(data)
+}
+
+func TestApp_Hooks_Open(t *testing.T) {
+	app := newHooksTestApp(t, map[string][]string{})
+	editorService := app.editor.(*editor.MockService)
+
+	captureStderr(t, func() {
+		if err := app.RunInteractive("drafts/new.md"); err != nil {
+			t.Fatalf("RunInteractive() error = %v", err)
+		}
+	})
+	// The mock editor leaves the note unchanged, so post-edit doesn't run
+	if got, want := hookLog(t, app), "post-create drafts/new.md\npre-open drafts/new.md\n"; got != want {
+		t.Errorf("hooks ran %q, want %q", got, want)
+	}
+	if len(editorService.OpenedFiles) != 1 {
+		t.Errorf("editor opened %v", editorService.OpenedFiles)
+	}
+}
+
+func TestApp_Hooks_PreOpenAborts(t *testing.T) {
+	app := newHooksTestApp(t, map[string][]string{
+		hook.PreOpen: {`echo "refusing $OB_NOTE" >&2; exit 1`},
+	})
+	editorService := app.editor.(*editor.MockService)
+
+	var err error
+	stderr := captureStderr(t, func() { err = app.RunInteractive("home.md") })
+	if !errors.Is(err, errs.ErrHookFailed) {
+		t.Errorf("RunInteractive() error = %v, want %v", err, errs.ErrHookFailed)
+	}
+	if stderr != "refusing home.md\n" {
+		t.Errorf("hook printed %q", stderr)
+	}
+	if len(editorService.OpenedFiles) != 0 {
+		t.Errorf("editor opened %v after pre-open aborted", editorService.OpenedFiles)
+	}
+}
+
+func TestApp_Hooks_Sync(t *testing.T) {
+	app := newHooksTestApp(t, map[string][]string{
+		hook.PostSync: {`grep -q '"error":"rejected"' && echo "post-sync failed" >> log`},
+	})
+	app.gitService = git.NewMockService(nil, "", 0, 0, errors.New("rejected"))
+
+	if err := app.SyncWithRemote(); err == nil {
+		t.Fatal("SyncWithRemote() succeeded, want the sync error")
+	}
+	if got, want := hookLog(t, app), "pre-sync \npost-sync failed\n"; got != want {
+		t.Errorf("hooks ran %q, want %q", got, want)
+	}
+
+	app = newHooksTestApp(t, map[string][]string{hook.PreSync: {"exit 1"}})
+	if err := app.SyncWithRemote(); !errors.Is(err, errs.ErrHookFailed) {
+		t.Errorf("SyncWithRemote() error = %v, want %v", err, errs.ErrHookFailed)
+	}
+	if got := hookLog(t, app); got != "" {
+		t.Errorf("hooks ran %q after pre-sync aborted", got)
+	}
+}
+
+func TestApp_Hooks_CreateNote(t *testing.T) {
+	app := newHooksTestApp(t, map[string][]string{
+		hook.PostCreate: {`cat "$OB_NOTE_PATH" >> log`},
+	})
+
+	captureStderr(t, func() {
+		if _, err := app.CreateNote("ideas/next", "# Next"); err != nil {
+			t.Fatalf("CreateNote() error = %v", err)
+		}
+	})
+	if got := hookLog(t, app); got != "# Next\n" {
+		t.Errorf("post-create saw %q, want the note's content", got)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package app
+
+import "os"
+
+// lockFile is a no-op on platforms without flock
+func lockFile(file *os.File) error {
+	return nil
+}
+
+// unlockFile is a no-op on platforms without flock
+func unlockFile(file *os.File) error {
+	return nil
+}
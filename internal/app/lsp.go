@@ -0,0 +1,50 @@
+package app
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/lsp"
+	"github.com/shalomb/ob-cli/internal/vault"
+)
+
+// ServeLSP runs a markdown language server over in and out. Documents are
+// served from the vault that contains them; documents under the current
+// notes directory use its frecency ranking for completion.
+func (a *App) ServeLSP(in io.Reader, out io.Writer) error {
+	server := lsp.NewServer(lsp.Options{
+		FindRoot: a.lspRoot,
+		Notes:    a.lspNotes,
+	})
+	return a.untilDone(func() error { return server.Serve(in, out) })
+}
+
+// lspRoot finds the vault of a document: the current notes directory when
+// the document is inside it, else the nearest Obsidian or git vault, else
+// the document's own folder
+func (a *App) lspRoot(path string) (string, error) {
+	if a.notesDir != "" && within(a.notesDir, path) {
+		return a.notesDir, nil
+	}
+	if root, err := vault.NewDiscoverer().FindVaultRoot(a.baseContext(), path); err == nil {
+		return root, nil
+	}
+	return filepath.Dir(path), nil
+}
+
+func (a *App) lspNotes(root string) ([]string, error) {
+	if root == a.notesDir {
+		return a.sortedFiles()
+	}
+	ctx, cancel := a.listContext()
+	defer cancel()
+	return frecency.NewService(root).GetSortedFiles(ctx)
+}
+
+// within reports whether path is dir or inside it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_lspRoot(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	other := t.TempDir()
+	os.MkdirAll(filepath.Join(other, ".obsidian"), 0755)
+	os.MkdirAll(filepath.Join(other, "daily"), 0755)
+	loose := t.TempDir()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{filepath.Join(app.notesDir, "projects", "plan.md"), app.notesDir},
+		{filepath.Join(other, "daily", "today.md"), other},
+		{filepath.Join(loose, "note.md"), loose},
+	}
+
+	for _, tt := range tests {
+		got, err := app.lspRoot(tt.path)
+		if err != nil || got != tt.expected {
+			t.Errorf("lspRoot(%s) = %q, %v, expected %q", tt.path, got, err, tt.expected)
+		}
+	}
+}
+
+func TestApp_lspNotes(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	notes, err := app.lspNotes(app.notesDir)
+	if err != nil || len(notes) != 3 || notes[0] != "projects/plan.md" {
+		t.Errorf("lspNotes(notesDir) = %v, %v", notes, err)
+	}
+}
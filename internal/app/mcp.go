@@ -0,0 +1,199 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/shalomb/ob-cli/internal/mcp"
+)
+
+// ServeMCP serves the vault as Model Context Protocol tools over in and
+// out until in is closed or the app's context is done. Notes are created
+// and written through the same vault path guard as interactive use.
+func (a *App) ServeMCP(in io.Reader, out io.Writer, version string) error {
+	server := mcp.NewServer("ob-cli", version, a.mcpTools())
+	return a.untilDone(func() error { return server.Serve(in, out) })
+}
+
+// mcpTools binds App operations to MCP tools
+func (a *App) mcpTools() []mcp.Tool {
+	return []mcp.Tool{
+		{
+			Name:        "search_notes",
+			Description: "Search note paths and contents for all words of a query (case-insensitive). Returns matching notes, most relevant first, with the first matching line.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"query": stringProperty("Words to search for"),
+				"limit": integerProperty("Maximum number of results (default 20)"),
+			}, "query"),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Query string `json:"query"`
+					Limit int    `json:"limit"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if args.Limit <= 0 {
+					args.Limit = 20
+				}
+				results, err := a.SearchNotes(args.Query, args.Limit)
+				if results == nil {
+					results = []SearchResult{}
+				}
+				return results, err
+			},
+		},
+		{
+			Name:        "read_note",
+			Description: "Read the markdown content of a note by its vault-relative path.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"path": stringProperty("Vault-relative path, e.g. projects/plan.md"),
+			}, "path"),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Path string `json:"path"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if err := checkToolPath(args.Path); err != nil {
+					return nil, err
+				}
+				return a.ReadNote(args.Path)
+			},
+		},
+		{
+			Name:        "list_recent",
+			Description: "List notes ordered by frecency: recently and frequently used notes first.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"limit": integerProperty("Maximum number of notes (default 20)"),
+			}),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Limit int `json:"limit"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if args.Limit <= 0 {
+					args.Limit = 20
+				}
+				return a.RecentNotes(args.Limit)
+			},
+		},
+		{
+			Name:        "create_note",
+			Description: "Create a new note with the given content. Fails if the note exists. Missing folders are created; \".md\" is added when the path has no extension.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"path":    stringProperty("Vault-relative path of the new note"),
+				"content": stringProperty("Markdown content"),
+			}, "path"),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Path    string `json:"path"`
+					Content string `json:"content"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if err := checkToolPath(args.Path); err != nil {
+					return nil, err
+				}
+				file, err := a.CreateNote(args.Path, args.Content)
+				if err != nil {
+					return nil, err
+				}
+				return fmt.Sprintf("Created %s", file), nil
+			},
+		},
+		{
+			Name:        "append_note",
+			Description: "Append markdown to a note, optionally at the end of the section under a heading (added when missing). The note is created when it does not exist.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"path":    stringProperty("Vault-relative path of the note"),
+				"text":    stringProperty("Markdown to append"),
+				"heading": stringProperty("Heading to append under"),
+			}, "path", "text"),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Path    string `json:"path"`
+					Text    string `json:"text"`
+					Heading string `json:"heading"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if err := checkToolPath(args.Path); err != nil {
+					return nil, err
+				}
+				file, err := a.AppendNote(args.Path, args.Text, args.Heading)
+				if err != nil {
+					return nil, err
+				}
+				return fmt.Sprintf("Appended to %s", file), nil
+			},
+		},
+		{
+			Name:        "list_backlinks",
+			Description: "List the notes linking to a note, with the line containing each link.",
+			InputSchema: objectSchema(map[string]interface{}{
+				"path": stringProperty("Vault-relative path or name of the note"),
+			}, "path"),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				var args struct {
+					Path string `json:"path"`
+				}
+				if err := decodeArgs(raw, &args); err != nil {
+					return nil, err
+				}
+				if err := checkToolPath(args.Path); err != nil {
+					return nil, err
+				}
+				backlinks, err := a.Backlinks(args.Path)
+				if backlinks == nil {
+					backlinks = []Backlink{}
+				}
+				return backlinks, err
+			},
+		},
+		{
+			Name:        "git_status",
+			Description: "Show the vault's git working tree status and how far it is behind or ahead of its remote.",
+			InputSchema: objectSchema(nil),
+			Call: func(raw json.RawMessage) (interface{}, error) {
+				status, err := a.GitStatus()
+				if err != nil || !status.Upstream {
+					return status.Changes, err // No upstream; the working tree status is still useful
+				}
+				return fmt.Sprintf("%sBehind origin: %d\nAhead of origin: %d\n", status.Changes, status.Behind, status.Ahead), nil
+			},
+		},
+	}
+}
+
+func decodeArgs(raw json.RawMessage, args interface{}) error {
+	if err := json.Unmarshal(raw, args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	if properties == nil {
+		properties = map[string]interface{}{}
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func integerProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": description}
+}
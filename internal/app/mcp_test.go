@@ -0,0 +1,72 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestApp_ServeMCP(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"read_note","arguments":{"path":"home.md"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"create_note","arguments":{"path":"../outside","content":"x"}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"list_recent","arguments":{"limit":1}}}`,
+		`{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"read_note","arguments":{"path":".obsidian/app.json"}}}`,
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"create_note","arguments":{"path":"hook.sh","content":"x"}}}`,
+	}, "\n")
+
+	var out bytes.Buffer
+	var err error
+	captureStdout(t, func() { err = app.ServeMCP(strings.NewReader(requests), &out, "test") })
+	if err != nil {
+		t.Fatalf("ServeMCP() error = %v", err)
+	}
+
+	type response struct {
+		Result struct {
+			Tools   []struct{ Name string }
+			Content []struct{ Text string }
+			IsError bool
+		}
+	}
+	var responses []response
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp response
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, resp)
+	}
+	if len(responses) != 7 {
+		t.Fatalf("got %d responses, expected 7", len(responses))
+	}
+
+	var names []string
+	for _, tool := range responses[1].Result.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "search_notes,read_note,list_recent,create_note,append_note,list_backlinks,git_status" {
+		t.Errorf("tools = %s", got)
+	}
+	if text := responses[2].Result.Content[0].Text; !strings.HasPrefix(text, "# Home") {
+		t.Errorf("read_note = %q", text)
+	}
+	if !responses[3].Result.IsError || !strings.Contains(responses[3].Result.Content[0].Text, "outside the vault") {
+		t.Errorf("create_note outside the vault = %+v", responses[3].Result)
+	}
+	if text := responses[4].Result.Content[0].Text; !strings.Contains(text, "projects/plan.md") || strings.Contains(text, "home.md") {
+		t.Errorf("list_recent = %q", text)
+	}
+	if !responses[5].Result.IsError || !strings.Contains(responses[5].Result.Content[0].Text, "hidden") {
+		t.Errorf("read_note of a hidden file = %+v", responses[5].Result)
+	}
+	if !responses[6].Result.IsError || !strings.Contains(responses[6].Result.Content[0].Text, "not a markdown note") {
+		t.Errorf("create_note of a script = %+v", responses[6].Result)
+	}
+}
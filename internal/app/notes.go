@@ -0,0 +1,355 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/index"
+	"github.com/shalomb/ob-cli/internal/markdown"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+var (
+	// ErrNoteExists is returned when creating a note that already exists
+	ErrNoteExists = errors.New("already exists")
+	// ErrOutsideVault is returned for note paths that escape the vault
+	ErrOutsideVault = errs.New(errs.ErrInvalidPath, "outside the vault")
+	// ErrHiddenPath is returned when the MCP and API tools are given a path
+	// in a hidden file or folder, such as .git or .obsidian
+	ErrHiddenPath = errs.New(errs.ErrInvalidPath, "hidden")
+	// ErrNotNote is returned when the MCP and API tools are asked to write
+	// a file that is not a markdown note
+	ErrNotNote = errs.New(errs.ErrInvalidPath, "not a markdown note")
+)
+
+// SearchResult is a note matching a search, with the first matching line
+// when the match is in the content rather than the path
+type SearchResult struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+}
+
+// Backlink is a link to a note from another note
+type Backlink struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// GitStatus is the state of the vault's git repository
+type GitStatus struct {
+	Changes  string `json:"changes"`  // Short working tree status
+	Upstream bool   `json:"upstream"` // Behind and Ahead are known
+	Behind   int    `json:"behind"`
+	Ahead    int    `json:"ahead"`
+}
+
+// RecentNotes returns up to limit notes in frecency order (all when limit
+// is zero)
+func (a *App) RecentNotes(limit int) ([]string, error) {
+	files, err := a.sortedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+	if limit > 0 && len(files) > limit {
+		files = files[:limit]
+	}
+	return files, nil
+}
+
+// SearchNotes returns notes whose path or content contains every word of
+// query, ignoring case, in frecency order
+func (a *App) SearchNotes(query string, limit int) ([]SearchResult, error) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty search query")
+	}
+
+	files, err := a.sortedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+
+	var results []SearchResult
+	for _, file := range files {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		data, err := os.ReadFile(filepath.Join(a.notesDir, file))
+		if err != nil {
+			continue
+		}
+		lowerPath := strings.ToLower(file)
+		lowerContent := strings.ToLower(string(data))
+
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(lowerPath, word) && !strings.Contains(lowerContent, word) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		result := SearchResult{File: file}
+		for i, line := range strings.Split(string(data), "\n") {
+			lower := strings.ToLower(line)
+			for _, word := range words {
+				if strings.Contains(lower, word) {
+					result.Line, result.Text = i+1, strings.TrimSpace(line)
+					break
+				}
+			}
+			if result.Line > 0 {
+				break
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Search prints the notes matching query in frecency order, as
+// "<file>:<line>\t<text>" for a match in the content and the path alone
+// for a match in the path, or as matches in a structured output format
+func (a *App) Search(out io.Writer, query string, limit int, output Output) error {
+	if strings.TrimSpace(query) == "" {
+		return errs.New(errs.ErrUsage, "empty search query")
+	}
+	results, err := a.SearchNotes(query, limit)
+	if err != nil {
+		return err
+	}
+
+	if output.Format != "" {
+		files := make([]string, len(results))
+		for i, result := range results {
+			files[i] = result.File
+		}
+		notes := a.noteInfos(files)
+		matches := make([]model.MatchInfo, len(results))
+		for i, result := range results {
+			matches[i] = model.MatchInfo{NoteInfo: notes[result.File], Line: result.Line, Text: result.Text}
+		}
+		return writeRecords(out, matches, output, matchFields)
+	}
+
+	for _, result := range results {
+		if result.Line == 0 {
+			fmt.Fprintln(out, result.File)
+			continue
+		}
+		fmt.Fprintf(out, "%s:%d\t%s\n", result.File, result.Line, result.Text)
+	}
+	return nil
+}
+
+// checkToolPath refuses the paths the MCP and API tools leave alone: those
+// with a hidden segment, where git and Obsidian keep their configuration
+func checkToolPath(file string) error {
+	for _, segment := range strings.FieldsFunc(filepath.ToSlash(file), func(r rune) bool { return r == '/' }) {
+		if strings.HasPrefix(segment, ".") && segment != "." && segment != ".." {
+			return fmt.Errorf("path %s is %w", file, ErrHiddenPath)
+		}
+	}
+	return nil
+}
+
+// ReadNote returns the content of a vault note
+func (a *App) ReadNote(file string) (string, error) {
+	fullPath, err := a.notePath(file)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return string(data), nil
+}
+
+// CreateNote creates a new note with content, refusing to overwrite an
+// existing one. Parent directories are created as for interactive notes.
+func (a *App) CreateNote(file, content string) (string, error) {
+	file, fullPath, err := a.writablePath(file)
+	if err != nil {
+		return "", err
+	}
+	if content != "" {
+		content = ensureNewline(content)
+	}
+	if err := createFile(fullPath, content); err != nil {
+		if os.IsExist(err) {
+			return "", fmt.Errorf("note %s %w", file, ErrNoteExists)
+		}
+		return "", fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	a.noteCreated(file)
+	return file, nil
+}
+
+// WriteNote replaces the content of a note, creating it when it does not
+// exist yet
+func (a *App) WriteNote(file, content string) (string, error) {
+	file, fullPath, err := a.writablePath(file)
+	if err != nil {
+		return "", err
+	}
+	created, err := ensureNote(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	err = updateLocked(fullPath, func(string) string { return content })
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	if created {
+		a.noteCreated(file)
+	}
+	return file, nil
+}
+
+// AppendNote appends text to a note, under heading when given, creating
+// the note when it does not exist yet
+func (a *App) AppendNote(file, text, heading string) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("nothing to append")
+	}
+	file, fullPath, err := a.writablePath(file)
+	if err != nil {
+		return "", err
+	}
+	created, err := ensureNote(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", file, err)
+	}
+	if err := appendLocked(fullPath, heading, ensureNewline(text)); err != nil {
+		return "", fmt.Errorf("failed to append to %s: %w", file, err)
+	}
+	if created {
+		a.noteCreated(file)
+	}
+	return file, nil
+}
+
+// writablePath resolves a note the MCP and API tools may write: a
+// markdown note, given with or without its extension. It returns the note
+// with its extension and its full path.
+func (a *App) writablePath(file string) (string, string, error) {
+	file = withNoteExt(file)
+	if filepath.Ext(file) != ".md" {
+		return "", "", fmt.Errorf("path %s is %w", file, ErrNotNote)
+	}
+	fullPath, err := a.notePath(file)
+	if err != nil {
+		return "", "", err
+	}
+	return file, fullPath, nil
+}
+
+// ensureNote creates a missing note for the MCP and API tools. Unlike
+// prepareFile it records no frecency access and prints nothing, as the
+// writes don't come from the user. It returns whether the note was
+// created.
+func ensureNote(fullPath string) (bool, error) {
+	err := createFile(fullPath, "")
+	if os.IsExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Backlinks returns the links to a note from other notes, resolved the way
+// Obsidian resolves them
+func (a *App) Backlinks(file string) ([]Backlink, error) {
+	file = withNoteExt(file)
+	if _, err := a.notePath(file); err != nil {
+		return nil, err
+	}
+
+	files, err := a.sortedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+	resolver := markdown.NewResolver(files)
+	target, ok := resolver.Resolve("", file)
+	if !ok {
+		return nil, errs.New(errs.ErrInvalidPath, "note %s not found", file)
+	}
+
+	links := a.noteLinks(files)
+	var backlinks []Backlink
+	for _, source := range files {
+		if source == target {
+			continue
+		}
+		for _, link := range links[source] {
+			if resolved, ok := resolver.Resolve(source, link.Target); ok && resolved == target {
+				backlinks = append(backlinks, Backlink{File: source, Line: link.Line, Text: link.Text})
+			}
+		}
+	}
+	return backlinks, nil
+}
+
+// noteLinks returns the links of each note, from the daemon's index when
+// it is running, else by reading the notes
+func (a *App) noteLinks(files []string) map[string][]index.Link {
+	links := make(map[string][]index.Link, len(files))
+	if a.index != nil {
+		ctx, cancel := a.listContext()
+		defer cancel()
+		if notes, err := a.index.Notes(ctx); err == nil {
+			for _, note := range notes {
+				links[note.File] = note.Links
+			}
+			return links
+		}
+	}
+
+	for _, file := range files {
+		if note, err := index.ReadNote(a.notesDir, file); err == nil {
+			links[file] = note.Links
+		}
+	}
+	return links
+}
+
+// GitStatus returns the working tree status and, when the branch has an
+// upstream, how far it is behind or ahead
+func (a *App) GitStatus() (GitStatus, error) {
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	changes, err := a.gitService.GetStatus(ctx)
+	if err != nil {
+		return GitStatus{}, fmt.Errorf("failed to get git status: %w", err)
+	}
+	status := GitStatus{Changes: changes}
+	if behind, ahead, err := a.gitService.GetSyncStatus(ctx); err == nil {
+		status.Upstream, status.Behind, status.Ahead = true, behind, ahead
+	}
+	return status, nil
+}
+
+// withNoteExt adds the markdown extension to paths without one
+func withNoteExt(file string) string {
+	if filepath.Ext(file) == "" {
+		return file + ".md"
+	}
+	return file
+}
+
+func ensureNewline(s string) string {
+	if strings.HasSuffix(s, "\n") {
+		return s
+	}
+	return s + "\n"
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func newNotesTestApp(t *testing.T) (*App, *frecency.MockService) {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	notes := []struct{ name, content string }{
+		{"projects/plan.md", "# Plan\nShip the release\nSee [[home]]\n"},
+		{"home.md", "# Home\n[[plan]] and [again](projects/plan.md)\n"},
+		{"ideas.md", "Release notes idea\n"},
+	}
+	var files []string
+	for _, note := range notes {
+		path := filepath.Join(tempDir, note.name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(note.content), 0644)
+		files = append(files, note.name)
+	}
+
+	frecencyService := frecency.NewMockService(files, nil)
+	return &App{
+		config:     &Config{Mode: "tips"},
+		gitService: git.NewMockService(nil, "", 0, 0, nil),
+		editor:     editor.NewMockService([]string{}, 0, nil),
+		opener:     editor.NewMockOpener(nil),
+		fzf:        fzf.NewMockService("", true, nil),
+		frecency:   frecencyService,
+		notesDir:   tempDir,
+		mode:       "tips",
+	}, frecencyService.(*frecency.MockService)
+}
+
+func TestApp_SearchNotes(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	tests := []struct {
+		query    string
+		limit    int
+		expected []SearchResult
+	}{
+		{"release", 0, []SearchResult{{File: "projects/plan.md", Line: 2, Text: "Ship the release"}, {File: "ideas.md", Line: 1, Text: "Release notes idea"}}},
+		{"release", 1, []SearchResult{{File: "projects/plan.md", Line: 2, Text: "Ship the release"}}},
+		{"projects ship", 0, []SearchResult{{File: "projects/plan.md", Line: 2, Text: "Ship the release"}}},
+		{"nothing", 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := app.SearchNotes(tt.query, tt.limit)
+			if err != nil {
+				t.Fatalf("SearchNotes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SearchNotes(%q) = %+v, expected %+v", tt.query, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := app.SearchNotes("  ", 0); err == nil {
+		t.Error("SearchNotes() expected error for empty query")
+	}
+}
+
+func TestApp_Search(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	tests := []struct {
+		query  string
+		output string
+		want   string
+	}{
+		{"release", "", "projects/plan.md:2\tShip the release\nideas.md:1\tRelease notes idea\n"},
+		{"projects", "", "projects/plan.md\nhome.md:2\t[[plan]] and [again](projects/plan.md)\n"},
+		{"release", "tsv", "projects/plan.md\t2\tShip the release\nideas.md\t1\tRelease notes idea\n"},
+		{"ship", "template={{.Title}}: {{.Text}} ({{.Score}})", "Plan: Ship the release (3)\n"},
+		{"nothing", "json", "[]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query+" "+tt.output, func(t *testing.T) {
+			output, err := ParseOutput(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			if err := app.Search(&out, tt.query, 0, output); err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("Search(%q) = %q, want %q", tt.query, out.String(), tt.want)
+			}
+		})
+	}
+
+	if err := app.Search(io.Discard, " ", 0, Output{}); !errors.Is(err, errs.ErrUsage) {
+		t.Errorf("Search() of an empty query error = %v", err)
+	}
+}
+
+func TestApp_CreateAndAppendNote(t *testing.T) {
+	app, frecencyService := newNotesTestApp(t)
+
+	var file string
+	var err error
+	stderr := captureStderr(t, func() { file, err = app.CreateNote("new/idea", "# Idea") })
+	if err != nil || file != "new/idea.md" {
+		t.Fatalf("CreateNote() = %q, %v", file, err)
+	}
+	if stderr != "" {
+		t.Errorf("CreateNote() printed %q", stderr)
+	}
+	if _, err := app.CreateNote("new/idea.md", "again"); !errors.Is(err, ErrNoteExists) {
+		t.Errorf("CreateNote() error = %v, expected ErrNoteExists for existing note", err)
+	}
+	if _, err := app.CreateNote("../escape.md", ""); !errors.Is(err, ErrOutsideVault) {
+		t.Errorf("CreateNote() error = %v, expected ErrOutsideVault", err)
+	}
+	for _, file := range []string{"hook.sh", "board.canvas", "notes.md.txt"} {
+		if _, err := app.CreateNote(file, "x"); !errors.Is(err, ErrNotNote) {
+			t.Errorf("CreateNote(%s) error = %v, expected ErrNotNote", file, err)
+		}
+		if _, err := app.WriteNote(file, "x"); !errors.Is(err, ErrNotNote) {
+			t.Errorf("WriteNote(%s) error = %v, expected ErrNotNote", file, err)
+		}
+		if _, err := app.AppendNote(file, "x", ""); !errors.Is(err, ErrNotNote) {
+			t.Errorf("AppendNote(%s) error = %v, expected ErrNotNote", file, err)
+		}
+	}
+
+	if _, err := app.AppendNote("new/idea", "- first", "Log"); err != nil {
+		t.Fatalf("AppendNote() error = %v", err)
+	}
+	content, err := app.ReadNote("new/idea.md")
+	if err != nil {
+		t.Fatalf("ReadNote() error = %v", err)
+	}
+	if expected := "# Idea\n\n## Log\n- first\n"; content != expected {
+		t.Errorf("note content = %q, expected %q", content, expected)
+	}
+	// Writes from the tools aren't the user's, so they don't count as recent
+	if len(frecencyService.Accessed) != 0 {
+		t.Errorf("recorded accesses = %q", frecencyService.Accessed)
+	}
+
+	if _, err := app.ReadNote("/etc/passwd"); err == nil {
+		t.Error("ReadNote() expected error for absolute path")
+	}
+}
+
+func TestApp_WriteNote(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	for _, content := range []string{"First version\nwith two lines\n", "Second\n"} {
+		file, err := app.WriteNote("drafts/post", content)
+		if err != nil || file != "drafts/post.md" {
+			t.Fatalf("WriteNote() = %q, %v", file, err)
+		}
+		if got, _ := app.ReadNote(file); got != content {
+			t.Errorf("note content = %q, expected %q", got, content)
+		}
+	}
+}
+
+func TestApp_Backlinks(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+
+	backlinks, err := app.Backlinks("projects/plan.md")
+	if err != nil {
+		t.Fatalf("Backlinks() error = %v", err)
+	}
+	if len(backlinks) != 2 || backlinks[0].File != "home.md" || backlinks[0].Line != 2 || !strings.Contains(backlinks[0].Text, "[[plan]]") {
+		t.Errorf("Backlinks() = %+v", backlinks)
+	}
+
+	if _, err := app.Backlinks("missing"); err == nil {
+		t.Error("Backlinks() expected error for missing note")
+	}
+}
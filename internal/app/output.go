@@ -0,0 +1,320 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/canvas"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/filetype"
+	"github.com/shalomb/ob-cli/internal/markdown"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+// Output formats for listings
+const (
+	OutputJSON     = "json"
+	OutputJSONL    = "jsonl"
+	OutputTSV      = "tsv"
+	OutputTemplate = "template"
+)
+
+// Output selects how listings are written. The zero value is the plain
+// text each command prints by default.
+type Output struct {
+	Format   string
+	Template *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// ParseOutput parses an --output value: json, jsonl, tsv or template=TEXT,
+// where TEXT is a Go template executed for each note
+func ParseOutput(value string) (Output, error) {
+	switch value {
+	case "":
+		return Output{}, nil
+	case OutputJSON, OutputJSONL, OutputTSV:
+		return Output{Format: value}, nil
+	}
+
+	text, ok := strings.CutPrefix(value, OutputTemplate+"=")
+	if !ok {
+		return Output{}, errs.New(errs.ErrUsage, "invalid output %q, expected json, jsonl, tsv or template=TEXT", value)
+	}
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return Output{}, errs.New(errs.ErrUsage, "invalid output template: %w", err)
+	}
+	return Output{Format: OutputTemplate, Template: tmpl}, nil
+}
+
+// writeNotes writes notes in a structured output format
+func writeNotes(w io.Writer, notes []model.NoteInfo, output Output) error {
+	return writeRecords(w, notes, output, noteFields)
+}
+
+// writeRecords writes listing records, notes or types embedding a note, in
+// a structured output format. fields gives a record's TSV columns.
+func writeRecords[T any](w io.Writer, records []T, output Output, fields func(T) []string) error {
+	switch output.Format {
+	case OutputJSON:
+		if records == nil {
+			records = []T{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case OutputJSONL:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+	case OutputTSV:
+		for _, record := range records {
+			columns := fields(record)
+			for i, column := range columns {
+				columns[i] = tsvEscaper.Replace(column)
+			}
+			if _, err := fmt.Fprintln(w, strings.Join(columns, "\t")); err != nil {
+				return err
+			}
+		}
+	case OutputTemplate:
+		for _, record := range records {
+			if err := output.Template.Execute(w, record); err != nil {
+				return fmt.Errorf("failed to execute output template: %w", err)
+			}
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown output format %q", output.Format)
+	}
+	return nil
+}
+
+// noteFields are a note's TSV columns
+func noteFields(note model.NoteInfo) []string {
+	return []string{
+		note.Path,
+		note.Title,
+		tsvTime(note.Modified),
+		strconv.FormatFloat(note.Score, 'f', -1, 64),
+		strings.Join(note.Tags, ","),
+		note.Git,
+		note.Type,
+		note.Summary,
+	}
+}
+
+// taskFields are a task's TSV columns
+func taskFields(task model.TaskInfo) []string {
+	return []string{
+		task.Path,
+		tsvLine(task.Line),
+		task.Status,
+		task.Text,
+		task.Due,
+		task.Scheduled,
+		task.Start,
+		task.Completed,
+		task.Priority,
+		strings.Join(task.Tags, ","),
+	}
+}
+
+// agendaFields are an agenda item's TSV columns; notes show their title
+// where tasks show their text
+func agendaFields(item model.AgendaItem) []string {
+	text := item.Text
+	if item.Kind == model.AgendaNote {
+		text = item.Title
+	}
+	return []string{item.Day, item.Kind, item.Path, tsvLine(item.Line), text, item.Priority}
+}
+
+// matchFields are a search match's TSV columns
+func matchFields(match model.MatchInfo) []string {
+	return []string{match.Path, tsvLine(match.Line), match.Text}
+}
+
+// tsvEscaper keeps each note on one line with a fixed number of fields
+var tsvEscaper = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+
+func tsvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func tsvLine(line int) string {
+	if line == 0 {
+		return ""
+	}
+	return strconv.Itoa(line)
+}
+
+// isoDate formats a task date, or returns "" when it is unset
+func isoDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(markdown.DateLayout)
+}
+
+// noteInfos describes vault files for listings of what they contain, such
+// as tasks: their frecency score, modification time, title, tags, type and
+// git state, by path
+func (a *App) noteInfos(files []string) map[string]model.NoteInfo {
+	wanted := map[string]bool{}
+	for _, file := range files {
+		wanted[file] = true
+	}
+	var notes []model.NoteInfo
+	if scored, err := a.scoredFiles(); err == nil {
+		for _, note := range scored {
+			if wanted[note.Path] {
+				notes = append(notes, note)
+				delete(wanted, note.Path)
+			}
+		}
+	}
+	for file := range wanted {
+		note := model.NoteInfo{Path: file}
+		if info, err := os.Stat(filepath.Join(a.notesDir, file)); err == nil {
+			note.Modified = info.ModTime()
+		}
+		notes = append(notes, note)
+	}
+	a.describeNotes(notes, a.gitStates())
+
+	byPath := make(map[string]model.NoteInfo, len(notes))
+	for _, note := range notes {
+		byPath[note.Path] = note
+	}
+	return byPath
+}
+
+// describeNotes fills in each note's type, its title and tags from its
+// content, a canvas's summary, and the git state from states when the vault
+// is a git repository. Other files are titled by their name.
+func (a *App) describeNotes(notes []model.NoteInfo, states map[string]string) {
+	for i := range notes {
+		note := &notes[i]
+		note.Type = filetype.Of(note.Path)
+		switch note.Type {
+		case filetype.Markdown, filetype.Excalidraw:
+			if content, err := os.ReadFile(filepath.Join(a.notesDir, note.Path)); err == nil {
+				note.Title = markdown.Title(note.Path, string(content))
+				if note.Tags == nil {
+					note.Tags = markdown.NoteTags(string(content))
+				}
+			}
+		case filetype.Canvas:
+			note.Title = markdown.Title(note.Path, "")
+			note.Summary = a.canvasSummary(note.Path)
+		default:
+			note.Title = markdown.Title(note.Path, "")
+		}
+		if states != nil && note.Git == "" {
+			note.Git = gitState(states, note.Path)
+		}
+	}
+}
+
+// canvasSummary describes a canvas's cards and links in one line, or
+// returns "" if it can't be read
+func (a *App) canvasSummary(file string) string {
+	data, err := os.ReadFile(filepath.Join(a.notesDir, file))
+	if err != nil {
+		return ""
+	}
+	c, err := canvas.Parse(data)
+	if err != nil {
+		return ""
+	}
+	return c.Summary()
+}
+
+// gitStates returns the git state of each changed path in the vault, or
+// nil if the vault is not in a git repository
+func (a *App) gitStates() map[string]string {
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	status, err := a.gitService.GetStatus(ctx)
+	if err != nil {
+		return nil
+	}
+	states := map[string]string{}
+	for _, entry := range parseStatus(status) {
+		states[entry.Path] = entry.Git
+	}
+	return states
+}
+
+// gitState looks up path in states; untracked directories are listed with
+// a trailing slash and cover everything beneath them
+func gitState(states map[string]string, path string) string {
+	path = filepath.ToSlash(path)
+	if state, ok := states[path]; ok {
+		return state
+	}
+	for dir, state := range states {
+		if strings.HasSuffix(dir, "/") && strings.HasPrefix(path, dir) {
+			return state
+		}
+	}
+	return model.GitClean
+}
+
+// parseStatus parses `git status --porcelain` output into notes with only
+// their path and git state set
+func parseStatus(status string) []model.NoteInfo {
+	var notes []model.NoteInfo
+	for _, line := range strings.Split(status, "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		code, path := line[:2], line[3:]
+		if _, to, ok := strings.Cut(path, " -> "); ok {
+			path = to
+		}
+		if unquoted, err := strconv.Unquote(path); err == nil {
+			path = unquoted
+		}
+		notes = append(notes, model.NoteInfo{Path: path, Git: statusState(code)})
+	}
+	return notes
+}
+
+// statusState maps a porcelain status code to a git state
+func statusState(code string) string {
+	switch {
+	case code == "??":
+		return model.GitUntracked
+	case strings.Contains(code, "U") || code == "AA" || code == "DD":
+		return model.GitConflicted
+	case strings.Contains(code, "R"):
+		return model.GitRenamed
+	case strings.Contains(code, "D"):
+		return model.GitDeleted
+	case strings.ContainsAny(code, "AC"):
+		return model.GitAdded
+	default:
+		return model.GitModified
+	}
+}
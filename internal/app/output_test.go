@@ -0,0 +1,151 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/git"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		value  string
+		format string
+		err    error
+	}{
+		{"", "", nil},
+		{"json", OutputJSON, nil},
+		{"jsonl", OutputJSONL, nil},
+		{"tsv", OutputTSV, nil},
+		{"template={{.Path}} {{.Score}}", OutputTemplate, nil},
+		{"yaml", "", errs.ErrUsage},
+		{"template={{.Path", "", errs.ErrUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			output, err := ParseOutput(tt.value)
+			if !errors.Is(err, tt.err) || (err == nil) != (tt.err == nil) {
+				t.Fatalf("ParseOutput(%q) error = %v, want %v", tt.value, err, tt.err)
+			}
+			if output.Format != tt.format {
+				t.Errorf("ParseOutput(%q) format = %q, want %q", tt.value, output.Format, tt.format)
+			}
+		})
+	}
+}
+
+func TestWriteNotes(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	notes := []model.NoteInfo{
+		{Path: "plan.md", Title: "The\tPlan", Modified: modified, Score: 2.5, Tags: []string{"work", "q1"}, Git: model.GitModified},
+		{Path: "ideas.md", Score: 1, Git: model.GitClean},
+		{Path: "board.canvas", Score: 0.5, Type: "canvas", Summary: "2 cards, 1 link"},
+	}
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"jsonl", `{"path":"plan.md","title":"The\tPlan","mtime":"2024-03-01T09:30:00Z","score":2.5,"tags":["work","q1"],"git":"modified"}
+{"path":"ideas.md","mtime":"0001-01-01T00:00:00Z","score":1,"git":"clean"}
+{"path":"board.canvas","mtime":"0001-01-01T00:00:00Z","score":0.5,"type":"canvas","summary":"2 cards, 1 link"}
+`},
+		{"tsv", "plan.md\tThe Plan\t2024-03-01T09:30:00Z\t2.5\twork,q1\tmodified\t\t\nideas.md\t\t\t1\t\tclean\t\t\nboard.canvas\t\t\t0.5\t\t\tcanvas\t2 cards, 1 link\n"},
+		{`template={{.Path}} {{.Score}} {{join .Tags "+"}}`, "plan.md 2.5 work+q1\nideas.md 1 \nboard.canvas 0.5 \n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			output, err := ParseOutput(tt.output)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writeNotes(&buf, notes, output); err != nil {
+				t.Fatalf("writeNotes() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("writeNotes() =\n%q\nwant\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	writeNotes(&buf, nil, Output{Format: OutputJSON})
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("writeNotes(nil, json) = %q, want []", buf.String())
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	status := " M plan.md\nA  new.md\nAM staged.md\n D gone.md\nR  old.md -> moved.md\nUU both.md\n?? drafts/\n?? \"with space.md\"\n"
+	want := []model.NoteInfo{
+		{Path: "plan.md", Git: model.GitModified},
+		{Path: "new.md", Git: model.GitAdded},
+		{Path: "staged.md", Git: model.GitAdded},
+		{Path: "gone.md", Git: model.GitDeleted},
+		{Path: "moved.md", Git: model.GitRenamed},
+		{Path: "both.md", Git: model.GitConflicted},
+		{Path: "drafts/", Git: model.GitUntracked},
+		{Path: "with space.md", Git: model.GitUntracked},
+	}
+	if got := parseStatus(status); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseStatus() = %+v, want %+v", got, want)
+	}
+}
+
+func TestApp_ListFiles_JSON(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	app.gitService = git.NewMockService(nil, " M home.md\n?? projects/\n", 0, 0, nil)
+
+	out := captureStdout(t, func() {
+		if err := app.ListFiles(Output{Format: OutputJSON}); err != nil {
+			t.Fatalf("ListFiles() error = %v", err)
+		}
+	})
+
+	var notes []model.NoteInfo
+	if err := json.Unmarshal([]byte(out), &notes); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	got := map[string]model.NoteInfo{}
+	for _, note := range notes {
+		got[note.Path] = note
+	}
+	if len(got) != 3 {
+		t.Fatalf("ListFiles() listed %d notes, want 3", len(got))
+	}
+	checks := []struct{ path, title, git string }{
+		{"projects/plan.md", "Plan", model.GitUntracked},
+		{"home.md", "Home", model.GitModified},
+		{"ideas.md", "ideas", model.GitClean},
+	}
+	for _, c := range checks {
+		if note := got[c.path]; note.Title != c.title || note.Git != c.git {
+			t.Errorf("%s: title %q git %q, want %q %q", c.path, note.Title, note.Git, c.title, c.git)
+		}
+	}
+}
+
+func TestApp_ShowGitStatus_Template(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	app.gitService = git.NewMockService(nil, " M home.md\n D gone.md\n", 0, 0, nil)
+	output, _ := ParseOutput("template={{.Git}} {{.Path}} {{.Title}}")
+
+	out := captureStdout(t, func() {
+		if err := app.ShowGitStatus(output); err != nil {
+			t.Fatalf("ShowGitStatus() error = %v", err)
+		}
+	})
+	if want := "modified home.md Home\ndeleted gone.md \n"; out != want {
+		t.Errorf("ShowGitStatus() = %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"github.com/shalomb/ob-cli/internal/plugin"
+)
+
+// PluginContext describes the vault to external plugins
+func (a *App) PluginContext() plugin.Context {
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	root, err := a.gitService.Root(ctx)
+	if err != nil {
+		a.logger().Debug("vault is not in git", "error", err)
+	}
+	return plugin.Context{
+		Vault:   a.notesDir,
+		Mode:    a.mode,
+		GitRoot: root,
+		Editor:  editorProfile(a.config, a.mode).ResolvedCommand(),
+	}
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func TestApp_PluginContext(t *testing.T) {
+	t.Setenv("TIPS_EDITOR", "hx")
+	app, _ := newNotesTestApp(t)
+
+	c := app.PluginContext()
+	if c.Vault != app.notesDir || c.Mode != "tips" || c.GitRoot != "" || c.Editor != "hx" {
+		t.Errorf("PluginContext() = %+v", c)
+	}
+
+	app.gitService.(*git.MockService).RootDir = app.notesDir
+	if c := app.PluginContext(); c.GitRoot != app.notesDir {
+		t.Errorf("PluginContext().GitRoot = %q, want %q", c.GitRoot, app.notesDir)
+	}
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/api"
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// Serve serves the vault's REST API on l until it fails or the app's
+// context is done. When token is
+// set, clients must send it as a bearer token.
+func (a *App) Serve(l net.Listener, token string) error {
+	server := &http.Server{Handler: a.APIHandler(token), ReadHeaderTimeout: 10 * time.Second}
+	stop := context.AfterFunc(a.baseContext(), func() { server.Close() })
+	defer stop()
+	if err := server.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// APIHandler returns the REST API, bound to the same operations as the
+// CLI and the MCP server
+func (a *App) APIHandler(token string) http.Handler {
+	return api.NewHandler([]api.Route{
+		{Method: http.MethodGet, Path: "/api/files", Handle: a.apiFiles},
+		{Method: http.MethodGet, Path: "/api/search", Handle: a.apiSearch},
+		{Method: http.MethodPost, Path: "/api/notes", Status: http.StatusCreated, Handle: a.apiCreateNote},
+		{Method: http.MethodGet, Path: "/api/notes/", Handle: a.apiReadNote},
+		{Method: http.MethodPut, Path: "/api/notes/", Handle: a.apiWriteNote},
+		{Method: http.MethodGet, Path: "/api/status", Handle: a.apiStatus},
+		{Method: http.MethodPost, Path: "/api/sync", Handle: a.apiSync},
+	}, token)
+}
+
+func (a *App) apiFiles(r *http.Request) (interface{}, error) {
+	limit, err := limitParam(r)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := a.scoredFiles()
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(notes) > limit {
+		notes = notes[:limit]
+	}
+	a.describeNotes(notes, a.gitStates())
+	return notes, nil
+}
+
+func (a *App) apiSearch(r *http.Request) (interface{}, error) {
+	limit, err := limitParam(r)
+	if err != nil {
+		return nil, err
+	}
+	query := r.URL.Query().Get("q")
+	if strings.TrimSpace(query) == "" {
+		return nil, api.Errorf(http.StatusBadRequest, "missing query parameter q")
+	}
+	results, err := a.SearchNotes(query, limit)
+	if results == nil {
+		results = []SearchResult{}
+	}
+	return results, err
+}
+
+// note is a note's path and content in API requests and responses
+type note struct {
+	File     string `json:"file"`
+	Content  string `json:"content,omitempty"`
+	Template string `json:"template,omitempty"` // Create from this template instead of content
+}
+
+func (a *App) apiReadNote(r *http.Request) (interface{}, error) {
+	file := strings.TrimPrefix(r.URL.Path, "/api/notes/")
+	if err := checkToolPath(file); err != nil {
+		return nil, apiError(err)
+	}
+	content, err := a.ReadNote(file)
+	if err != nil {
+		return nil, apiError(err)
+	}
+	return note{File: file, Content: content}, nil
+}
+
+func (a *App) apiWriteNote(r *http.Request) (interface{}, error) {
+	var body note
+	if err := api.DecodeJSON(r, &body); err != nil {
+		return nil, err
+	}
+	file := strings.TrimPrefix(r.URL.Path, "/api/notes/")
+	if err := checkToolPath(file); err != nil {
+		return nil, apiError(err)
+	}
+	file, err := a.WriteNote(file, body.Content)
+	if err != nil {
+		return nil, apiError(err)
+	}
+	return note{File: file}, nil
+}
+
+func (a *App) apiCreateNote(r *http.Request) (interface{}, error) {
+	var body note
+	if err := api.DecodeJSON(r, &body); err != nil {
+		return nil, err
+	}
+	if body.File == "" {
+		return nil, api.Errorf(http.StatusBadRequest, "missing file")
+	}
+	if err := checkToolPath(body.File); err != nil {
+		return nil, apiError(err)
+	}
+
+	var file string
+	var err error
+	if body.Template != "" {
+		file, err = a.CreateFromTemplate(body.File, body.Template, time.Now())
+	} else {
+		file, err = a.CreateNote(body.File, body.Content)
+	}
+	if err != nil {
+		return nil, apiError(err)
+	}
+	return note{File: file}, nil
+}
+
+func (a *App) apiStatus(r *http.Request) (interface{}, error) {
+	return a.GitStatus()
+}
+
+func (a *App) apiSync(r *http.Request) (interface{}, error) {
+	if err := a.SyncWithRemote(); err != nil {
+		return nil, apiError(err)
+	}
+	return a.GitStatus()
+}
+
+// apiError maps App errors to HTTP statuses
+func apiError(err error) error {
+	switch {
+	case errors.Is(err, ErrOutsideVault), errors.Is(err, ErrHiddenPath), errors.Is(err, ErrNotNote):
+		return &api.Error{Status: http.StatusBadRequest, Err: err}
+	case errors.Is(err, ErrNoteExists), errors.Is(err, errs.ErrGitConflict):
+		return &api.Error{Status: http.StatusConflict, Err: err}
+	case errors.Is(err, fs.ErrNotExist):
+		return &api.Error{Status: http.StatusNotFound, Err: err}
+	}
+	return err
+}
+
+func limitParam(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return 0, api.Errorf(http.StatusBadRequest, "invalid limit %q", value)
+	}
+	return limit, nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_APIHandler(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	os.MkdirAll(filepath.Join(app.notesDir, "templates"), 0755)
+	os.WriteFile(filepath.Join(app.notesDir, "templates", "meeting.md"), []byte("# {{title}}\n"), 0644)
+	handler := app.APIHandler("secret")
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		contains           string
+	}{
+		{"GET", "/api/files?limit=2", "", 200, `[{"path":"projects/plan.md","title":"Plan",`},
+		{"GET", "/api/files?limit=x", "", 400, `invalid limit`},
+		{"GET", "/api/search?q=release&limit=1", "", 200, `[{"file":"projects/plan.md","line":2,"text":"Ship the release"}]`},
+		{"GET", "/api/search", "", 400, `missing query parameter q`},
+		{"GET", "/api/notes/home.md", "", 200, `{"file":"home.md","content":"# Home\n`},
+		{"GET", "/api/notes/missing.md", "", 404, `failed to read missing.md`},
+		{"GET", "/api/notes/../secret.md", "", 400, `outside the vault`},
+		{"GET", "/api/notes/.git/config", "", 400, `path .git/config is hidden`},
+		{"PUT", "/api/notes/.obsidian/app", `{"content":"{}"}`, 400, `is hidden`},
+		{"PUT", "/api/notes/scripts/x.sh", `{"content":"rm -rf ~"}`, 400, `path scripts/x.sh is not a markdown note`},
+		{"PUT", "/api/notes/ideas", `{"content":"Rewritten\n"}`, 200, `{"file":"ideas.md"}`},
+		{"POST", "/api/notes", `{"file":"home","content":"x"}`, 409, `note home.md already exists`},
+		{"POST", "/api/notes", `{"file":"standup","template":"meeting"}`, 201, `{"file":"standup.md"}`},
+		{"GET", "/api/status", "", 200, `"upstream":true`},
+		{"DELETE", "/api/notes/home.md", "", 405, `method DELETE not allowed`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			req.URL.Path, req.URL.RawQuery, _ = strings.Cut(tt.path, "?")
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			captureStdout(t, func() { handler.ServeHTTP(rec, req) })
+
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, expected %d (%s)", rec.Code, tt.status, rec.Body)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body = %s, expected it to contain %s", rec.Body, tt.contains)
+			}
+		})
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(app.notesDir, "ideas.md")); string(data) != "Rewritten\n" {
+		t.Errorf("ideas.md = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(app.notesDir, "standup.md")); string(data) != "# standup\n" {
+		t.Errorf("standup.md = %q", data)
+	}
+}
@@ -0,0 +1,337 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/markdown"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+// TaskOptions filters and orders the tasks listed by ListTasks
+type TaskOptions struct {
+	Status string    // open (default), done or all
+	Due    string    // today, overdue, week, none or a YYYY-MM-DD date
+	Tag    string    // Only tasks with this tag (or a nested child tag)
+	Path   string    // Only tasks in notes under this vault-relative path
+	Sort   string    // file (default), due or priority
+	Open   bool      // Pick a task with fzf and open its note at the task line
+	Output Output    // Structured output format instead of task lines
+	Today  time.Time // Reference date for due filters (default: today)
+}
+
+// ListTasks prints the checkbox tasks found across the vault, one per line
+// as "<file>:<line>\t[<status>] <description> <metadata>", or as tasks in
+// a structured output format
+func (a *App) ListTasks(opts TaskOptions) error {
+	if opts.Open && opts.Output.Format != "" {
+		return errs.New(errs.ErrUsage, "--open and --output cannot be combined")
+	}
+	if opts.Today.IsZero() {
+		opts.Today = time.Now()
+	}
+	opts.Today = startOfDay(opts.Today)
+
+	tasks, err := a.collectTasks()
+	if err != nil {
+		return err
+	}
+
+	tasks, err = filterTasks(tasks, opts)
+	if err != nil {
+		return err
+	}
+	if err := sortTasks(tasks, opts.Sort); err != nil {
+		return err
+	}
+
+	if opts.Output.Format != "" {
+		return writeRecords(os.Stdout, a.taskInfos(tasks), opts.Output, taskFields)
+	}
+
+	lines := make([]string, len(tasks))
+	for i, task := range tasks {
+		lines[i] = formatTask(task)
+	}
+
+	if !opts.Open {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		return nil
+	}
+
+	selection, err := a.fzf.SelectFile(a.baseContext(), lines, "")
+	if err != nil {
+		return fmt.Errorf("fzf selection failed: %w", err)
+	}
+	// fzf prints the typed query when nothing matched it; only a listed
+	// task is opened
+	for i, line := range lines {
+		if line != selection {
+			continue
+		}
+		file := tasks[i].File
+		if err := a.recordAccess(file); err != nil {
+			a.logger().Debug("failed to record access", "file", file, "error", err)
+		}
+		return a.openInEditor(file, tasks[i].Line)
+	}
+	return nil // User cancelled
+}
+
+// ToggleTask checks off (or reopens) the task with the given
+// "<file>:<line>" ID in place
+func (a *App) ToggleTask(id string, today time.Time) error {
+	file, line, err := parseTaskID(id)
+	if err != nil {
+		return err
+	}
+	fullPath, err := a.notePath(file)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(fullPath, os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", file, err)
+	}
+	defer unlockFile(f)
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	if line < 1 || line > len(lines) {
+		return fmt.Errorf("no task at %s: file has %d lines", id, len(lines))
+	}
+	toggled, ok := markdown.ToggleTask(lines[line-1], today)
+	if !ok {
+		return fmt.Errorf("no task at %s", id)
+	}
+	lines[line-1] = toggled
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strings.Join(lines, "\n")); err != nil {
+		return err
+	}
+
+	task, _ := markdown.ParseTask(toggled)
+	task.File, task.Line = file, line
+	fmt.Println(formatTask(task))
+	return nil
+}
+
+// collectTasks parses the tasks of every note in the vault
+func (a *App) collectTasks() ([]markdown.Task, error) {
+	files, err := a.sortedFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file list: %w", err)
+	}
+
+	var tasks []markdown.Task
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(a.notesDir, file))
+		if err != nil {
+			continue // Skip notes that vanished or cannot be read
+		}
+		tasks = append(tasks, markdown.ParseTasks(file, string(data))...)
+	}
+	return tasks, nil
+}
+
+// filterTasks keeps the tasks matching the status, due, tag and path filters
+func filterTasks(tasks []markdown.Task, opts TaskOptions) ([]markdown.Task, error) {
+	dueMatch, err := dueFilter(opts.Due, opts.Today)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.ToSlash(filepath.Clean(opts.Path))
+	var result []markdown.Task
+	for _, task := range tasks {
+		switch opts.Status {
+		case "", "open":
+			if !task.Open() {
+				continue
+			}
+		case "done":
+			if task.Open() {
+				continue
+			}
+		case "all":
+		default:
+			return nil, errs.New(errs.ErrUsage, "invalid status %q: use open, done or all", opts.Status)
+		}
+
+		if !dueMatch(task) {
+			continue
+		}
+		if opts.Tag != "" && !markdown.HasTag(task.Tags, opts.Tag) {
+			continue
+		}
+		if opts.Path != "" && path != "." {
+			file := filepath.ToSlash(task.File)
+			if file != path && !strings.HasPrefix(file, path+"/") {
+				continue
+			}
+		}
+		result = append(result, task)
+	}
+	return result, nil
+}
+
+// dueFilter turns a due filter specification into a predicate
+func dueFilter(spec string, today time.Time) (func(markdown.Task) bool, error) {
+	switch spec {
+	case "":
+		return func(markdown.Task) bool { return true }, nil
+	case "none":
+		return func(t markdown.Task) bool { return t.Due.IsZero() }, nil
+	case "today":
+		return func(t markdown.Task) bool { return t.Due.Equal(today) }, nil
+	case "overdue":
+		return func(t markdown.Task) bool { return !t.Due.IsZero() && t.Due.Before(today) }, nil
+	case "week":
+		end := today.AddDate(0, 0, 7)
+		return func(t markdown.Task) bool { return !t.Due.IsZero() && !t.Due.Before(today) && t.Due.Before(end) }, nil
+	}
+
+	date, err := time.ParseInLocation(markdown.DateLayout, spec, time.Local)
+	if err != nil {
+		return nil, errs.New(errs.ErrUsage, "invalid due filter %q: use today, overdue, week, none or YYYY-MM-DD", spec)
+	}
+	return func(t markdown.Task) bool { return t.Due.Equal(date) }, nil
+}
+
+// sortTasks orders tasks in place. Tasks without a due date sort last.
+func sortTasks(tasks []markdown.Task, by string) error {
+	byFile := func(i, j int) bool {
+		if tasks[i].File != tasks[j].File {
+			return tasks[i].File < tasks[j].File
+		}
+		return tasks[i].Line < tasks[j].Line
+	}
+
+	switch by {
+	case "", "file":
+		sort.SliceStable(tasks, byFile)
+	case "due":
+		sort.SliceStable(tasks, func(i, j int) bool {
+			di, dj := tasks[i].Due, tasks[j].Due
+			if di.Equal(dj) {
+				return byFile(i, j)
+			}
+			if di.IsZero() || dj.IsZero() {
+				return dj.IsZero()
+			}
+			return di.Before(dj)
+		})
+	case "priority":
+		sort.SliceStable(tasks, func(i, j int) bool {
+			if tasks[i].Priority != tasks[j].Priority {
+				return tasks[i].Priority > tasks[j].Priority
+			}
+			return byFile(i, j)
+		})
+	default:
+		return errs.New(errs.ErrUsage, "invalid sort %q: use file, due or priority", by)
+	}
+	return nil
+}
+
+// taskInfos describes tasks for a structured output format
+func (a *App) taskInfos(tasks []markdown.Task) []model.TaskInfo {
+	files := make([]string, len(tasks))
+	for i, task := range tasks {
+		files[i] = task.File
+	}
+	notes := a.noteInfos(files)
+
+	infos := make([]model.TaskInfo, len(tasks))
+	for i, task := range tasks {
+		infos[i] = taskInfo(notes[task.File], task)
+	}
+	return infos
+}
+
+// taskInfo describes a task in the note described by note
+func taskInfo(note model.NoteInfo, task markdown.Task) model.TaskInfo {
+	info := model.TaskInfo{
+		NoteInfo:  note,
+		Line:      task.Line,
+		Status:    string(task.Status),
+		Text:      task.Description,
+		Due:       isoDate(task.Due),
+		Scheduled: isoDate(task.Scheduled),
+		Start:     isoDate(task.Start),
+		Completed: isoDate(task.Completed),
+		Tags:      task.Tags,
+	}
+	if task.Priority != markdown.PriorityNone {
+		info.Priority = task.Priority.String()
+	}
+	return info
+}
+
+// formatTask renders a task as "<id>\t[<status>] <description> <metadata>"
+func formatTask(task markdown.Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\t[%c] %s", task.ID(), task.Status, task.Description)
+
+	dates := []struct {
+		emoji string
+		date  time.Time
+	}{
+		{"📅", task.Due},
+		{"⏳", task.Scheduled},
+		{"🛫", task.Start},
+		{"✅", task.Completed},
+	}
+	for _, d := range dates {
+		if !d.date.IsZero() {
+			fmt.Fprintf(&b, " %s %s", d.emoji, d.date.Format(markdown.DateLayout))
+		}
+	}
+	if task.Priority != markdown.PriorityNone {
+		fmt.Fprintf(&b, " [%s]", task.Priority)
+	}
+	return b.String()
+}
+
+// parseTaskID splits a "<file>:<line>" task ID
+func parseTaskID(id string) (string, int, error) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, errs.New(errs.ErrUsage, "invalid task id %q: expected <file>:<line>", id)
+	}
+	line, err := strconv.Atoi(id[i+1:])
+	if err != nil || line < 1 {
+		return "", 0, errs.New(errs.ErrUsage, "invalid task id %q: expected <file>:<line>", id)
+	}
+	return id[:i], line, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
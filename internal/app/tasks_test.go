@@ -0,0 +1,184 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/editor"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/fzf"
+	"github.com/shalomb/ob-cli/internal/git"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+func newTasksTestApp(t *testing.T, selection string) (*App, *editor.MockService) {
+	t.Helper()
+	tempDir := t.TempDir()
+
+	notes := map[string]string{
+		"work/plan.md": "# Plan\n- [ ] ship release ⏫ 📅 2024-03-05 #work\n- [x] write spec ✅ 2024-03-01 #work\n- [ ] review 📅 2024-03-01\n",
+		"home.md":      "- [ ] water plants 🔽 #home\n- [ ] taxes 🔺 📅 2024-03-09\n",
+	}
+	var files []string
+	for name, content := range notes {
+		path := filepath.Join(tempDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+		files = append(files, name)
+	}
+
+	editorService := editor.NewMockService([]string{}, 0, nil)
+	return &App{
+		config:     &Config{Mode: "tips"},
+		gitService: git.NewMockService(nil, "", 0, 0, nil),
+		editor:     editorService,
+		fzf:        fzf.NewMockService(selection, selection == "", nil),
+		frecency:   frecency.NewMockService(files, nil),
+		notesDir:   tempDir,
+		mode:       "tips",
+	}, editorService.(*editor.MockService)
+}
+
+func taskIDs(output string) []string {
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line != "" {
+			ids = append(ids, strings.SplitN(line, "\t", 2)[0])
+		}
+	}
+	return ids
+}
+
+func TestApp_ListTasks(t *testing.T) {
+	today := time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		opts     TaskOptions
+		expected []string
+		wantErr  bool
+	}{
+		{"open tasks by file", TaskOptions{}, []string{"home.md:1", "home.md:2", "work/plan.md:2", "work/plan.md:4"}, false},
+		{"done tasks", TaskOptions{Status: "done"}, []string{"work/plan.md:3"}, false},
+		{"due today", TaskOptions{Due: "today"}, []string{"work/plan.md:2"}, false},
+		{"overdue", TaskOptions{Due: "overdue"}, []string{"work/plan.md:4"}, false},
+		{"due this week by due date", TaskOptions{Due: "week", Sort: "due"}, []string{"work/plan.md:2", "home.md:2"}, false},
+		{"tag filter", TaskOptions{Tag: "#work", Status: "all"}, []string{"work/plan.md:2", "work/plan.md:3"}, false},
+		{"path filter", TaskOptions{Path: "work/"}, []string{"work/plan.md:2", "work/plan.md:4"}, false},
+		{"by priority", TaskOptions{Sort: "priority"}, []string{"home.md:2", "work/plan.md:2", "work/plan.md:4", "home.md:1"}, false},
+		{"invalid due", TaskOptions{Due: "someday"}, nil, true},
+		{"invalid sort", TaskOptions{Sort: "size"}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newTasksTestApp(t, "")
+			tt.opts.Today = today
+
+			var err error
+			output := captureStdout(t, func() { err = app.ListTasks(tt.opts) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListTasks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			ids := taskIDs(output)
+			if strings.Join(ids, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected tasks %v, got %v", tt.expected, ids)
+			}
+		})
+	}
+}
+
+func TestApp_ListTasks_Open(t *testing.T) {
+	app, mockEditor := newTasksTestApp(t, "work/plan.md:4\t[ ] review 📅 2024-03-01")
+
+	captureStdout(t, func() {
+		if err := app.ListTasks(TaskOptions{Open: true}); err != nil {
+			t.Errorf("ListTasks() error = %v", err)
+		}
+	})
+
+	if len(mockEditor.OpenedFiles) != 1 || mockEditor.OpenedFiles[0] != filepath.Join(app.notesDir, "work", "plan.md") {
+		t.Errorf("Expected work/plan.md to be opened, got %v", mockEditor.OpenedFiles)
+	}
+	if len(mockEditor.OpenedLines) != 1 || mockEditor.OpenedLines[0] != 4 {
+		t.Errorf("Expected line 4 to be opened, got %v", mockEditor.OpenedLines)
+	}
+
+	// A query fzf printed without a match opens nothing
+	app, mockEditor = newTasksTestApp(t, "plan.md:99")
+	captureStdout(t, func() {
+		if err := app.ListTasks(TaskOptions{Open: true}); err != nil {
+			t.Errorf("ListTasks() error = %v", err)
+		}
+	})
+	if len(mockEditor.OpenedFiles) != 0 {
+		t.Errorf("Expected nothing to be opened, got %v", mockEditor.OpenedFiles)
+	}
+}
+
+func TestApp_ToggleTask(t *testing.T) {
+	app, _ := newTasksTestApp(t, "")
+	today := time.Date(2024, 3, 6, 0, 0, 0, 0, time.Local)
+
+	captureStdout(t, func() {
+		if err := app.ToggleTask("work/plan.md:2", today); err != nil {
+			t.Fatalf("ToggleTask() error = %v", err)
+		}
+		if err := app.ToggleTask("work/plan.md:3", today); err != nil {
+			t.Fatalf("ToggleTask() error = %v", err)
+		}
+	})
+
+	data, _ := os.ReadFile(filepath.Join(app.notesDir, "work", "plan.md"))
+	expected := "# Plan\n- [x] ship release ⏫ 📅 2024-03-05 #work ✅ 2024-03-06\n- [ ] write spec #work\n- [ ] review 📅 2024-03-01\n"
+	if string(data) != expected {
+		t.Errorf("Expected note content %q, got %q", expected, string(data))
+	}
+
+	for _, id := range []string{"work/plan.md:1", "work/plan.md:99", "work/plan.md", "../x.md:1"} {
+		if err := app.ToggleTask(id, today); err == nil {
+			t.Errorf("Expected error toggling %q, got nil", id)
+		}
+	}
+}
+
+func TestApp_ListTasks_Output(t *testing.T) {
+	app, _ := newTasksTestApp(t, "")
+	today := time.Date(2024, 3, 5, 10, 0, 0, 0, time.Local)
+
+	output, _ := ParseOutput(OutputJSON)
+	var err error
+	out := captureStdout(t, func() { err = app.ListTasks(TaskOptions{Due: "today", Output: output, Today: today}) })
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	var tasks []model.TaskInfo
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("ListTasks() listed %+v", tasks)
+	}
+	task := tasks[0]
+	if task.Path != "work/plan.md" || task.Title != "Plan" || task.Line != 2 || task.Status != " " || task.Text != "ship release #work" ||
+		task.Due != "2024-03-05" || task.Priority != "high" || len(task.Tags) != 1 {
+		t.Errorf("task = %+v", task)
+	}
+
+	output, _ = ParseOutput("template={{.Path}}:{{.Line}} {{.Text}}")
+	out = captureStdout(t, func() { err = app.ListTasks(TaskOptions{Due: "overdue", Output: output, Today: today}) })
+	if err != nil || out != "work/plan.md:4 review\n" {
+		t.Errorf("ListTasks() with a template = %q, %v", out, err)
+	}
+
+	if err := app.ListTasks(TaskOptions{Open: true, Output: output}); !errors.Is(err, errs.ErrUsage) {
+		t.Errorf("ListTasks() with --open and --output error = %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// templateVar matches Obsidian template variables: {{title}}, {{date}},
+// {{time}}, and {{date:FORMAT}} or {{time:FORMAT}} with a moment.js format
+var templateVar = regexp.MustCompile(`{{\s*(title|date|time)\s*(?::([^}]*))?}}`)
+
+// templateSettings follows Obsidian's core templates plugin configuration
+type templateSettings struct {
+	Folder     string `json:"folder"`
+	DateFormat string `json:"dateFormat"`
+	TimeFormat string `json:"timeFormat"`
+}
+
+// CreateFromTemplate creates a new note from a note in the templates
+// folder (".obsidian/templates.json", default "templates"), filling in
+// the template variables for now
+func (a *App) CreateFromTemplate(file, template string, now time.Time) (string, error) {
+	settings := a.templateSettings()
+	templatePath, err := a.notePath(filepath.Join(settings.Folder, withNoteExt(template)))
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", template, err)
+	}
+
+	title := strings.TrimSuffix(filepath.Base(withNoteExt(file)), ".md")
+	return a.CreateNote(file, renderTemplate(string(data), title, now, settings))
+}
+
+// templateSettings reads the vault's template settings, with defaults
+func (a *App) templateSettings() templateSettings {
+	settings := templateSettings{}
+	if data, err := os.ReadFile(filepath.Join(a.notesDir, ".obsidian", "templates.json")); err == nil {
+		json.Unmarshal(data, &settings) // Fall back to defaults on bad JSON
+	}
+	if settings.Folder == "" {
+		settings.Folder = "templates"
+	}
+	if settings.DateFormat == "" {
+		settings.DateFormat = "YYYY-MM-DD"
+	}
+	if settings.TimeFormat == "" {
+		settings.TimeFormat = "HH:mm"
+	}
+	return settings
+}
+
+// renderTemplate replaces the template variables in content
+func renderTemplate(content, title string, now time.Time, settings templateSettings) string {
+	return templateVar.ReplaceAllStringFunc(content, func(match string) string {
+		m := templateVar.FindStringSubmatch(match)
+		format := strings.TrimSpace(m[2])
+		switch m[1] {
+		case "title":
+			return title
+		case "date":
+			if format == "" {
+				format = settings.DateFormat
+			}
+		case "time":
+			if format == "" {
+				format = settings.TimeFormat
+			}
+		}
+		return formatMoment(format, now)
+	})
+}
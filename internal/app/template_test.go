@@ -0,0 +1,55 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 5, 9, 7, 0, 0, time.UTC)
+	settings := templateSettings{DateFormat: "YYYY-MM-DD", TimeFormat: "HH:mm"}
+
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"# {{title}}", "# Standup"},
+		{"{{date}} {{time}}", "2024-03-05 09:07"},
+		{"{{ date:DD/MM/YYYY }}", "05/03/2024"},
+		{"{{time:HH}}h", "09h"},
+		{"{{unknown}}", "{{unknown}}"},
+	}
+
+	for _, tt := range tests {
+		if got := renderTemplate(tt.content, "Standup", now, settings); got != tt.expected {
+			t.Errorf("renderTemplate(%q) = %q, expected %q", tt.content, got, tt.expected)
+		}
+	}
+}
+
+func TestApp_CreateFromTemplate(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	now := time.Date(2024, 3, 5, 9, 7, 0, 0, time.UTC)
+
+	os.MkdirAll(filepath.Join(app.notesDir, ".obsidian"), 0755)
+	os.WriteFile(filepath.Join(app.notesDir, ".obsidian", "templates.json"), []byte(`{"folder":"meta/tpl","dateFormat":"D MMM YYYY"}`), 0644)
+	os.MkdirAll(filepath.Join(app.notesDir, "meta", "tpl"), 0755)
+	os.WriteFile(filepath.Join(app.notesDir, "meta", "tpl", "meeting.md"), []byte("# {{title}}\nHeld {{date}}\n"), 0644)
+
+	var file string
+	var err error
+	captureStdout(t, func() { file, err = app.CreateFromTemplate("meetings/standup", "meeting", now) })
+	if err != nil || file != "meetings/standup.md" {
+		t.Fatalf("CreateFromTemplate() = %q, %v", file, err)
+	}
+	data, _ := os.ReadFile(filepath.Join(app.notesDir, "meetings", "standup.md"))
+	if string(data) != "# standup\nHeld 5 Mar 2024\n" {
+		t.Errorf("content = %q", data)
+	}
+
+	if _, err := app.CreateFromTemplate("other", "missing", now); err == nil {
+		t.Error("CreateFromTemplate() with a missing template succeeded")
+	}
+}
@@ -0,0 +1,216 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/markdown"
+	"github.com/shalomb/ob-cli/internal/trash"
+)
+
+// defaultArchive is the archive folder when none is configured
+const defaultArchive = "archive"
+
+// MoveOptions controls removing and archiving notes
+type MoveOptions struct {
+	Git bool // Stage the change in git when the note is tracked
+}
+
+// RemoveNote deletes a note as Obsidian would, following the vault's
+// trashOption: into the system trash, into .trash or permanently
+func (a *App) RemoveNote(file string, opts MoveOptions) error {
+	file, err := a.existingNote(file)
+	if err != nil {
+		return err
+	}
+	a.warnInboundLinks(file, "")
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	tracked := opts.Git && a.gitService.IsTracked(ctx, file)
+
+	applied, err := trash.New(a.notesDir).Delete(file, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", file, err)
+	}
+	switch applied {
+	case trash.OptionSystem:
+		fmt.Fprintf(os.Stderr, "Moved %s to the system trash\n", file)
+	case trash.OptionLocal:
+		fmt.Fprintf(os.Stderr, "Moved %s to %s/\n", file, trash.LocalDir)
+	default:
+		fmt.Fprintf(os.Stderr, "Deleted %s\n", file)
+	}
+
+	if tracked {
+		return a.gitService.StageFiles(ctx, file)
+	}
+	return nil
+}
+
+// ArchiveNote moves a note into the archive folder (vaults.<mode>.archive,
+// default "archive"), keeping its path below the vault root
+func (a *App) ArchiveNote(file string, opts MoveOptions) error {
+	file, err := a.existingNote(file)
+	if err != nil {
+		return err
+	}
+	archive := filepath.Clean(a.archivePath())
+	if file == archive || strings.HasPrefix(file, archive+string(filepath.Separator)) {
+		return fmt.Errorf("%s is already archived", file)
+	}
+
+	dest := filepath.Join(archive, file)
+	destPath, err := a.notePath(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("note %s %w", dest, ErrNoteExists)
+	}
+	a.warnInboundLinks(file, dest)
+	ctx, cancel := a.gitContext()
+	defer cancel()
+	tracked := opts.Git && a.gitService.IsTracked(ctx, file)
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+	}
+	if err := os.Rename(filepath.Join(a.notesDir, file), destPath); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", file, err)
+	}
+	fmt.Fprintf(os.Stderr, "Archived %s to %s\n", file, dest)
+
+	if tracked {
+		return a.gitService.StageFiles(ctx, file, dest)
+	}
+	return nil
+}
+
+// ListTrash prints the trashed notes of the vault, most recent first
+func (a *App) ListTrash(out io.Writer) error {
+	items, err := trash.New(a.notesDir).List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash: %w", err)
+	}
+	for _, item := range items {
+		where := trash.LocalDir
+		if item.System {
+			where = "system"
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", item.Deleted.Format("2006-01-02 15:04"), where, item.Name, item.Path)
+	}
+	return nil
+}
+
+// RestoreTrash restores the most recently trashed note with the given
+// trash name or original path
+func (a *App) RestoreTrash(name string, opts MoveOptions) error {
+	t := trash.New(a.notesDir)
+	items, err := t.List()
+	if err != nil {
+		return fmt.Errorf("failed to list trash: %w", err)
+	}
+	for _, item := range items {
+		if item.Name != name && item.Path != name && item.Path != withNoteExt(name) {
+			continue
+		}
+		if _, err := a.notePath(item.Path); err != nil {
+			return err
+		}
+		if err := t.Restore(item); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", item.Name, err)
+		}
+		fmt.Fprintf(os.Stderr, "Restored %s\n", item.Path)
+		if opts.Git {
+			ctx, cancel := a.gitContext()
+			defer cancel()
+			return a.gitService.StageFiles(ctx, item.Path)
+		}
+		return nil
+	}
+	return fmt.Errorf("%s is not in the trash", name)
+}
+
+// EmptyTrash permanently deletes the vault's trashed notes
+func (a *App) EmptyTrash() error {
+	n, err := trash.New(a.notesDir).Empty()
+	if err != nil {
+		return fmt.Errorf("failed to empty trash: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Deleted %d trashed files\n", n)
+	return nil
+}
+
+// existingNote resolves a vault file to remove or archive, adding the
+// markdown extension when only the note name is given
+func (a *App) existingNote(file string) (string, error) {
+	for _, candidate := range []string{file, withNoteExt(file)} {
+		fullPath, err := a.notePath(candidate)
+		if err != nil {
+			return "", err
+		}
+		if info, err := os.Stat(fullPath); err == nil && !info.IsDir() {
+			return filepath.Clean(candidate), nil
+		}
+	}
+	return "", errs.New(errs.ErrInvalidPath, "note %s not found", file)
+}
+
+// archivePath returns the configured archive folder for the current vault
+func (a *App) archivePath() string {
+	if archive := a.config.Vaults[a.mode].Archive; archive != "" {
+		return archive
+	}
+	return defaultArchive
+}
+
+// warnInboundLinks warns about links to a note that will no longer
+// resolve to it once it is removed, or moved to the path moved
+func (a *App) warnInboundLinks(file, moved string) {
+	if filepath.Ext(file) != ".md" {
+		return
+	}
+	files, err := a.sortedFiles()
+	if err != nil {
+		return
+	}
+	var after []string
+	for _, f := range files {
+		if f != file {
+			after = append(after, f)
+		}
+	}
+	if moved != "" {
+		after = append(after, moved)
+	}
+	before, afterResolver := markdown.NewResolver(files), markdown.NewResolver(after)
+
+	var broken []string
+	links := a.noteLinks(files)
+	for _, source := range files {
+		if source == file {
+			continue
+		}
+		for _, link := range links[source] {
+			if target, ok := before.Resolve(source, link.Target); !ok || target != file {
+				continue
+			}
+			if target, ok := afterResolver.Resolve(source, link.Target); ok && moved != "" && target == moved {
+				continue
+			}
+			broken = append(broken, fmt.Sprintf("%s:%d: %s", source, link.Line, link.Text))
+		}
+	}
+
+	if len(broken) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d links to %s will break:\n", len(broken), file)
+		for _, b := range broken {
+			fmt.Fprintf(os.Stderr, "  %s\n", b)
+		}
+	}
+}
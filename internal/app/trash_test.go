@@ -0,0 +1,85 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/git"
+)
+
+func TestApp_RemoveAndRestoreNote(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	os.MkdirAll(filepath.Join(app.notesDir, ".obsidian"), 0755)
+	os.WriteFile(filepath.Join(app.notesDir, ".obsidian", "app.json"), []byte(`{"trashOption":"local"}`), 0644)
+	gitService := app.gitService.(*git.MockService)
+	gitService.TrackedFiles = []string{"projects/plan.md"}
+
+	var err error
+	stderr := captureStderr(t, func() { err = app.RemoveNote("projects/plan", MoveOptions{Git: true}) })
+	if err != nil {
+		t.Fatalf("RemoveNote() error = %v", err)
+	}
+	if !strings.Contains(stderr, "Warning: 2 links to projects/plan.md will break:\n  home.md:2: [[plan]] and [again](projects/plan.md)\n") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(app.notesDir, ".trash", "plan.md")); err != nil {
+		t.Errorf("note not in .trash: %v", err)
+	}
+	if !reflect.DeepEqual(gitService.StagedFiles, []string{"projects/plan.md"}) {
+		t.Errorf("staged files = %v", gitService.StagedFiles)
+	}
+
+	var out bytes.Buffer
+	if err := app.ListTrash(&out); err != nil || !strings.HasSuffix(out.String(), "\t.trash\tplan.md\tprojects/plan.md\n") {
+		t.Errorf("ListTrash() = %q, %v", out.String(), err)
+	}
+
+	captureStderr(t, func() { err = app.RestoreTrash("projects/plan", MoveOptions{}) })
+	if err != nil {
+		t.Fatalf("RestoreTrash() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(app.notesDir, "projects", "plan.md")); err != nil {
+		t.Errorf("note not restored: %v", err)
+	}
+	if err := app.RestoreTrash("plan.md", MoveOptions{}); err == nil {
+		t.Error("RestoreTrash() of a note not in the trash succeeded")
+	}
+
+	if err := app.RemoveNote("missing", MoveOptions{}); err == nil {
+		t.Error("RemoveNote() of a missing note succeeded")
+	}
+	if err := app.RemoveNote("../outside.md", MoveOptions{}); err == nil {
+		t.Error("RemoveNote() outside the vault succeeded")
+	}
+}
+
+func TestApp_ArchiveNote(t *testing.T) {
+	app, _ := newNotesTestApp(t)
+	app.config.Vaults = map[string]VaultConfig{"tips": {Archive: "old"}}
+	gitService := app.gitService.(*git.MockService)
+	gitService.TrackedFiles = []string{"projects/plan.md"}
+
+	var err error
+	stderr := captureStderr(t, func() { err = app.ArchiveNote("projects/plan.md", MoveOptions{Git: true}) })
+	if err != nil {
+		t.Fatalf("ArchiveNote() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(app.notesDir, "old", "projects", "plan.md")); err != nil {
+		t.Errorf("note not archived: %v", err)
+	}
+	// [[plan]] and projects/plan.md still resolve by name and path suffix
+	if strings.Contains(stderr, "Warning") {
+		t.Errorf("stderr = %q", stderr)
+	}
+	if !reflect.DeepEqual(gitService.StagedFiles, []string{"projects/plan.md", filepath.Join("old", "projects", "plan.md")}) {
+		t.Errorf("staged files = %v", gitService.StagedFiles)
+	}
+
+	if err := app.ArchiveNote("old/projects/plan.md", MoveOptions{}); err == nil {
+		t.Error("ArchiveNote() of an archived note succeeded")
+	}
+}
@@ -0,0 +1,136 @@
+// Package canvas reads Obsidian canvas files (JSON Canvas) and describes
+// them as text: their cards and the links between them.
+package canvas
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Card types
+const (
+	TextCard  = "text"
+	FileCard  = "file"
+	LinkCard  = "link"
+	GroupCard = "group"
+)
+
+// Empty is the content of a new canvas without cards or links
+const Empty = `{"nodes":[],"edges":[]}`
+
+// Canvas is a parsed .canvas file
+type Canvas struct {
+	Cards []Card `json:"nodes"`
+	Links []Link `json:"edges"`
+}
+
+// Card is a node of the canvas
+type Card struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Text  string `json:"text,omitempty"`  // Text cards: markdown
+	File  string `json:"file,omitempty"`  // File cards: vault-relative path
+	URL   string `json:"url,omitempty"`   // Link cards
+	Label string `json:"label,omitempty"` // Groups
+}
+
+// Link is an edge between two cards
+type Link struct {
+	ID    string `json:"id"`
+	From  string `json:"fromNode"`
+	To    string `json:"toNode"`
+	Label string `json:"label,omitempty"`
+}
+
+// Parse parses a canvas file's content
+func Parse(data []byte) (*Canvas, error) {
+	var c Canvas
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid canvas: %w", err)
+	}
+	return &c, nil
+}
+
+// Name describes a card in one line: the first line of its text, its
+// file, its URL or its group label
+func (c Card) Name() string {
+	var name string
+	switch c.Type {
+	case TextCard:
+		name, _, _ = strings.Cut(strings.TrimSpace(c.Text), "\n")
+		name = strings.TrimSpace(strings.TrimLeft(name, "# "))
+	case FileCard:
+		name = c.File
+	case LinkCard:
+		name = c.URL
+	case GroupCard:
+		name = c.Label
+	}
+	if name == "" {
+		return "(" + c.Type + " " + c.ID + ")"
+	}
+	if runes := []rune(name); len(runes) > 60 {
+		name = string(runes[:59]) + "…"
+	}
+	return name
+}
+
+// Summary describes the canvas in one line, e.g. "5 cards, 1 group, 4 links"
+func (c *Canvas) Summary() string {
+	cards, groups := 0, 0
+	for _, card := range c.Cards {
+		if card.Type == GroupCard {
+			groups++
+		} else {
+			cards++
+		}
+	}
+	parts := []string{plural(cards, "card")}
+	if groups > 0 {
+		parts = append(parts, plural(groups, "group"))
+	}
+	return strings.Join(append(parts, plural(len(c.Links), "link")), ", ")
+}
+
+// Text describes the canvas for reading in a terminal: its summary, each
+// card by type and each link between named cards
+func (c *Canvas) Text() string {
+	var b strings.Builder
+	b.WriteString(c.Summary() + "\n")
+
+	names := map[string]string{}
+	if len(c.Cards) > 0 {
+		b.WriteString("\nCards:\n")
+	}
+	for _, card := range c.Cards {
+		names[card.ID] = card.Name()
+		fmt.Fprintf(&b, "  [%s] %s\n", card.Type, card.Name())
+	}
+
+	if len(c.Links) > 0 {
+		b.WriteString("\nLinks:\n")
+	}
+	for _, link := range c.Links {
+		from, to := names[link.From], names[link.To]
+		if from == "" {
+			from = "(missing " + link.From + ")"
+		}
+		if to == "" {
+			to = "(missing " + link.To + ")"
+		}
+		fmt.Fprintf(&b, "  %s → %s", from, to)
+		if link.Label != "" {
+			fmt.Fprintf(&b, " (%s)", link.Label)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
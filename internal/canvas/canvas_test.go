@@ -0,0 +1,66 @@
+package canvas
+
+import "testing"
+
+const board = `{
+	"nodes": [
+		{"id": "a", "type": "text", "text": "# Idea\nDetails", "x": 0, "y": 0, "width": 200, "height": 100},
+		{"id": "b", "type": "file", "file": "projects/plan.md"},
+		{"id": "c", "type": "link", "url": "https://example.com"},
+		{"id": "g", "type": "group", "label": "Q3"},
+		{"id": "e", "type": "text", "text": ""}
+	],
+	"edges": [
+		{"id": "1", "fromNode": "a", "toNode": "b", "label": "becomes"},
+		{"id": "2", "fromNode": "b", "toNode": "c"},
+		{"id": "3", "fromNode": "c", "toNode": "gone"}
+	]
+}`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(board))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := c.Summary(), "4 cards, 1 group, 3 links"; got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	want := `4 cards, 1 group, 3 links
+
+Cards:
+  [text] Idea
+  [file] projects/plan.md
+  [link] https://example.com
+  [group] Q3
+  [text] (text e)
+
+Links:
+  Idea → projects/plan.md (becomes)
+  projects/plan.md → https://example.com
+  https://example.com → (missing gone)
+`
+	if got := c.Text(); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+
+	if _, err := Parse([]byte("not json")); err == nil {
+		t.Error("Parse() of invalid JSON succeeded")
+	}
+}
+
+func TestCanvas_Summary(t *testing.T) {
+	tests := []struct {
+		canvas Canvas
+		want   string
+	}{
+		{Canvas{}, "0 cards, 0 links"},
+		{Canvas{Cards: []Card{{Type: TextCard}}, Links: []Link{{}}}, "1 card, 1 link"},
+		{Canvas{Cards: []Card{{Type: GroupCard}, {Type: GroupCard}}}, "0 cards, 2 groups, 0 links"},
+	}
+	for _, tt := range tests {
+		if got := tt.canvas.Summary(); got != tt.want {
+			t.Errorf("Summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
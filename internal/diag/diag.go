@@ -0,0 +1,114 @@
+// Package diag carries a structured logger and an optional trace through
+// contexts, so every service call can report what it runs and how long it
+// takes without services holding any diagnostics state.
+package diag
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	tracerKey
+)
+
+// WithLogger returns a copy of ctx that carries logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// Logger returns the logger ctx carries, or one that discards everything
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+		return logger
+	}
+	return discard
+}
+
+var discard = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// Span times an operation. Ending it logs the duration at debug level and
+// adds the operation to ctx's trace, if any.
+type Span struct {
+	ctx   context.Context
+	name  string
+	start time.Time
+	attrs []any
+}
+
+// Start begins timing an operation. attrs are alternating keys and values.
+func Start(ctx context.Context, name string, attrs ...any) *Span {
+	return &Span{ctx: ctx, name: name, start: time.Now(), attrs: attrs}
+}
+
+// End stops timing, adding attrs to those given to Start. Pairs with a nil
+// value, such as a nil error, are left out.
+func (s *Span) End(attrs ...any) {
+	duration := time.Since(s.start)
+	all := append(s.attrs[:len(s.attrs):len(s.attrs)], attrs...)
+	attrs = nil
+	for i := 0; i < len(all); i += 2 {
+		if i+1 < len(all) && all[i+1] == nil {
+			continue
+		}
+		attrs = append(attrs, all[i:min(i+2, len(all))]...)
+	}
+	attrs = append(attrs, "duration", duration)
+	Logger(s.ctx).Debug(s.name, attrs...)
+	if t := tracerFrom(s.ctx); t != nil {
+		t.add(s.name, s.start, duration, attrs)
+	}
+}
+
+// Run runs cmd like cmd.Run, logging and tracing it
+func Run(ctx context.Context, cmd *exec.Cmd) error {
+	span := startCommand(ctx, cmd)
+	err := cmd.Run()
+	endCommand(span, cmd, err)
+	return err
+}
+
+// Output runs cmd like cmd.Output, logging and tracing it
+func Output(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	span := startCommand(ctx, cmd)
+	output, err := cmd.Output()
+	endCommand(span, cmd, err)
+	return output, err
+}
+
+// startCommand names a command's span after the program and its first
+// argument, such as "exec git status"
+func startCommand(ctx context.Context, cmd *exec.Cmd) *Span {
+	name := []string{"exec", filepath.Base(cmd.Path)}
+	if len(cmd.Args) > 1 && !strings.HasPrefix(cmd.Args[1], "-") {
+		name = append(name, cmd.Args[1])
+	}
+	return Start(ctx, strings.Join(name, " "), "args", cmd.Args[1:])
+}
+
+func endCommand(span *Span, cmd *exec.Cmd, err error) {
+	exit := -1 // Never started
+	if cmd.ProcessState != nil {
+		exit = cmd.ProcessState.ExitCode()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		err = nil // Reported as the exit code
+	}
+	span.End("exit", exit, "error", err)
+}
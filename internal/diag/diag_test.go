@@ -0,0 +1,90 @@
+package diag
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func newTestLogger() (context.Context, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return WithLogger(context.Background(), logger), &buf
+}
+
+func TestLogger_Default(t *testing.T) {
+	logger := Logger(context.Background())
+	if logger == nil {
+		t.Fatal("Logger() = nil")
+	}
+	if logger.Enabled(context.Background(), slog.LevelError) {
+		t.Error("default logger is enabled")
+	}
+}
+
+func TestSpan_End(t *testing.T) {
+	ctx, buf := newTestLogger()
+	span := Start(ctx, "vault walk", "dir", "/notes")
+	span.End("files", 3)
+
+	line := buf.String()
+	for _, want := range []string{"level=DEBUG", `msg="vault walk"`, "dir=/notes", "files=3", "duration="} {
+		if !strings.Contains(line, want) {
+			t.Errorf("log %q does not contain %q", line, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		wantLogs []string
+	}{
+		{"success", []string{"-c", "true"}, false, []string{`msg="exec sh"`, "args=\"[-c true]\"", "exit=0"}},
+		{"exit code", []string{"-c", "exit 3"}, true, []string{"exit=3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, buf := newTestLogger()
+			err := Run(ctx, exec.Command("sh", tt.args...))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Run() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, want := range tt.wantLogs {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("log %q does not contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestOutput_NotFound(t *testing.T) {
+	ctx, buf := newTestLogger()
+	if _, err := Output(ctx, exec.Command("ob-cli-no-such-command")); err == nil {
+		t.Fatal("Output() error = nil")
+	}
+	if log := buf.String(); !strings.Contains(log, "exit=-1") || !strings.Contains(log, "error=") {
+		t.Errorf("log %q lacks exit=-1 and the error", log)
+	}
+}
+
+func TestSpan_End_NilValues(t *testing.T) {
+	ctx, buf := newTestLogger()
+	var err error
+	Start(ctx, "fetch").End("remote", "origin", "error", err)
+
+	if log := buf.String(); strings.Contains(log, "error") || !strings.Contains(log, "remote=origin") {
+		t.Errorf("log %q should have the remote and no error", log)
+	}
+}
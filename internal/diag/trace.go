@@ -0,0 +1,150 @@
+package diag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tracer records spans in the Chrome trace event format, which
+// chrome://tracing and https://ui.perfetto.dev display as a timeline
+type Tracer struct {
+	mu     sync.Mutex
+	start  time.Time
+	events []event
+}
+
+type event struct {
+	name     string
+	start    time.Time
+	duration time.Duration
+	args     map[string]any
+}
+
+// NewTracer creates a tracer whose timeline starts now
+func NewTracer() *Tracer {
+	return &Tracer{start: time.Now()}
+}
+
+// WithTracer returns a copy of ctx whose spans are recorded by t
+func WithTracer(ctx context.Context, t *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey, t)
+}
+
+func tracerFrom(ctx context.Context) *Tracer {
+	t, _ := ctx.Value(tracerKey).(*Tracer)
+	return t
+}
+
+func (t *Tracer) add(name string, start time.Time, duration time.Duration, attrs []any) {
+	args := make(map[string]any)
+	record := slog.NewRecord(start, slog.LevelDebug, name, 0)
+	record.Add(attrs...)
+	record.Attrs(func(attr slog.Attr) bool {
+		args[attr.Key] = traceValue(attr.Value.Resolve())
+		return true
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events = append(t.events, event{name: name, start: start, duration: duration, args: args})
+}
+
+// traceValue converts a logged value to one that encodes readably as JSON
+func traceValue(v slog.Value) any {
+	switch v.Kind() {
+	case slog.KindDuration:
+		return v.Duration().String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return err.Error()
+		}
+	}
+	return v.Any()
+}
+
+// traceEvent is a complete event ("ph": "X") in the Chrome trace format;
+// timestamps are in microseconds
+type traceEvent struct {
+	Name     string         `json:"name"`
+	Phase    string         `json:"ph"`
+	Time     float64        `json:"ts"`
+	Duration float64        `json:"dur"`
+	Process  int            `json:"pid"`
+	Thread   int            `json:"tid"`
+	Args     map[string]any `json:"args,omitempty"`
+}
+
+// Write writes the recorded spans as a Chrome trace. Overlapping spans
+// that do not nest, such as a background fetch, go on separate tracks.
+func (t *Tracer) Write(w io.Writer) error {
+	t.mu.Lock()
+	events := append([]event(nil), t.events...)
+	t.mu.Unlock()
+
+	// Outer spans first, so inner ones land on the same track
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].start.Equal(events[j].start) {
+			return events[i].start.Before(events[j].start)
+		}
+		return events[i].duration > events[j].duration
+	})
+
+	var tracks [][]time.Time // Ends of the open spans on each track
+	trace := struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}{TraceEvents: make([]traceEvent, 0, len(events))}
+	for _, e := range events {
+		end := e.start.Add(e.duration)
+		track := -1
+		for i, open := range tracks {
+			for len(open) > 0 && !open[len(open)-1].After(e.start) {
+				open = open[:len(open)-1]
+			}
+			tracks[i] = open
+			if track < 0 && (len(open) == 0 || !open[len(open)-1].Before(end)) {
+				track = i
+			}
+		}
+		if track < 0 {
+			track = len(tracks)
+			tracks = append(tracks, nil)
+		}
+		tracks[track] = append(tracks[track], end)
+
+		trace.TraceEvents = append(trace.TraceEvents, traceEvent{
+			Name:     e.name,
+			Phase:    "X",
+			Time:     float64(e.start.Sub(t.start).Nanoseconds()) / 1e3,
+			Duration: float64(e.duration.Nanoseconds()) / 1e3,
+			Process:  os.Getpid(),
+			Thread:   track + 1,
+			Args:     e.args,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(trace)
+}
+
+// WriteFile writes the trace to a file
+func (t *Tracer) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create trace file: %w", err)
+	}
+	if err := t.Write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write trace: %w", err)
+	}
+	return f.Close()
+}
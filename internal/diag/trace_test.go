@@ -0,0 +1,97 @@
+package diag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type decodedTrace struct {
+	TraceEvents []struct {
+		Name  string         `json:"name"`
+		Phase string         `json:"ph"`
+		Time  float64        `json:"ts"`
+		Dur   float64        `json:"dur"`
+		Tid   int            `json:"tid"`
+		Args  map[string]any `json:"args"`
+	} `json:"traceEvents"`
+}
+
+func TestTracer_Write(t *testing.T) {
+	tracer := NewTracer()
+	base := tracer.start
+	at := func(ms int) time.Time { return base.Add(time.Duration(ms) * time.Millisecond) }
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+	// run contains list and fzf; fetch overlaps list without nesting
+	tracer.add("fzf", at(40), ms(50), nil)
+	tracer.add("run", at(0), ms(100), []any{"command", "ob-cli"})
+	tracer.add("list", at(10), ms(20), []any{"files", 3})
+	tracer.add("fetch", at(20), ms(200), []any{"error", errors.New("offline")})
+
+	var buf bytes.Buffer
+	if err := tracer.Write(&buf); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var trace decodedTrace
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("invalid trace JSON: %v\n%s", err, buf.String())
+	}
+
+	want := []struct {
+		name string
+		ts   float64
+		dur  float64
+		tid  int
+	}{
+		{"run", 0, 100000, 1},
+		{"list", 10000, 20000, 1},
+		{"fetch", 20000, 200000, 2},
+		{"fzf", 40000, 50000, 1},
+	}
+	if len(trace.TraceEvents) != len(want) {
+		t.Fatalf("got %d events, want %d", len(trace.TraceEvents), len(want))
+	}
+	for i, w := range want {
+		got := trace.TraceEvents[i]
+		if got.Name != w.name || got.Phase != "X" || got.Time != w.ts || got.Dur != w.dur || got.Tid != w.tid {
+			t.Errorf("event %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if got := trace.TraceEvents[2].Args["error"]; got != "offline" {
+		t.Errorf("fetch error arg = %v, want offline", got)
+	}
+	if got := trace.TraceEvents[1].Args["files"]; got != float64(3) {
+		t.Errorf("list files arg = %v, want 3", got)
+	}
+}
+
+func TestSpan_Traced(t *testing.T) {
+	tracer := NewTracer()
+	ctx := WithTracer(context.Background(), tracer)
+	Start(ctx, "vault walk").End("files", 2)
+
+	path := filepath.Join(t.TempDir(), "trace.json")
+	if err := tracer.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace decodedTrace
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatalf("invalid trace JSON: %v", err)
+	}
+	if len(trace.TraceEvents) != 1 || trace.TraceEvents[0].Name != "vault walk" {
+		t.Fatalf("events = %+v", trace.TraceEvents)
+	}
+	if _, ok := trace.TraceEvents[0].Args["duration"]; !ok {
+		t.Errorf("args = %v, want a duration", trace.TraceEvents[0].Args)
+	}
+}
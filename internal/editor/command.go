@@ -0,0 +1,169 @@
+package editor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// guiWaitFlags maps GUI editors that return immediately to the flags that
+// make them block until the file is closed. The first flag is the one added.
+var guiWaitFlags = map[string][]string{
+	"code":          {"--wait", "-w"},
+	"code-insiders": {"--wait", "-w"},
+	"codium":        {"--wait", "-w"},
+	"cursor":        {"--wait", "-w"},
+	"subl":          {"--wait", "-w"},
+	"atom":          {"--wait", "-w"},
+	"zed":           {"--wait", "-w"},
+	"mate":          {"--wait", "-w"},
+	"gedit":         {"--wait", "-w"},
+	"kate":          {"--block", "-b"},
+	"gvim":          {"--nofork", "-f"},
+	"mvim":          {"--nofork", "-f"},
+}
+
+// lineArgs returns the arguments that open filePath at a 1-based line.
+// Editors without a known syntax just get the file.
+func lineArgs(editor, filePath string, line int) []string {
+	if line <= 0 {
+		return []string{filePath}
+	}
+
+	switch name := strings.TrimSuffix(filepath.Base(editor), ".exe"); {
+	case vimEditors[name], name == "nano", name == "emacs", name == "emacsclient",
+		name == "kak", name == "micro", name == "mg", name == "joe", name == "gedit":
+		return []string{fmt.Sprintf("+%d", line), filePath}
+	case name == "code", name == "code-insiders", name == "codium", name == "cursor":
+		return []string{"--goto", fmt.Sprintf("%s:%d", filePath, line)}
+	case name == "subl", name == "zed", name == "hx", name == "helix":
+		return []string{fmt.Sprintf("%s:%d", filePath, line)}
+	case name == "kate":
+		return []string{"--line", fmt.Sprint(line), filePath}
+	case name == "mate":
+		return []string{"-l", fmt.Sprint(line), filePath}
+	default:
+		return []string{filePath}
+	}
+}
+
+// resolveEditor picks the editor command string in order of precedence:
+// explicit override, $VISUAL (only when attached to a terminal), $EDITOR.
+func resolveEditor(override string, tty bool) string {
+	if override != "" {
+		return override
+	}
+	if visual := os.Getenv("VISUAL"); visual != "" && tty {
+		return visual
+	}
+	return os.Getenv("EDITOR")
+}
+
+// splitCommand splits a command line into words using POSIX shell quoting
+// rules: single quotes, double quotes, backslash escapes and a leading ~.
+func splitCommand(command string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	quoted := false
+
+	flush := func() {
+		if !inWord {
+			return
+		}
+		w := word.String()
+		if !quoted {
+			w = expandHome(w)
+		}
+		words = append(words, w)
+		word.Reset()
+		inWord, quoted = false, false
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		case r == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash")
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+			inWord, quoted = true, true
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inWord, quoted = true, true
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	flush()
+
+	return words, nil
+}
+
+// withWaitFlag appends the blocking flag for known GUI editors unless one
+// of their wait flags is already present.
+func withWaitFlag(argv []string) []string {
+	name := strings.TrimSuffix(filepath.Base(argv[0]), ".exe")
+	flags, ok := guiWaitFlags[name]
+	if !ok {
+		return argv
+	}
+	for _, arg := range argv[1:] {
+		for _, flag := range flags {
+			if arg == flag {
+				return argv
+			}
+		}
+	}
+	return append(argv, flags[0])
+}
+
+// isTerminal reports whether f is connected to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func expandHome(word string) string {
+	if word != "~" && !strings.HasPrefix(word, "~/") {
+		return word
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return word
+	}
+	return filepath.Join(home, word[1:])
+}
+
+func indexRune(runes []rune, from int, r rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,177 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+func TestSplitCommand(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		command  string
+		expected []string
+		wantErr  bool
+	}{
+		{"single word", "vim", []string{"vim"}, false},
+		{"with flag", "code --wait", []string{"code", "--wait"}, false},
+		{"extra whitespace", "  nvim   -u  init.vim ", []string{"nvim", "-u", "init.vim"}, false},
+		{"tilde expansion", "nvim -u ~/.notes.vim", []string{"nvim", "-u", filepath.Join(home, ".notes.vim")}, false},
+		{"quoted tilde", "nvim -u '~/.notes.vim'", []string{"nvim", "-u", "~/.notes.vim"}, false},
+		{"single quotes", "'/opt/My Editor/bin/ed' -n", []string{"/opt/My Editor/bin/ed", "-n"}, false},
+		{"double quotes", `emacs --eval "(setq x \"y\")"`, []string{"emacs", "--eval", `(setq x "y")`}, false},
+		{"backslash space", `/opt/My\ Editor/ed`, []string{"/opt/My Editor/ed"}, false},
+		{"empty quotes", `vim ""`, []string{"vim", ""}, false},
+		{"unterminated single", "vim 'abc", nil, true},
+		{"unterminated double", `vim "abc`, nil, true},
+		{"trailing backslash", `vim \`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := splitCommand(tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitCommand(%q) error = %v, wantErr %v", tt.command, err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(words, tt.expected) {
+				t.Errorf("splitCommand(%q) = %q, expected %q", tt.command, words, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveEditor(t *testing.T) {
+	tests := []struct {
+		name     string
+		override string
+		visual   string
+		editor   string
+		tty      bool
+		expected string
+	}{
+		{"override wins", "code --wait", "nvim", "vim", true, "code --wait"},
+		{"visual with tty", "", "nvim", "vi", true, "nvim"},
+		{"visual without tty", "", "nvim", "vi", false, "vi"},
+		{"editor only", "", "", "nano", true, "nano"},
+		{"nothing set", "", "", "", true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+
+			if got := resolveEditor(tt.override, tt.tty); got != tt.expected {
+				t.Errorf("resolveEditor() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithWaitFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		argv     []string
+		expected []string
+	}{
+		{"terminal editor untouched", []string{"vim"}, []string{"vim"}},
+		{"code gets wait", []string{"code"}, []string{"code", "--wait"}},
+		{"code with -w kept", []string{"code", "-w"}, []string{"code", "-w"}},
+		{"full path", []string{"/usr/local/bin/subl", "-n"}, []string{"/usr/local/bin/subl", "-n", "--wait"}},
+		{"gvim nofork", []string{"gvim"}, []string{"gvim", "--nofork"}},
+		{"gvim with -f kept", []string{"gvim", "-f"}, []string{"gvim", "-f"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withWaitFlag(tt.argv); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("withWaitFlag(%q) = %q, expected %q", tt.argv, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRealService_OpenFile_Arguments(t *testing.T) {
+	tempDir := t.TempDir()
+	argsFile := filepath.Join(tempDir, "args")
+
+	// The editor command carries its own arguments; the file is appended last
+	service := NewService(Profile{Command: "sh -c 'printf \"%s\\n\" \"$@\" > " + argsFile + "' editor --flag"})
+	if err := service.OpenFile(context.Background(), "notes/daily.md"); err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("Failed to read recorded arguments: %v", err)
+	}
+	expected := "--flag\nnotes/daily.md\n"
+	if string(data) != expected {
+		t.Errorf("Expected editor arguments %q, got %q", expected, string(data))
+	}
+}
+
+func TestRealService_OpenFile_NonZeroExit(t *testing.T) {
+	service := NewService(Profile{Command: "sh -c 'exit 3'"})
+
+	err := service.OpenFile(context.Background(), "test.md")
+	if err == nil {
+		t.Fatal("Expected error for failing editor, got nil")
+	}
+	if !strings.Contains(err.Error(), "exited with status 3") {
+		t.Errorf("Expected exit status in error, got %q", err.Error())
+	}
+	if !errors.Is(err, errs.ErrEditorFailed) {
+		t.Errorf("Expected errs.ErrEditorFailed, got %v", err)
+	}
+}
+
+func TestRealService_OpenFile_NotFound(t *testing.T) {
+	service := NewService(Profile{Command: "ob-cli-no-such-editor"})
+
+	err := service.OpenFile(context.Background(), "test.md")
+	if !errors.Is(err, errs.ErrToolMissing) {
+		t.Errorf("Expected errs.ErrToolMissing, got %v", err)
+	}
+}
+
+func TestRealService_OpenFile_InvalidCommand(t *testing.T) {
+	service := NewService(Profile{Command: "vim 'unterminated"})
+
+	err := service.OpenFile(context.Background(), "test.md")
+	if err == nil || !strings.Contains(err.Error(), "invalid editor command") {
+		t.Errorf("Expected invalid editor command error, got %v", err)
+	}
+}
+
+func TestLineArgs(t *testing.T) {
+	tests := []struct {
+		editor   string
+		line     int
+		expected []string
+	}{
+		{"nvim", 12, []string{"+12", "note.md"}},
+		{"/usr/bin/nano", 3, []string{"+3", "note.md"}},
+		{"code", 7, []string{"--goto", "note.md:7"}},
+		{"hx", 2, []string{"note.md:2"}},
+		{"unknown-editor", 5, []string{"note.md"}},
+		{"nvim", 0, []string{"note.md"}},
+	}
+
+	for _, tt := range tests {
+		if got := lineArgs(tt.editor, "note.md", tt.line); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("lineArgs(%q, %d) = %q, expected %q", tt.editor, tt.line, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package editor
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// This file implements the subset of MessagePack needed to talk to nvim's
+// msgpack-RPC API: requests are encoded from strings, integers and arrays,
+// and responses are decoded into plain Go values.
+
+// extValue is a decoded msgpack extension (nvim uses these for handles)
+type extValue struct {
+	Type int8
+	Data []byte
+}
+
+// encodeMsgpack appends the msgpack encoding of v to buf
+func encodeMsgpack(buf []byte, v interface{}) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if v {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case int:
+		if v < 0 {
+			if v >= -32 {
+				return append(buf, byte(int8(v))), nil
+			}
+			return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(int64(v))), nil
+		}
+		return encodeUint(buf, uint64(v)), nil
+	case uint32:
+		return encodeUint(buf, uint64(v)), nil
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			buf = append(buf, 0xa0|byte(n))
+		case n <= math.MaxUint8:
+			buf = append(buf, 0xd9, byte(n))
+		case n <= math.MaxUint16:
+			buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+		default:
+			buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+		}
+		return append(buf, v...), nil
+	case []interface{}:
+		n := len(v)
+		switch {
+		case n < 16:
+			buf = append(buf, 0x90|byte(n))
+		case n <= math.MaxUint16:
+			buf = binary.BigEndian.AppendUint16(append(buf, 0xdc), uint16(n))
+		default:
+			buf = binary.BigEndian.AppendUint32(append(buf, 0xdd), uint32(n))
+		}
+		var err error
+		for _, item := range v {
+			if buf, err = encodeMsgpack(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported type %T", v)
+	}
+}
+
+func encodeUint(buf []byte, v uint64) []byte {
+	switch {
+	case v < 128:
+		return append(buf, byte(v))
+	case v <= math.MaxUint8:
+		return append(buf, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), v)
+	}
+}
+
+// decodeMsgpack reads a single msgpack value. Integers decode to int64,
+// strings and binaries to string, arrays to []interface{} and maps to
+// map[interface{}]interface{}.
+func decodeMsgpack(r *bufio.Reader) (interface{}, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case b <= 0x7f:
+		return int64(b), nil
+	case b >= 0xe0:
+		return int64(int8(b)), nil
+	case b&0xf0 == 0x80:
+		return decodeMap(r, int(b&0x0f))
+	case b&0xf0 == 0x90:
+		return decodeArray(r, int(b&0x0f))
+	case b&0xe0 == 0xa0:
+		return readString(r, int(b&0x1f))
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xd9:
+		n, err := readUint(r, 1)
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, int(n))
+	case 0xc5, 0xda:
+		n, err := readUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, int(n))
+	case 0xc6, 0xdb:
+		n, err := readUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, int(n))
+	case 0xc7, 0xc8, 0xc9:
+		size := map[byte]int{0xc7: 1, 0xc8: 2, 0xc9: 4}[b]
+		n, err := readUint(r, size)
+		if err != nil {
+			return nil, err
+		}
+		return readExt(r, int(n))
+	case 0xca:
+		n, err := readUint(r, 4)
+		return float64(math.Float32frombits(uint32(n))), err
+	case 0xcb:
+		n, err := readUint(r, 8)
+		return math.Float64frombits(n), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		n, err := readUint(r, 1<<(b-0xcc))
+		return int64(n), err
+	case 0xd0:
+		n, err := readUint(r, 1)
+		return int64(int8(n)), err
+	case 0xd1:
+		n, err := readUint(r, 2)
+		return int64(int16(n)), err
+	case 0xd2:
+		n, err := readUint(r, 4)
+		return int64(int32(n)), err
+	case 0xd3:
+		n, err := readUint(r, 8)
+		return int64(n), err
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return readExt(r, 1<<(b-0xd4))
+	case 0xdc:
+		n, err := readUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(r, int(n))
+	case 0xdd:
+		n, err := readUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeArray(r, int(n))
+	case 0xde:
+		n, err := readUint(r, 2)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(r, int(n))
+	case 0xdf:
+		n, err := readUint(r, 4)
+		if err != nil {
+			return nil, err
+		}
+		return decodeMap(r, int(n))
+	}
+
+	return nil, fmt.Errorf("msgpack: invalid type byte 0x%02x", b)
+}
+
+func decodeArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func decodeMap(r *bufio.Reader, n int) (map[interface{}]interface{}, error) {
+	m := make(map[interface{}]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		value, err := decodeMsgpack(r)
+		if err != nil {
+			return nil, err
+		}
+		// Only comparable keys can be stored; nvim only sends strings
+		switch key.(type) {
+		case []interface{}, map[interface{}]interface{}, extValue:
+			key = fmt.Sprint(key)
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+func readString(r *bufio.Reader, n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func readExt(r *bufio.Reader, n int) (interface{}, error) {
+	t, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return extValue{Type: int8(t), Data: buf}, nil
+}
@@ -0,0 +1,320 @@
+package editor
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+)
+
+const (
+	nvimDialTimeout = 500 * time.Millisecond
+	nvimRPCTimeout  = 2 * time.Second
+)
+
+// nvimClosed is the notification a waiting open gets when its buffer closes
+const nvimClosed = "ob-cli-closed"
+
+// RemoteService opens files in an already-running nvim over msgpack-RPC,
+// falling back to another editor service when no server is reachable
+type RemoteService struct {
+	notesDir string
+	scan     bool // Also look for --listen sockets under $XDG_RUNTIME_DIR
+	wait     bool // Return once the buffer is closed rather than at once
+	profile  Profile
+	fallback Service
+}
+
+// NewRemoteService creates an editor service that prefers a running nvim.
+// $NVIM is always honoured; when scan is set, sockets under
+// $XDG_RUNTIME_DIR are tried as well. Relative paths are resolved against
+// notesDir because the server's working directory is unknown. The
+// profile's vim settings are applied to the opened buffer. Opens return at
+// once unless wait is set, in which case they return when the buffer is
+// hidden or deleted, like a launched editor exiting.
+func NewRemoteService(notesDir string, scan, wait bool, profile Profile, fallback Service) Service {
+	return &RemoteService{
+		notesDir: notesDir,
+		scan:     scan,
+		wait:     wait,
+		profile:  profile,
+		fallback: fallback,
+	}
+}
+
+// OpenFile sends the file to a running nvim, or launches the fallback editor
+func (s *RemoteService) OpenFile(ctx context.Context, filePath string) error {
+	return s.OpenFileAt(ctx, filePath, 0)
+}
+
+// OpenFileAt sends the file and line to a running nvim, or launches the
+// fallback editor
+func (s *RemoteService) OpenFileAt(ctx context.Context, filePath string, line int) error {
+	conn := s.connect(ctx)
+	if conn == nil {
+		if line > 0 {
+			return s.fallback.OpenFileAt(ctx, filePath, line)
+		}
+		return s.fallback.OpenFile(ctx, filePath)
+	}
+	defer conn.Close()
+	client := newNvimClient(conn)
+
+	path := filePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.notesDir, path)
+	}
+
+	target := escapeFilename(path)
+	if line > 0 {
+		target = fmt.Sprintf("+%d %s", line, target)
+	}
+	span := diag.Start(ctx, "nvim remote edit", "addr", conn.RemoteAddr().String(), "file", path)
+	err := client.exec(ctx, s.script("drop "+target, path))
+	if err != nil && strings.Contains(err.Error(), "E37:") {
+		// The current buffer has unsaved changes: leave it and open a split
+		err = client.exec(ctx, s.script("split "+target, path))
+	}
+	span.End("error", err)
+	if err != nil {
+		return fmt.Errorf("failed to open %s in running nvim: %w", filePath, err)
+	}
+	if !s.wait {
+		return nil
+	}
+
+	span = diag.Start(ctx, "nvim remote wait", "file", path)
+	err = client.waitClosed(ctx)
+	span.End("error", err)
+	if err != nil {
+		return fmt.Errorf("failed to wait for %s in running nvim: %w", filePath, err)
+	}
+	return nil
+}
+
+// script follows the Ex command opening filePath with the profile's settings
+func (s *RemoteService) script(command, filePath string) string {
+	if settings := s.profile.vimSettings(filePath); len(settings) > 0 {
+		command += "\nsetlocal " + strings.Join(settings, " ")
+	}
+	return command
+}
+
+// connect returns a connection to the first reachable nvim server
+func (s *RemoteService) connect(ctx context.Context) net.Conn {
+	var candidates []string
+	if addr := os.Getenv("NVIM"); addr != "" {
+		candidates = append(candidates, addr)
+	}
+	if addr := os.Getenv("NVIM_LISTEN_ADDRESS"); addr != "" {
+		candidates = append(candidates, addr)
+	}
+	if s.scan {
+		candidates = append(candidates, findNvimSockets(os.Getenv("XDG_RUNTIME_DIR"))...)
+	}
+
+	for _, addr := range candidates {
+		if conn, err := dialNvim(ctx, addr); err == nil {
+			return conn
+		}
+	}
+	return nil
+}
+
+// findNvimSockets lists nvim server sockets under the runtime directory,
+// most recently started first. This covers the default nvim.<pid>.0
+// sockets (directly or in the nvim.<user>/ run directory) and --listen
+// sockets whose name mentions nvim.
+func findNvimSockets(runtimeDir string) []string {
+	if runtimeDir == "" {
+		return nil
+	}
+
+	type socket struct {
+		path    string
+		modTime time.Time
+	}
+	var sockets []socket
+
+	base := strings.Count(filepath.Clean(runtimeDir), string(filepath.Separator))
+	filepath.WalkDir(runtimeDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip unreadable entries
+		}
+		if d.IsDir() {
+			if strings.Count(path, string(filepath.Separator))-base >= 3 {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&fs.ModeSocket == 0 || !strings.Contains(path[len(runtimeDir):], "nvim") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		sockets = append(sockets, socket{path: path, modTime: info.ModTime()})
+		return nil
+	})
+
+	sort.Slice(sockets, func(i, j int) bool {
+		return sockets[i].modTime.After(sockets[j].modTime)
+	})
+
+	paths := make([]string, len(sockets))
+	for i, sock := range sockets {
+		paths[i] = sock.path
+	}
+	return paths
+}
+
+// dialNvim connects to a unix socket path or a host:port TCP address
+func dialNvim(ctx context.Context, addr string) (net.Conn, error) {
+	network := "unix"
+	if !strings.Contains(addr, string(filepath.Separator)) && strings.Contains(addr, ":") {
+		network = "tcp"
+	}
+	dialer := net.Dialer{Timeout: nvimDialTimeout}
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// nvimClient makes msgpack-RPC requests on a connection to nvim, one at a
+// time, so each is answered before the next
+type nvimClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	lastID int
+}
+
+func newNvimClient(conn net.Conn) *nvimClient {
+	return &nvimClient{conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// exec runs lines of Ex commands through nvim_exec
+func (c *nvimClient) exec(ctx context.Context, script string) error {
+	_, err := c.call(ctx, "nvim_exec", script, false)
+	return err
+}
+
+// call sends a request and waits for its result, giving up when ctx is
+// done or nvim doesn't answer in time. Notifications are skipped.
+func (c *nvimClient) call(ctx context.Context, method string, args ...interface{}) (interface{}, error) {
+	c.lastID++
+	msgID := c.lastID
+	if args == nil {
+		args = []interface{}{}
+	}
+	request, err := encodeMsgpack(nil, []interface{}{0, msgID, method, args})
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn.SetDeadline(time.Now().Add(nvimRPCTimeout))
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	defer stop()
+	if _, err := c.conn.Write(request); err != nil {
+		return nil, err
+	}
+
+	for {
+		msg, err := decodeMsgpack(c.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		// Responses are [1, msgid, error, result]
+		fields, ok := msg.([]interface{})
+		if !ok || len(fields) != 4 || fields[0] != int64(1) || fields[1] != int64(msgID) {
+			continue
+		}
+		if fields[2] != nil {
+			return nil, fmt.Errorf("nvim: %s", nvimErrorMessage(fields[2]))
+		}
+		return fields[3], nil
+	}
+}
+
+// waitClosed waits until the current buffer is hidden or deleted, or nvim
+// exits. The buffer gets autocommands notifying this connection's channel,
+// which remove themselves when they fire.
+func (c *nvimClient) waitClosed(ctx context.Context) error {
+	info, err := c.call(ctx, "nvim_get_api_info")
+	if err != nil {
+		return err
+	}
+	fields, ok := info.([]interface{})
+	if !ok || len(fields) == 0 {
+		return fmt.Errorf("unexpected nvim_get_api_info result %v", info)
+	}
+	channel, ok := fields[0].(int64)
+	if !ok {
+		return fmt.Errorf("unexpected nvim channel %v", fields[0])
+	}
+
+	// silent! because nvim may outlive this connection
+	group := fmt.Sprintf("ob_cli_wait_%d", channel)
+	err = c.exec(ctx, strings.Join([]string{
+		"augroup " + group,
+		"autocmd!",
+		fmt.Sprintf("autocmd BufHidden,BufDelete <buffer> silent! call rpcnotify(%d, %q) | autocmd! %s", channel, nvimClosed, group),
+		"augroup END",
+	}, "\n"))
+	if err != nil {
+		return err
+	}
+
+	// No deadline: the user may take as long as they like
+	c.conn.SetDeadline(time.Time{})
+	stop := context.AfterFunc(ctx, func() { c.conn.SetDeadline(time.Now()) })
+	defer stop()
+	for {
+		msg, err := decodeMsgpack(c.reader)
+		if errors.Is(err, io.EOF) {
+			return nil // nvim exited
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+
+		// Notifications are [2, method, params]
+		if fields, ok := msg.([]interface{}); ok && len(fields) == 3 && fields[0] == int64(2) && fields[1] == nvimClosed {
+			return nil
+		}
+	}
+}
+
+// nvimErrorMessage extracts the message from an nvim [type, message] error
+func nvimErrorMessage(e interface{}) string {
+	if fields, ok := e.([]interface{}); ok && len(fields) == 2 {
+		if msg, ok := fields[1].(string); ok {
+			return msg
+		}
+	}
+	return fmt.Sprint(e)
+}
+
+// escapeFilename escapes a path for use in an Ex command like fnameescape()
+func escapeFilename(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if strings.ContainsRune(" \t\n*?[{`$\\%#'\"|!<", r) {
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
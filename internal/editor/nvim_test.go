@@ -0,0 +1,312 @@
+package editor
+
+import (
+	"context"
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeNvim serves one msgpack-RPC request per reply error, recording the
+// Ex commands sent
+func fakeNvim(t *testing.T, socketPath string, replyErrs ...interface{}) <-chan string {
+	t.Helper()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on %s: %v", socketPath, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	if len(replyErrs) == 0 {
+		replyErrs = []interface{}{nil}
+	}
+	commands := make(chan string, len(replyErrs))
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for _, replyErr := range replyErrs {
+			msg, err := decodeMsgpack(reader)
+			if err != nil {
+				return
+			}
+			request := msg.([]interface{})
+			commands <- request[3].([]interface{})[0].(string)
+
+			// Send an unrelated notification before the response
+			notification, _ := encodeMsgpack(nil, []interface{}{2, "redraw", []interface{}{}})
+			response, _ := encodeMsgpack(nil, []interface{}{1, int(request[1].(int64)), replyErr, nil})
+			conn.Write(append(notification, response...))
+		}
+	}()
+	return commands
+}
+
+func TestMsgpack_RoundTrip(t *testing.T) {
+	values := []interface{}{
+		nil, true, false, 0, 1, 127, 128, 70000, -1, -33,
+		"", "short", string(bytes.Repeat([]byte("x"), 300)),
+		[]interface{}{0, 1, "nvim_command", []interface{}{"edit foo.md"}},
+	}
+	expected := []interface{}{
+		nil, true, false, int64(0), int64(1), int64(127), int64(128), int64(70000), int64(-1), int64(-33),
+		"", "short", string(bytes.Repeat([]byte("x"), 300)),
+		[]interface{}{int64(0), int64(1), "nvim_command", []interface{}{"edit foo.md"}},
+	}
+
+	for i, v := range values {
+		encoded, err := encodeMsgpack(nil, v)
+		if err != nil {
+			t.Fatalf("encodeMsgpack(%v) failed: %v", v, err)
+		}
+		decoded, err := decodeMsgpack(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatalf("decodeMsgpack(%v) failed: %v", v, err)
+		}
+		if !reflect.DeepEqual(decoded, expected[i]) {
+			t.Errorf("Round trip of %v: expected %#v, got %#v", v, expected[i], decoded)
+		}
+	}
+}
+
+func TestMsgpack_DecodeMapAndExt(t *testing.T) {
+	// {"a": 1} followed by a fixext1 buffer handle of type 0
+	data := []byte{0x81, 0xa1, 'a', 0x01, 0xd4, 0x00, 0x05}
+	reader := bufio.NewReader(bytes.NewReader(data))
+
+	m, err := decodeMsgpack(reader)
+	if err != nil {
+		t.Fatalf("Failed to decode map: %v", err)
+	}
+	if !reflect.DeepEqual(m, map[interface{}]interface{}{"a": int64(1)}) {
+		t.Errorf("Unexpected map %#v", m)
+	}
+
+	ext, err := decodeMsgpack(reader)
+	if err != nil {
+		t.Fatalf("Failed to decode ext: %v", err)
+	}
+	if !reflect.DeepEqual(ext, extValue{Type: 0, Data: []byte{0x05}}) {
+		t.Errorf("Unexpected ext %#v", ext)
+	}
+}
+
+func TestRemoteService_OpenFile_UsesNvimEnv(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "nvim.sock")
+	commands := fakeNvim(t, socketPath)
+	t.Setenv("NVIM", socketPath)
+	t.Setenv("NVIM_LISTEN_ADDRESS", "")
+
+	fallback := NewMockService([]string{}, 0, nil)
+	service := NewRemoteService("/vault", false, false, DefaultProfile("obsidian"), fallback)
+
+	if err := service.OpenFile(context.Background(), "my notes/daily.md"); err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+
+	expected := "drop /vault/my\\ notes/daily.md\nsetlocal filetype=markdown conceallevel=2"
+	if command := <-commands; command != expected {
+		t.Errorf("Expected drop command for absolute path, got %q", command)
+	}
+	if opened := fallback.(*MockService).OpenedFiles; len(opened) != 0 {
+		t.Errorf("Expected fallback not to be used, got %v", opened)
+	}
+}
+
+func TestRemoteService_OpenFile_ScansRuntimeDir(t *testing.T) {
+	runtimeDir := t.TempDir()
+	runDir := filepath.Join(runtimeDir, "nvim.user", "abc")
+	if err := os.MkdirAll(runDir, 0700); err != nil {
+		t.Fatalf("Failed to create run directory: %v", err)
+	}
+	commands := fakeNvim(t, filepath.Join(runDir, "nvim.123.0"))
+	t.Setenv("NVIM", "")
+	t.Setenv("NVIM_LISTEN_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+
+	service := NewRemoteService("/vault", true, false, Profile{}, NewMockService([]string{}, 0, nil))
+	if err := service.OpenFileAt(context.Background(), "/abs/note.md", 12); err != nil {
+		t.Fatalf("OpenFileAt failed: %v", err)
+	}
+	if command := <-commands; command != "drop +12 /abs/note.md" {
+		t.Errorf("Expected drop command, got %q", command)
+	}
+}
+
+func TestRemoteService_OpenFile_Fallback(t *testing.T) {
+	t.Setenv("NVIM", filepath.Join(t.TempDir(), "missing.sock"))
+	t.Setenv("NVIM_LISTEN_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	fallback := NewMockService([]string{}, 0, nil)
+	service := NewRemoteService("/vault", true, false, Profile{}, fallback)
+
+	if err := service.OpenFile(context.Background(), "daily.md"); err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	opened := fallback.(*MockService).OpenedFiles
+	if len(opened) != 1 || opened[0] != "daily.md" {
+		t.Errorf("Expected fallback to open 'daily.md', got %v", opened)
+	}
+}
+
+func TestRemoteService_OpenFile_NvimError(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "nvim.sock")
+	fakeNvim(t, socketPath, []interface{}{0, "E325: ATTENTION"})
+	t.Setenv("NVIM", socketPath)
+
+	service := NewRemoteService("/vault", false, false, Profile{}, NewMockService([]string{}, 0, errors.New("unused")))
+	err := service.OpenFile(context.Background(), "daily.md")
+	if err == nil || !bytes.Contains([]byte(err.Error()), []byte("E325")) {
+		t.Errorf("Expected nvim error to be reported, got %v", err)
+	}
+}
+
+func TestRemoteService_OpenFile_SplitsOnUnsavedChanges(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "nvim.sock")
+	commands := fakeNvim(t, socketPath, []interface{}{0, "E37: No write since last change"}, nil)
+	t.Setenv("NVIM", socketPath)
+
+	service := NewRemoteService("/vault", false, false, DefaultProfile("tips"), NewMockService([]string{}, 0, errors.New("unused")))
+	if err := service.OpenFile(context.Background(), "daily.md"); err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	<-commands
+	if command := <-commands; command != "split /vault/daily.md\nsetlocal filetype=markdown conceallevel=0" {
+		t.Errorf("Expected split command, got %q", command)
+	}
+}
+
+// fakeNvimWaiting answers an open and the requests a waiting open makes as
+// channel 7, recording the Ex commands sent, then sends the closed
+// notification once release is closed
+func fakeNvimWaiting(t *testing.T, socketPath string, release <-chan struct{}) <-chan string {
+	t.Helper()
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on %s: %v", socketPath, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	commands := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		for i := 0; i < 3; i++ {
+			msg, err := decodeMsgpack(reader)
+			if err != nil {
+				return
+			}
+			request := msg.([]interface{})
+			var result interface{}
+			if request[2] == "nvim_get_api_info" {
+				result = []interface{}{7, []interface{}{}}
+			} else {
+				commands <- request[3].([]interface{})[0].(string)
+			}
+			response, _ := encodeMsgpack(nil, []interface{}{1, int(request[1].(int64)), nil, result})
+			conn.Write(response)
+		}
+
+		<-release
+		notification, _ := encodeMsgpack(nil, []interface{}{2, nvimClosed, []interface{}{}})
+		conn.Write(notification)
+	}()
+	return commands
+}
+
+func TestRemoteService_OpenFile_WaitsForClose(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "nvim.sock")
+	release := make(chan struct{})
+	commands := fakeNvimWaiting(t, socketPath, release)
+	t.Setenv("NVIM", socketPath)
+
+	service := NewRemoteService("/vault", false, true, Profile{}, NewMockService([]string{}, 0, errors.New("unused")))
+	done := make(chan error, 1)
+	go func() { done <- service.OpenFile(context.Background(), "daily.md") }()
+
+	if command := <-commands; command != "drop /vault/daily.md" {
+		t.Errorf("Expected drop command, got %q", command)
+	}
+	expected := "augroup ob_cli_wait_7\nautocmd!\n" +
+		`autocmd BufHidden,BufDelete <buffer> silent! call rpcnotify(7, "ob-cli-closed") | autocmd! ob_cli_wait_7` +
+		"\naugroup END"
+	if command := <-commands; command != expected {
+		t.Errorf("Expected autocommands %q, got %q", expected, command)
+	}
+	select {
+	case err := <-done:
+		t.Fatalf("OpenFile returned before the buffer closed: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Errorf("OpenFile failed: %v", err)
+	}
+}
+
+func TestRemoteService_OpenFile_WaitEndsWithNvim(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "nvim.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on %s: %v", socketPath, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	t.Setenv("NVIM", socketPath)
+
+	// Answers the open, the channel request and the autocommands, then exits
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for i := 0; i < 3; i++ {
+			msg, err := decodeMsgpack(reader)
+			if err != nil {
+				return
+			}
+			request := msg.([]interface{})
+			response, _ := encodeMsgpack(nil, []interface{}{1, int(request[1].(int64)), nil, []interface{}{3}})
+			conn.Write(response)
+		}
+	}()
+
+	service := NewRemoteService("/vault", false, true, Profile{}, NewMockService([]string{}, 0, errors.New("unused")))
+	if err := service.OpenFile(context.Background(), "daily.md"); err != nil {
+		t.Errorf("OpenFile failed: %v", err)
+	}
+}
+
+func TestEscapeFilename(t *testing.T) {
+	tests := map[string]string{
+		"/vault/note.md":        "/vault/note.md",
+		"/vault/my note.md":     `/vault/my\ note.md`,
+		"/vault/#1 %tmp|x.md":   `/vault/\#1\ \%tmp\|x.md`,
+		`/vault/it's "done".md`: `/vault/it\'s\ \"done\".md`,
+	}
+	for in, expected := range tests {
+		if got := escapeFilename(in); got != expected {
+			t.Errorf("escapeFilename(%q) = %q, expected %q", in, got, expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// DefaultOpener is the key of the opener used for file types without one
+const DefaultOpener = "default"
+
+// Opener opens files the editor doesn't handle, such as images and PDFs,
+// in another program
+type Opener interface {
+	Open(ctx context.Context, filePath, fileType string) error
+}
+
+// RealOpener runs configured or system opener commands
+type RealOpener struct {
+	commands map[string]string
+}
+
+// MockOpener records opened files for testing
+type MockOpener struct {
+	OpenedFiles []string
+	OpenedTypes []string
+	Error       error
+}
+
+// NewOpener creates an opener running the command configured for a file
+// type (e.g. "zathura" for pdf), else the one for DefaultOpener, else the
+// system's: xdg-open, or open on macOS. The file is the last argument.
+func NewOpener(commands map[string]string) Opener {
+	return &RealOpener{commands: commands}
+}
+
+// NewMockOpener creates a new mock opener
+func NewMockOpener(err error) Opener {
+	return &MockOpener{Error: err}
+}
+
+// Open opens filePath with the opener for fileType and waits for the
+// opener to return; xdg-open and open return once the viewer has started
+func (o *RealOpener) Open(ctx context.Context, filePath, fileType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	command := o.command(fileType)
+	argv, err := splitCommand(command)
+	if err != nil {
+		return fmt.Errorf("invalid opener command %q: %w", command, err)
+	}
+	if len(argv) == 0 {
+		return fmt.Errorf("invalid opener command %q: empty command", command)
+	}
+
+	// Like the editor, the opener isn't tied to ctx
+	cmd := exec.Command(argv[0], append(argv[1:], filePath)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := diag.Run(ctx, cmd); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return errs.New(errs.ErrEditorFailed, "opener %s exited with status %d", argv[0], exitErr.ExitCode())
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return errs.New(errs.ErrToolMissing, "opener %s not found: %w", argv[0], err)
+		}
+		return errs.New(errs.ErrEditorFailed, "failed to run opener %s: %w", argv[0], err)
+	}
+	return nil
+}
+
+// command returns the opener command line for fileType
+func (o *RealOpener) command(fileType string) string {
+	if command := o.commands[fileType]; command != "" {
+		return command
+	}
+	if command := o.commands[DefaultOpener]; command != "" {
+		return command
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return "open"
+	case "windows":
+		return "rundll32 url.dll,FileProtocolHandler"
+	default:
+		return "xdg-open"
+	}
+}
+
+// Open mock implementation
+func (o *MockOpener) Open(ctx context.Context, filePath, fileType string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if o.Error != nil {
+		return o.Error
+	}
+	o.OpenedFiles = append(o.OpenedFiles, filePath)
+	o.OpenedTypes = append(o.OpenedTypes, fileType)
+	return nil
+}
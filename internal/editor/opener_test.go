@@ -0,0 +1,74 @@
+package editor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+func TestRealOpener_command(t *testing.T) {
+	opener := NewOpener(map[string]string{"pdf": "zathura --fork", DefaultOpener: "feh"}).(*RealOpener)
+	if got := opener.command("pdf"); got != "zathura --fork" {
+		t.Errorf("command(pdf) = %q", got)
+	}
+	if got := opener.command("image"); got != "feh" {
+		t.Errorf("command(image) = %q, want the default opener", got)
+	}
+
+	want := "xdg-open"
+	switch runtime.GOOS {
+	case "darwin":
+		want = "open"
+	case "windows":
+		want = "rundll32 url.dll,FileProtocolHandler"
+	}
+	if got := NewOpener(nil).(*RealOpener).command("pdf"); got != want {
+		t.Errorf("system opener = %q, want %q", got, want)
+	}
+}
+
+func TestRealOpener_Open(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the opener is a shell script")
+	}
+	dir := t.TempDir()
+	script := filepath.Join(dir, "viewer")
+	os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/opened\"\n"), 0755)
+
+	opener := NewOpener(map[string]string{"pdf": script + " --page 1", "image": "false"})
+	if err := opener.Open(context.Background(), "/vault/spec.pdf", "pdf"); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "opened")); string(data) != "--page 1 /vault/spec.pdf\n" {
+		t.Errorf("opener got %q", data)
+	}
+
+	if err := opener.Open(context.Background(), "/vault/cat.png", "image"); !errors.Is(err, errs.ErrEditorFailed) {
+		t.Errorf("Open() with a failing opener error = %v, want %v", err, errs.ErrEditorFailed)
+	}
+	opener = NewOpener(map[string]string{DefaultOpener: "ob-cli-no-such-opener"})
+	if err := opener.Open(context.Background(), "/vault/a.bin", "other"); !errors.Is(err, errs.ErrToolMissing) {
+		t.Errorf("Open() with a missing opener error = %v, want %v", err, errs.ErrToolMissing)
+	}
+}
+
+func TestMockOpener_Open(t *testing.T) {
+	opener := NewMockOpener(nil).(*MockOpener)
+	if err := opener.Open(context.Background(), "cat.png", "image"); err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if len(opener.OpenedFiles) != 1 || opener.OpenedTypes[0] != "image" {
+		t.Errorf("opened %v %v", opener.OpenedFiles, opener.OpenedTypes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := opener.Open(ctx, "cat.png", "image"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Open() after cancel error = %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package editor
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/filetype"
+)
+
+// vimEditors are editors that accept vim option settings through -c
+var vimEditors = map[string]bool{
+	"vi":   true,
+	"vim":  true,
+	"nvim": true,
+	"gvim": true,
+	"mvim": true,
+	"view": true,
+}
+
+// Profile holds the editor settings for a vault/mode. Unset (nil) options
+// leave the editor's own configuration untouched.
+type Profile struct {
+	Command      string   `mapstructure:"command"`      // Editor command, overrides $VISUAL/$EDITOR
+	ConcealLevel *int     `mapstructure:"conceallevel"` // vim 'conceallevel'
+	Filetype     string   `mapstructure:"filetype"`     // vim 'filetype'
+	Spell        *bool    `mapstructure:"spell"`        // vim 'spell'
+	Wrap         *bool    `mapstructure:"wrap"`         // vim 'wrap'
+	Args         []string `mapstructure:"args"`         // Extra arguments placed before the file
+	Remote       *bool    `mapstructure:"remote"`       // Open in a running nvim (unset: only via $NVIM)
+}
+
+// DefaultProfile returns the built-in profile for a mode. Obsidian notes
+// are rendered with concealed markup; Tips notes are shown as plain text.
+func DefaultProfile(mode string) Profile {
+	switch mode {
+	case "obsidian":
+		return Profile{ConcealLevel: intPtr(2), Filetype: "markdown"}
+	case "tips":
+		return Profile{ConcealLevel: intPtr(0), Filetype: "markdown"}
+	default:
+		return Profile{}
+	}
+}
+
+// Merge returns p with every option that is set in override replaced
+func (p Profile) Merge(override Profile) Profile {
+	if override.Command != "" {
+		p.Command = override.Command
+	}
+	if override.ConcealLevel != nil {
+		p.ConcealLevel = override.ConcealLevel
+	}
+	if override.Filetype != "" {
+		p.Filetype = override.Filetype
+	}
+	if override.Spell != nil {
+		p.Spell = override.Spell
+	}
+	if override.Wrap != nil {
+		p.Wrap = override.Wrap
+	}
+	if len(override.Args) > 0 {
+		p.Args = override.Args
+	}
+	if override.Remote != nil {
+		p.Remote = override.Remote
+	}
+	return p
+}
+
+// ResolvedCommand returns the editor command line the profile runs in a terminal:
+// its own command, else $VISUAL, else $EDITOR
+func (p Profile) ResolvedCommand() string {
+	return resolveEditor(p.Command, true)
+}
+
+// EditorArgs returns the arguments the profile adds for the given editor
+// opening filePath. vim-family editors get a single -c setlocal command;
+// every editor gets the profile's extra arguments.
+func (p Profile) EditorArgs(editor, filePath string) []string {
+	var args []string
+
+	name := strings.TrimSuffix(filepath.Base(editor), ".exe")
+	if vimEditors[name] {
+		if settings := p.vimSettings(filePath); len(settings) > 0 {
+			args = append(args, "-c", "setlocal "+strings.Join(settings, " "))
+		}
+	}
+
+	return append(args, p.Args...)
+}
+
+// vimSettings renders the profile as vim options for filePath. filetype
+// comes first so that ftplugins cannot reset the options that follow it.
+// filetype and conceallevel only apply to markdown notes; vim detects the
+// type of other files, such as canvases.
+func (p Profile) vimSettings(filePath string) []string {
+	var settings []string
+	if fileType := filetype.Of(filePath); fileType == filetype.Markdown || fileType == filetype.Excalidraw {
+		if p.Filetype != "" {
+			settings = append(settings, "filetype="+p.Filetype)
+		}
+		if p.ConcealLevel != nil {
+			settings = append(settings, fmt.Sprintf("conceallevel=%d", *p.ConcealLevel))
+		}
+	}
+	if p.Spell != nil {
+		settings = append(settings, boolOption("spell", *p.Spell))
+	}
+	if p.Wrap != nil {
+		settings = append(settings, boolOption("wrap", *p.Wrap))
+	}
+	return settings
+}
+
+func boolOption(name string, on bool) string {
+	if on {
+		return name
+	}
+	return "no" + name
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,102 @@
+package editor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultProfile(t *testing.T) {
+	tests := []struct {
+		mode         string
+		concealLevel *int
+		filetype     string
+	}{
+		{"obsidian", intPtr(2), "markdown"},
+		{"tips", intPtr(0), "markdown"},
+		{"unknown", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			profile := DefaultProfile(tt.mode)
+			if !reflect.DeepEqual(profile.ConcealLevel, tt.concealLevel) {
+				t.Errorf("Expected conceallevel %v, got %v", tt.concealLevel, profile.ConcealLevel)
+			}
+			if profile.Filetype != tt.filetype {
+				t.Errorf("Expected filetype %q, got %q", tt.filetype, profile.Filetype)
+			}
+		})
+	}
+}
+
+func TestProfile_Merge(t *testing.T) {
+	spell := true
+	base := DefaultProfile("obsidian")
+	merged := base.Merge(Profile{Command: "nvim", Spell: &spell})
+
+	if merged.Command != "nvim" {
+		t.Errorf("Expected command 'nvim', got %q", merged.Command)
+	}
+	if merged.ConcealLevel == nil || *merged.ConcealLevel != 2 {
+		t.Errorf("Expected default conceallevel 2 to be kept, got %v", merged.ConcealLevel)
+	}
+	if merged.Spell == nil || !*merged.Spell {
+		t.Errorf("Expected spell to be enabled, got %v", merged.Spell)
+	}
+	if base.Spell != nil {
+		t.Error("Merge must not modify the base profile")
+	}
+
+	merged = base.Merge(Profile{ConcealLevel: intPtr(0)})
+	if *merged.ConcealLevel != 0 {
+		t.Errorf("Expected explicit conceallevel 0 to override, got %d", *merged.ConcealLevel)
+	}
+}
+
+func TestProfile_ResolvedCommand(t *testing.T) {
+	t.Setenv("VISUAL", "code --wait")
+	t.Setenv("EDITOR", "vi")
+	if got := (Profile{Command: "hx"}).ResolvedCommand(); got != "hx" {
+		t.Errorf("ResolvedCommand() = %q, want the profile command", got)
+	}
+	if got := (Profile{}).ResolvedCommand(); got != "code --wait" {
+		t.Errorf("ResolvedCommand() = %q, want $VISUAL", got)
+	}
+}
+
+func TestProfile_EditorArgs(t *testing.T) {
+	wrap := false
+	profile := Profile{
+		ConcealLevel: intPtr(2),
+		Filetype:     "markdown",
+		Wrap:         &wrap,
+		Args:         []string{"--extra"},
+	}
+
+	tests := []struct {
+		name     string
+		editor   string
+		file     string
+		expected []string
+	}{
+		{"nvim", "nvim", "note.md", []string{"-c", "setlocal filetype=markdown conceallevel=2 nowrap", "--extra"}},
+		{"vim by path", "/usr/bin/vim", "draw.excalidraw.md", []string{"-c", "setlocal filetype=markdown conceallevel=2 nowrap", "--extra"}},
+		{"canvas", "vim", "board.canvas", []string{"-c", "setlocal nowrap", "--extra"}},
+		{"other editor", "code", "note.md", []string{"--extra"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profile.EditorArgs(tt.editor, tt.file); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("EditorArgs(%q, %q) = %q, expected %q", tt.editor, tt.file, got, tt.expected)
+			}
+		})
+	}
+
+	if got := (Profile{ConcealLevel: intPtr(2)}).EditorArgs("vim", "notes.txt"); len(got) != 0 {
+		t.Errorf("Expected no arguments for a text file, got %q", got)
+	}
+	if got := (Profile{}).EditorArgs("vim", "note.md"); len(got) != 0 {
+		t.Errorf("Expected no arguments for an empty profile, got %q", got)
+	}
+}
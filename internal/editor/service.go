@@ -1,29 +1,41 @@
 package editor
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
-// Service interface for editor operations
+// Service interface for editor operations. A cancelled ctx keeps an editor
+// from starting, but never closes one the user is working in.
 type Service interface {
-	OpenFile(filePath string) error
+	OpenFile(ctx context.Context, filePath string) error
+	OpenFileAt(ctx context.Context, filePath string, line int) error
 }
 
 // RealService handles real editor operations
-type RealService struct{}
+type RealService struct {
+	profile Profile
+}
 
 // MockService handles mock editor operations for testing
 type MockService struct {
 	OpenedFiles []string
+	OpenedLines []int
 	ConcealLevel int
 	Error error
 }
 
-// NewService creates a new real editor service
-func NewService() Service {
-	return &RealService{}
+// NewService creates a new real editor service for the active profile.
+// A non-empty profile command (e.g. "code --wait") takes precedence over
+// $VISUAL and $EDITOR.
+func NewService(profile Profile) Service {
+	return &RealService{profile: profile}
 }
 
 // NewMockService creates a new mock editor service
@@ -36,9 +48,45 @@ func NewMockService(openedFiles []string, concealLevel int, err error) Service {
 }
 
 // OpenFile opens a file in the user's preferred editor
-func (s *RealService) OpenFile(filePath string) error {
-	// Get editor from environment or use fallback
-	editor := os.Getenv("EDITOR")
+func (s *RealService) OpenFile(ctx context.Context, filePath string) error {
+	return s.OpenFileAt(ctx, filePath, 0)
+}
+
+// OpenFileAt opens a file with the cursor on the given 1-based line, for
+// editors that support it. A line of 0 opens the file normally.
+func (s *RealService) OpenFileAt(ctx context.Context, filePath string, line int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	argv, err := s.editorCommand(isTerminal(os.Stdin) && isTerminal(os.Stdout), filePath)
+	if err != nil {
+		return err
+	}
+
+	// Run the editor with the file, without tying it to ctx: it may hold
+	// unsaved changes
+	cmd := exec.Command(argv[0], append(argv[1:], lineArgs(argv[0], filePath, line)...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := diag.Run(ctx, cmd); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return errs.New(errs.ErrEditorFailed, "editor %s exited with status %d", argv[0], exitErr.ExitCode())
+		}
+		if errors.Is(err, exec.ErrNotFound) {
+			return errs.New(errs.ErrToolMissing, "editor %s not found: %w", argv[0], err)
+		}
+		return errs.New(errs.ErrEditorFailed, "failed to run editor %s: %w", argv[0], err)
+	}
+	return nil
+}
+
+// editorCommand resolves the editor command line to open filePath with,
+// without the file
+func (s *RealService) editorCommand(tty bool, filePath string) ([]string, error) {
+	editor := resolveEditor(s.profile.Command, tty)
 	if editor == "" {
 		// Try common editors in order of preference
 		editors := []string{"edit", "vim", "nano", "emacs"}
@@ -49,21 +97,27 @@ func (s *RealService) OpenFile(filePath string) error {
 			}
 		}
 		if editor == "" {
-			return fmt.Errorf("no editor found. Please set $EDITOR or install vim/nano/emacs")
+			return nil, errs.New(errs.ErrToolMissing, "no editor found. Please set $EDITOR or install vim/nano/emacs")
 		}
 	}
 
-	// Run the editor with the file
-	cmd := exec.Command(editor, filePath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	argv, err := splitCommand(editor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor command %q: %w", editor, err)
+	}
+	if len(argv) == 0 {
+		return nil, fmt.Errorf("invalid editor command %q: empty command", editor)
+	}
 
-	return cmd.Run()
+	argv = withWaitFlag(argv)
+	return append(argv, s.profile.EditorArgs(argv[0], filePath)...), nil
 }
 
 // OpenFile mock implementation
-func (s *MockService) OpenFile(filePath string) error {
+func (s *MockService) OpenFile(ctx context.Context, filePath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if s.Error != nil {
 		return s.Error
 	}
@@ -71,4 +125,13 @@ func (s *MockService) OpenFile(filePath string) error {
 	// Add to opened files list
 	s.OpenedFiles = append(s.OpenedFiles, filePath)
 	return nil
+}
+
+// OpenFileAt mock implementation
+func (s *MockService) OpenFileAt(ctx context.Context, filePath string, line int) error {
+	if err := s.OpenFile(ctx, filePath); err != nil {
+		return err
+	}
+	s.OpenedLines = append(s.OpenedLines, line)
+	return nil
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package editor
 
 import (
+	"context"
 	"errors"
 	"testing"
 )
@@ -57,7 +58,7 @@ func TestMockService_OpenFile(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			service := NewMockService(tt.openedFiles, tt.concealLevel, tt.error)
 			
-			err := service.OpenFile(tt.filePath)
+			err := service.OpenFile(context.Background(), tt.filePath)
 			
 			if (err != nil) != (tt.expectedError != nil) {
 				t.Errorf("Expected error %v, got %v", tt.expectedError, err)
@@ -88,7 +89,7 @@ func TestMockService_EditorRespect(t *testing.T) {
 	mockService := service.(*MockService)
 	
 	// Test opening a file
-	err := service.OpenFile("test.md")
+	err := service.OpenFile(context.Background(), "test.md")
 	if err != nil {
 		t.Errorf("OpenFile failed: %v", err)
 	}
@@ -100,4 +101,19 @@ func TestMockService_EditorRespect(t *testing.T) {
 	if mockService.OpenedFiles[0] != "test.md" {
 		t.Errorf("Expected opened file 'test.md', got %s", mockService.OpenedFiles[0])
 	}
-}
\ No newline at end of file
+}
+
+func TestMockService_OpenFileAt(t *testing.T) {
+	service := NewMockService([]string{}, 0, nil)
+	mockService := service.(*MockService)
+
+	if err := service.OpenFileAt(context.Background(), "tasks.md", 42); err != nil {
+		t.Fatalf("OpenFileAt failed: %v", err)
+	}
+	if len(mockService.OpenedFiles) != 1 || mockService.OpenedFiles[0] != "tasks.md" {
+		t.Errorf("Expected opened files [tasks.md], got %v", mockService.OpenedFiles)
+	}
+	if len(mockService.OpenedLines) != 1 || mockService.OpenedLines[0] != 42 {
+		t.Errorf("Expected opened lines [42], got %v", mockService.OpenedLines)
+	}
+}
@@ -0,0 +1,38 @@
+// Package errs defines the kinds of failure ob-cli reports to scripts.
+// Services and the app wrap errors with these kinds, and main maps each
+// kind to a documented exit code.
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of failure
+var (
+	ErrVaultNotFound = errors.New("vault not found")
+	ErrToolMissing   = errors.New("required tool not found")
+	ErrCancelled     = errors.New("cancelled")
+	ErrGitConflict   = errors.New("git conflict")
+	ErrInvalidPath   = errors.New("invalid path")
+	ErrEditorFailed  = errors.New("editor failed")
+	ErrUsage         = errors.New("invalid arguments")
+	ErrHookFailed    = errors.New("hook failed")
+)
+
+// New formats an error like fmt.Errorf that is also of kind, so errors.Is
+// matches kind without it appearing in the message
+func New(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Is(target error) bool { return target == e.kind }
+
+func (e *kindError) Unwrap() error { return e.err }
@@ -0,0 +1,25 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	err := New(ErrInvalidPath, "note %s not found: %w", "a.md", fs.ErrNotExist)
+
+	if got := err.Error(); got != "note a.md not found: file does not exist" {
+		t.Errorf("Error() = %q", got)
+	}
+	wrapped := fmt.Errorf("restore failed: %w", err)
+	for _, target := range []error{ErrInvalidPath, fs.ErrNotExist, err} {
+		if !errors.Is(wrapped, target) {
+			t.Errorf("errors.Is(%v) = false", target)
+		}
+	}
+	if errors.Is(wrapped, ErrVaultNotFound) {
+		t.Error("errors.Is(ErrVaultNotFound) = true")
+	}
+}
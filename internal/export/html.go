@@ -0,0 +1,347 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// Options controls a static HTML export
+type Options struct {
+	Out   string // Output directory
+	Path  string // Only export notes under this vault-relative folder
+	Title string // Site title shown on every page
+}
+
+// Result summarises an export
+type Result struct {
+	Pages  int // Notes rendered
+	Assets int // Attachments copied
+}
+
+// page is a note being exported
+type page struct {
+	file      string // Vault-relative note path
+	out       string // Slash-separated output path relative to the site root
+	title     string
+	tags      []string
+	content   string
+	backlinks []*page
+}
+
+var imageExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true,
+	".svg": true, ".webp": true, ".bmp": true, ".avif": true,
+}
+
+// HTML renders the given vault-relative notes as a static site in
+// opts.Out. Wikilinks and markdown links become relative links, embedded
+// files are copied next to the pages, every page lists its backlinks, and
+// tags.html indexes all tags. Notes with "publish: false" frontmatter are
+// skipped and links to them are rendered as plain text. Output only
+// depends on the vault contents, so repeated exports are identical.
+func HTML(notesDir string, files []string, opts Options) (Result, error) {
+	if opts.Out == "" {
+		return Result{}, fmt.Errorf("no output directory given")
+	}
+	if opts.Title == "" {
+		opts.Title = filepath.Base(notesDir)
+	}
+	files = append([]string(nil), files...)
+	sort.Strings(files)
+
+	attachments, err := frecency.ListAttachments(notesDir)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to list attachments: %w", err)
+	}
+	resolver := markdown.NewResolver(append(append([]string(nil), files...), attachments...))
+
+	pages, order, err := loadPages(notesDir, files, opts.Path)
+	if err != nil {
+		return Result{}, err
+	}
+	linkBacklinks(pages, order, resolver)
+
+	assets := map[string]bool{}
+	for _, p := range order {
+		if err := writePage(opts, p, pages, resolver, assets); err != nil {
+			return Result{}, err
+		}
+	}
+	if err := writeIndex(opts, order); err != nil {
+		return Result{}, err
+	}
+	if err := writeTags(opts, order); err != nil {
+		return Result{}, err
+	}
+
+	copied := 0
+	for _, asset := range sortedKeys(assets) {
+		if err := copyFile(filepath.Join(notesDir, asset), filepath.Join(opts.Out, asset)); err != nil {
+			return Result{}, fmt.Errorf("failed to copy %s: %w", asset, err)
+		}
+		copied++
+	}
+
+	return Result{Pages: len(order), Assets: copied}, nil
+}
+
+// loadPages reads the notes to publish, keyed by vault-relative path
+func loadPages(notesDir string, files []string, folder string) (map[string]*page, []*page, error) {
+	folder = filepath.ToSlash(filepath.Clean(folder))
+	pages := map[string]*page{}
+	var order []*page
+
+	for _, file := range files {
+		slashed := filepath.ToSlash(file)
+		if folder != "." && slashed != folder && !strings.HasPrefix(slashed, folder+"/") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(notesDir, file))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		content := string(data)
+		fm, _ := markdown.Frontmatter(content)
+		if !publishable(fm) {
+			continue
+		}
+
+		p := &page{
+			file:    file,
+			out:     strings.TrimSuffix(slashed, ".md") + ".html",
+			title:   markdown.Title(file, content),
+			tags:    markdown.NoteTags(content),
+			content: content,
+		}
+		pages[file] = p
+		order = append(order, p)
+	}
+	return pages, order, nil
+}
+
+// publishable reports whether frontmatter allows publishing a note
+func publishable(fm map[string]interface{}) bool {
+	switch v := fm["publish"].(type) {
+	case bool:
+		return v
+	case string:
+		return !strings.EqualFold(strings.TrimSpace(v), "false")
+	}
+	return true
+}
+
+// linkBacklinks records on every page the other pages that link to it
+func linkBacklinks(pages map[string]*page, order []*page, resolver *markdown.Resolver) {
+	seen := map[[2]*page]bool{}
+	for _, p := range order {
+		for _, link := range markdown.ParseLinks(p.content) {
+			file, ok := resolver.Resolve(p.file, link.Target)
+			target := pages[file]
+			if !ok || target == nil || target == p || seen[[2]*page{p, target}] {
+				continue
+			}
+			seen[[2]*page{p, target}] = true
+			target.backlinks = append(target.backlinks, p)
+		}
+	}
+}
+
+// writePage renders a note and its backlinks to its output file, noting
+// the attachments it embeds or links to
+func writePage(opts Options, p *page, pages map[string]*page, resolver *markdown.Resolver, assets map[string]bool) error {
+	body := markdown.ReplaceLinks(markdown.Body(p.content), func(link markdown.Link) string {
+		return rewriteLink(p, link, pages, resolver, assets)
+	})
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{seen: map[string]int{}}))
+	var content bytes.Buffer
+	if err := md.Convert([]byte(body), &content, parser.WithContext(ctx)); err != nil {
+		return fmt.Errorf("failed to render %s: %w", p.file, err)
+	}
+
+	data := pageData{
+		SiteTitle: opts.Title,
+		Title:     p.title,
+		Heading:   !strings.HasPrefix(strings.TrimSpace(body), "# "),
+		Root:      relURL(p.out, "index.html"),
+		Content:   template.HTML(content.String()),
+	}
+	for _, tag := range p.tags {
+		data.Tags = append(data.Tags, linkData{Text: "#" + tag, Href: relURL(p.out, "tags.html") + "#" + tagID(tag)})
+	}
+	backlinks := append([]*page(nil), p.backlinks...)
+	sort.Slice(backlinks, func(i, j int) bool { return backlinks[i].file < backlinks[j].file })
+	for _, b := range backlinks {
+		data.Backlinks = append(data.Backlinks, linkData{Text: b.title, Href: relURL(p.out, b.out)})
+	}
+
+	return writeTemplate(filepath.Join(opts.Out, filepath.FromSlash(p.out)), pageTemplate, data)
+}
+
+// rewriteLink turns a note link into a markdown link to the exported page
+// or attachment, or plain text when the target is missing or unpublished
+func rewriteLink(p *page, link markdown.Link, pages map[string]*page, resolver *markdown.Resolver, assets map[string]bool) string {
+	text := link.Alias
+	if text == "" {
+		text = strings.TrimSuffix(link.Target, ".md")
+		if link.Fragment != "" {
+			text = strings.TrimPrefix(text+" > "+link.Fragment, " > ")
+		}
+	}
+
+	file, ok := resolver.Resolve(p.file, link.Target)
+	if !ok {
+		return escapeText(text)
+	}
+
+	if target, isNote := pages[file]; isNote {
+		href := ""
+		if target != p || link.Target != "" {
+			href = relURL(p.out, target.out)
+		}
+		if link.Fragment != "" && !strings.HasPrefix(link.Fragment, "^") {
+			href += "#" + headingID(link.Fragment)
+		}
+		return "[" + escapeText(text) + "](<" + href + ">)"
+	}
+	if strings.EqualFold(path.Ext(file), ".md") {
+		return escapeText(text) // Unpublished or outside the exported folder
+	}
+
+	assets[file] = true
+	href := relURL(p.out, filepath.ToSlash(file))
+	if link.Embed && imageExts[strings.ToLower(path.Ext(file))] {
+		alt := link.Alias
+		if isImageSize(alt) {
+			alt = ""
+		}
+		return "![" + escapeText(alt) + "](<" + href + ">)"
+	}
+	return "[" + escapeText(text) + "](<" + href + ">)"
+}
+
+// isImageSize reports whether an embed alias is an Obsidian image size
+// such as "300" or "300x200" rather than alt text
+func isImageSize(alias string) bool {
+	if alias == "" {
+		return false
+	}
+	return strings.Trim(alias, "0123456789x") == ""
+}
+
+// headingIDs generates heading anchors that headingID-based links can target
+type headingIDs struct {
+	seen map[string]int
+}
+
+func (ids *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	id := headingID(string(value))
+	if id == "" {
+		id = "heading"
+	}
+	n := ids.seen[id]
+	ids.seen[id]++
+	if n > 0 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return []byte(id)
+}
+
+func (ids *headingIDs) Put(value []byte) {
+	ids.seen[string(value)]++
+}
+
+// headingID turns heading text into an anchor: lower-case letters and
+// digits with runs of other characters collapsed to '-'
+func headingID(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		case unicode.IsSpace(r) || r == '-' || r == '_' || r == '/':
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+func tagID(tag string) string {
+	return "tag-" + headingID(tag)
+}
+
+// relURL returns the URL of the site-relative path "to" as seen from the
+// page at "from"
+func relURL(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		rel = to
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeText escapes markdown punctuation in link text
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`, "*", `\*`, "_", `\_`, "`", "\\`", "<", `\<`).Replace(s)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
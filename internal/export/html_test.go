@@ -0,0 +1,152 @@
+package export
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeVault(t *testing.T, notes map[string]string) (string, []string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	var files []string
+	for name, content := range notes {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Ext(name) == ".md" {
+			files = append(files, name)
+		}
+	}
+	return dir, files
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestHTML(t *testing.T) {
+	notesDir, files := writeVault(t, map[string]string{
+		"Home.md":            "---\ntags: [work]\n---\n# Home\nSee [[Plan#Next Steps|the plan]], [[Secret]] and [[Missing]].\n\n![[cat.png|200]]\n",
+		"projects/Plan.md":   "# Plan\n## Next Steps\nBack [home](../Home.md) #work/q1\n",
+		"Secret.md":          "---\npublish: false\n---\nLinks to [[Home]]\n",
+		"img/cat.png":        "png",
+		"img/unused.png":     "png",
+		".obsidian/app.json": "{}",
+	})
+	out := filepath.Join(t.TempDir(), "site")
+
+	result, err := HTML(notesDir, files, Options{Out: out, Title: "Tips"})
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if result.Pages != 2 || result.Assets != 1 {
+		t.Errorf("HTML() = %+v, expected 2 pages and 1 asset", result)
+	}
+
+	home := readFile(t, filepath.Join(out, "Home.html"))
+	for _, want := range []string{
+		`<a href="projects/Plan.html#next-steps">the plan</a>`,
+		"Secret and Missing.",
+		`<img src="img/cat.png" alt="">`,
+		`<a href="tags.html#tag-work">#work</a>`,
+		`<li><a href="projects/Plan.html">Plan</a></li>`, // Backlink
+	} {
+		if !strings.Contains(home, want) {
+			t.Errorf("Home.html missing %q:\n%s", want, home)
+		}
+	}
+
+	plan := readFile(t, filepath.Join(out, "projects", "Plan.html"))
+	for _, want := range []string{`<h2 id="next-steps">Next Steps</h2>`, `<a href="../Home.html">home</a>`, `href="../index.html"`} {
+		if !strings.Contains(plan, want) {
+			t.Errorf("Plan.html missing %q:\n%s", want, plan)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "Secret.html")); !os.IsNotExist(err) {
+		t.Error("unpublished note was exported")
+	}
+	if _, err := os.Stat(filepath.Join(out, "img", "unused.png")); !os.IsNotExist(err) {
+		t.Error("unreferenced attachment was copied")
+	}
+
+	tags := readFile(t, filepath.Join(out, "tags.html"))
+	if !strings.Contains(tags, `<h2 id="tag-work">#work</h2>`) || !strings.Contains(tags, `<h2 id="tag-work-q1">#work/q1</h2>`) {
+		t.Errorf("tags.html missing tag sections:\n%s", tags)
+	}
+	if index := readFile(t, filepath.Join(out, "index.html")); !strings.Contains(index, `<a href="projects/Plan.html">Plan</a>`) {
+		t.Errorf("index.html missing Plan:\n%s", index)
+	}
+}
+
+func TestHTML_Deterministic(t *testing.T) {
+	notesDir, files := writeVault(t, map[string]string{
+		"a.md": "# A\n[[b]] [[c]] #x\n",
+		"b.md": "# B\n[[a]] #y\n",
+		"c.md": "# C\n[[a]] [[b]] #x #y\n",
+	})
+
+	render := func(files []string) map[string]string {
+		out := t.TempDir()
+		if _, err := HTML(notesDir, files, Options{Out: out}); err != nil {
+			t.Fatalf("HTML() error = %v", err)
+		}
+		pages := map[string]string{}
+		for _, name := range []string{"a.html", "b.html", "c.html", "index.html", "tags.html"} {
+			pages[name] = readFile(t, filepath.Join(out, name))
+		}
+		return pages
+	}
+
+	first := render(files)
+	second := render([]string{files[2], files[0], files[1]})
+	for name, content := range first {
+		if second[name] != content {
+			t.Errorf("%s differs between exports", name)
+		}
+	}
+}
+
+func TestHTML_Path(t *testing.T) {
+	notesDir, files := writeVault(t, map[string]string{
+		"Home.md":          "[[Plan]]\n",
+		"projects/Plan.md": "[[Home]]\n",
+	})
+	out := t.TempDir()
+
+	result, err := HTML(notesDir, files, Options{Out: out, Path: "projects"})
+	if err != nil {
+		t.Fatalf("HTML() error = %v", err)
+	}
+	if result.Pages != 1 {
+		t.Errorf("HTML() exported %d pages, expected 1", result.Pages)
+	}
+	if plan := readFile(t, filepath.Join(out, "projects", "Plan.html")); strings.Contains(plan, "Home.html") {
+		t.Errorf("link to note outside the exported folder was kept:\n%s", plan)
+	}
+}
+
+func TestHeadingID(t *testing.T) {
+	tests := map[string]string{
+		"Next Steps":       "next-steps",
+		"  Q&A: 2024  ":    "qa-2024",
+		"work/q1":          "work-q1",
+		"Café au lait":     "café-au-lait",
+		"--already-dashed": "already-dashed",
+	}
+	for text, expected := range tests {
+		if got := headingID(text); got != expected {
+			t.Errorf("headingID(%q) = %q, expected %q", text, got, expected)
+		}
+	}
+}
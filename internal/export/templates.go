@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// pageData is the input of pageTemplate
+type pageData struct {
+	SiteTitle string
+	Title     string
+	Heading   bool // Show Title as a heading; false when the note starts with one
+	Root      string
+	Content   template.HTML
+	Tags      []linkData
+	Backlinks []linkData
+}
+
+// listData is the input of indexTemplate and tagsTemplate
+type listData struct {
+	SiteTitle string
+	Title     string
+	Root      string
+	Groups    []groupData
+}
+
+type groupData struct {
+	ID    string
+	Name  string
+	Links []linkData
+}
+
+type linkData struct {
+	Text string
+	Href string
+	Note string // Secondary text such as the folder
+}
+
+const layout = `{{define "head"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}} - {{.SiteTitle}}</title>
+<style>
+body{max-width:46rem;margin:0 auto;padding:1rem;font:16px/1.6 system-ui,sans-serif;color:#222}
+nav{border-bottom:1px solid #ddd;margin-bottom:1rem;padding-bottom:.5rem}
+nav a{margin-right:1rem}
+a{color:#5a3fc0}
+pre,code{background:#f5f5f5;border-radius:3px}
+pre{padding:.75rem;overflow:auto}
+img{max-width:100%}
+.tags a{margin-right:.5rem}
+.muted{color:#777}
+footer{border-top:1px solid #ddd;margin-top:2rem}
+</style>
+</head>
+<body>
+<nav><a href="{{.Root}}">{{.SiteTitle}}</a><a href="{{.Root | tags}}">Tags</a></nav>
+{{end}}`
+
+var funcs = template.FuncMap{
+	"tags": func(root string) string { return strings.TrimSuffix(root, "index.html") + "tags.html" },
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(funcs).Parse(layout + `{{template "head" .}}<main>
+{{if .Heading}}<h1>{{.Title}}</h1>
+{{end}}{{if .Tags}}<p class="tags">{{range .Tags}}<a href="{{.Href}}">{{.Text}}</a>{{end}}</p>
+{{end}}{{.Content}}</main>
+{{if .Backlinks}}<footer>
+<h2>Backlinks</h2>
+<ul>
+{{range .Backlinks}}<li><a href="{{.Href}}">{{.Text}}</a></li>
+{{end}}</ul>
+</footer>
+{{end}}</body>
+</html>
+`))
+
+var listTemplate = template.Must(template.New("list").Funcs(funcs).Parse(layout + `{{template "head" .}}<main>
+<h1>{{.Title}}</h1>
+{{range .Groups}}{{if .Name}}<h2 id="{{.ID}}">{{.Name}}</h2>
+{{end}}<ul>
+{{range .Links}}<li><a href="{{.Href}}">{{.Text}}</a>{{if .Note}} <span class="muted">{{.Note}}</span>{{end}}</li>
+{{end}}</ul>
+{{end}}</main>
+</body>
+</html>
+`))
+
+// writeIndex writes index.html listing every page by folder
+func writeIndex(opts Options, pages []*page) error {
+	data := listData{SiteTitle: opts.Title, Title: opts.Title, Root: "index.html"}
+
+	groups := map[string]*groupData{}
+	var names []string
+	for _, p := range pages {
+		dir := path.Dir(p.out)
+		if dir == "." {
+			dir = ""
+		}
+		g, ok := groups[dir]
+		if !ok {
+			g = &groupData{ID: "folder-" + headingID(dir), Name: dir}
+			groups[dir] = g
+			names = append(names, dir)
+		}
+		g.Links = append(g.Links, linkData{Text: p.title, Href: relURL("index.html", p.out)})
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data.Groups = append(data.Groups, *groups[name])
+	}
+
+	return writeTemplate(filepath.Join(opts.Out, "index.html"), listTemplate, data)
+}
+
+// writeTags writes tags.html listing the pages of every tag
+func writeTags(opts Options, pages []*page) error {
+	data := listData{SiteTitle: opts.Title, Title: "Tags", Root: "index.html"}
+
+	byTag := map[string]*groupData{}
+	var keys []string
+	for _, p := range pages {
+		for _, tag := range p.tags {
+			key := strings.ToLower(tag)
+			g, ok := byTag[key]
+			if !ok {
+				g = &groupData{ID: tagID(tag), Name: "#" + tag}
+				byTag[key] = g
+				keys = append(keys, key)
+			}
+			g.Links = append(g.Links, linkData{Text: p.title, Href: relURL("tags.html", p.out), Note: p.file})
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		data.Groups = append(data.Groups, *byTag[key])
+	}
+
+	return writeTemplate(filepath.Join(opts.Out, "tags.html"), listTemplate, data)
+}
+
+// writeTemplate renders a template to a file, creating parent directories
+func writeTemplate(file string, tmpl *template.Template, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", file, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(file, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return nil
+}
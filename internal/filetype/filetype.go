@@ -0,0 +1,158 @@
+// Package filetype sorts vault files into the types ob-cli lists and opens
+// differently: markdown notes, Excalidraw drawings, canvases, images, PDFs
+// and everything else.
+package filetype
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Types
+const (
+	Markdown   = "md"
+	Excalidraw = "excalidraw" // Excalidraw drawings saved as .excalidraw.md
+	Canvas     = "canvas"     // Obsidian canvas JSON
+	Image      = "image"
+	PDF        = "pdf"
+	Other      = "other"
+)
+
+// Names lists every type, in the order markers are documented
+var Names = []string{Markdown, Excalidraw, Canvas, Image, PDF, Other}
+
+var imageExts = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true,
+	".webp": true, ".bmp": true, ".avif": true, ".ico": true, ".tif": true, ".tiff": true,
+}
+
+// Of returns the type of a file from its name. Like the rest of ob-cli,
+// only the lowercase .md extension makes a note; other extensions are
+// matched in any case.
+func Of(path string) string {
+	name := filepath.Base(path)
+	switch ext := strings.ToLower(filepath.Ext(name)); {
+	case strings.HasSuffix(name, ".excalidraw.md"):
+		return Excalidraw
+	case filepath.Ext(name) == ".md":
+		return Markdown
+	case ext == ".canvas":
+		return Canvas
+	case ext == ".pdf":
+		return PDF
+	case imageExts[ext]:
+		return Image
+	default:
+		return Other
+	}
+}
+
+// Binary reports whether a file is opened outside the editor. Files of
+// the Other type are binary when their first bytes aren't UTF-8 text; a
+// file that can't be read isn't.
+func Binary(path string) bool {
+	switch Of(path) {
+	case Image, PDF:
+		return true
+	case Other:
+		f, err := os.Open(path)
+		if err != nil {
+			return false
+		}
+		defer f.Close()
+		head := make([]byte, 512)
+		n, _ := f.Read(head)
+		head = head[:n]
+		return bytes.IndexByte(head, 0) >= 0 || !utf8.Valid(trimPartialRune(head))
+	default:
+		return false
+	}
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence at the end of b, where
+// reading the start of a file may cut a character off
+func trimPartialRune(b []byte) []byte {
+	for i := 1; i < utf8.UTFMax && i <= len(b); i++ {
+		if utf8.RuneStart(b[len(b)-i]) {
+			if !utf8.FullRune(b[len(b)-i:]) {
+				return b[:len(b)-i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// Set is a set of types. The nil Set is Default.
+type Set map[string]bool
+
+// Default is what ob-cli lists without --type: markdown notes, including
+// Excalidraw drawings
+var Default = Set{Markdown: true, Excalidraw: true}
+
+// All holds every type
+var All = Set{Markdown: true, Excalidraw: true, Canvas: true, Image: true, PDF: true, Other: true}
+
+// Parse parses a comma-separated list of types, such as "md,canvas,pdf".
+// "all" stands for every type.
+func Parse(list string) (Set, error) {
+	set := Set{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+			continue
+		case "all":
+			return All, nil
+		case "markdown":
+			name = Markdown
+		case "images":
+			name = Image
+		}
+		if !All[name] {
+			return nil, fmt.Errorf("unknown file type %q (want %s or all)", name, strings.Join(Names, ", "))
+		}
+		set[name] = true
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no file types in %q", list)
+	}
+	return set, nil
+}
+
+// Has reports whether the type of the file at path is in the set
+func (s Set) Has(path string) bool {
+	if s == nil {
+		s = Default
+	}
+	return s[Of(path)]
+}
+
+// NotesOnly reports whether the set holds only markdown files, which are
+// the notes every other part of ob-cli works with
+func (s Set) NotesOnly() bool {
+	for name := range s {
+		if name != Markdown && name != Excalidraw {
+			return false
+		}
+	}
+	return true
+}
+
+// String lists the set's types, sorted
+func (s Set) String() string {
+	if s == nil {
+		s = Default
+	}
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,98 @@
+package filetype
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	tests := map[string]string{
+		"note.md":                     Markdown,
+		"dir/Note.MD":                 Other,
+		"drawings/plan.excalidraw.md": Excalidraw,
+		"board.canvas":                Canvas,
+		"img/cat.PNG":                 Image,
+		"img/logo.svg":                Image,
+		"docs/spec.pdf":               PDF,
+		"data.csv":                    Other,
+		"Makefile":                    Other,
+	}
+	for path, want := range tests {
+		if got := Of(path); got != want {
+			t.Errorf("Of(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"notes.txt":  []byte("plain text ✓"),
+		"data.bin":   {0x00, 0x01, 0x02},
+		"latin1.txt": {'c', 'a', 'f', 0xe9, ' '},
+		"cut.txt":    append(make([]byte, 511, 512), 0xe2), // A rune cut off at 512 bytes
+	}
+	for i := range files["cut.txt"][:511] {
+		files["cut.txt"][i] = 'a'
+	}
+	for name, data := range files {
+		os.WriteFile(filepath.Join(dir, name), data, 0644)
+	}
+
+	tests := map[string]bool{
+		"notes.txt":    false,
+		"data.bin":     true,
+		"latin1.txt":   true,
+		"cut.txt":      false,
+		"missing.txt":  false,
+		"cat.png":      true,
+		"spec.pdf":     true,
+		"board.canvas": false,
+		"note.md":      false,
+	}
+	for name, want := range tests {
+		if got := Binary(filepath.Join(dir, name)); got != want {
+			t.Errorf("Binary(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		list    string
+		want    string
+		wantErr bool
+	}{
+		{"md,canvas,pdf", "canvas,md,pdf", false},
+		{" markdown , images ", "image,md", false},
+		{"all", All.String(), false},
+		{"md,all", All.String(), false},
+		{"md,docx", "", true},
+		{",", "", true},
+	}
+	for _, tt := range tests {
+		set, err := Parse(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if err == nil && set.String() != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.list, set, tt.want)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	var defaults Set
+	if !defaults.Has("a.md") || !defaults.Has("b.excalidraw.md") || defaults.Has("c.canvas") {
+		t.Error("the nil set isn't Default")
+	}
+	if !defaults.NotesOnly() || !Default.NotesOnly() || All.NotesOnly() {
+		t.Error("NotesOnly() is wrong")
+	}
+	set := Set{Canvas: true, PDF: true}
+	if set.Has("a.md") || !set.Has("c.canvas") || !set.Has("d.pdf") || set.NotesOnly() {
+		t.Errorf("set %s is wrong", set)
+	}
+}
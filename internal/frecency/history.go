@@ -0,0 +1,141 @@
+package frecency
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxTotalRank bounds the history: once the ranks add up to more than
+// this, every rank is aged and forgotten entries are dropped
+const maxTotalRank = 1000
+
+// entry is the access history of a single file
+type entry struct {
+	Rank       float64   `json:"rank"`
+	LastAccess time.Time `json:"last_access"`
+}
+
+// history persists access counts per vault under the user's state directory
+type history struct {
+	path string
+}
+
+func newHistory(notesDir string) *history {
+	abs, err := filepath.Abs(notesDir)
+	if err != nil {
+		abs = notesDir
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := hex.EncodeToString(sum[:8]) + ".json"
+	return &history{path: filepath.Join(stateDir(), "ob-cli", "frecency", name)}
+}
+
+// stateDir returns $XDG_STATE_HOME, defaulting to ~/.local/state
+func stateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state")
+	}
+	return os.TempDir()
+}
+
+// load reads the history; a missing history file is empty
+func (h *history) load() (map[string]entry, error) {
+	entries := map[string]entry{}
+	data, err := os.ReadFile(h.path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frecency history: %w", err)
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		// A corrupt history is not worth failing over; start afresh
+		return map[string]entry{}, nil
+	}
+	return entries, nil
+}
+
+// record bumps the rank of a file and saves the history atomically
+func (h *history) record(file string, now time.Time) error {
+	entries, err := h.load()
+	if err != nil {
+		return err
+	}
+
+	e := entries[file]
+	e.Rank++
+	e.LastAccess = now
+	entries[file] = e
+	age(entries)
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return fmt.Errorf("failed to save frecency history: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(h.path), ".frecency-*")
+	if err != nil {
+		return fmt.Errorf("failed to save frecency history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save frecency history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save frecency history: %w", err)
+	}
+	return os.Rename(tmp.Name(), h.path)
+}
+
+// age scales ranks down once they grow too large, like zoxide
+func age(entries map[string]entry) {
+	var total float64
+	for _, e := range entries {
+		total += e.Rank
+	}
+	if total <= maxTotalRank {
+		return
+	}
+	for name, e := range entries {
+		e.Rank *= 0.9
+		if e.Rank < 1 {
+			delete(entries, name)
+			continue
+		}
+		entries[name] = e
+	}
+}
+
+// score combines access history with modification time. Both are weighted
+// by how recent they are: within the hour, day, week or longer ago.
+func score(e entry, modTime, now time.Time) float64 {
+	s := recencyWeight(now.Sub(modTime))
+	if e.Rank > 0 {
+		s += e.Rank * recencyWeight(now.Sub(e.LastAccess))
+	}
+	return s
+}
+
+func recencyWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 4
+	case age < 24*time.Hour:
+		return 2
+	case age < 7*24*time.Hour:
+		return 0.5
+	default:
+		return 0.25
+	}
+}
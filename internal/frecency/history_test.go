@@ -0,0 +1,91 @@
+package frecency
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestService_RecordAccess(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tempDir := t.TempDir()
+
+	// Both files were modified a few minutes apart, well within the hour
+	now := time.Now()
+	for name, modTime := range map[string]time.Time{
+		"recent.md":  now.Add(-1 * time.Minute),
+		"picked.md":  now.Add(-5 * time.Minute),
+		"ancient.md": now.Add(-30 * 24 * time.Hour),
+	} {
+		path := filepath.Join(tempDir, name)
+		os.WriteFile(path, []byte(name), 0644)
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	service := NewService(tempDir)
+	if err := service.RecordAccess(context.Background(), "picked.md"); err != nil {
+		t.Fatalf("RecordAccess failed: %v", err)
+	}
+
+	sortedFiles, err := service.GetSortedFiles(context.Background())
+	if err != nil {
+		t.Fatalf("GetSortedFiles failed: %v", err)
+	}
+
+	expectedOrder := []string{"picked.md", "recent.md", "ancient.md"}
+	for i, expectedFile := range expectedOrder {
+		if sortedFiles[i] != expectedFile {
+			t.Errorf("Expected file %d to be %s, got %s", i, expectedFile, sortedFiles[i])
+		}
+	}
+
+	// History is kept per vault
+	other := NewService(t.TempDir()).(*RealService)
+	entries, err := other.history.load()
+	if err != nil {
+		t.Fatalf("Failed to load history: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected empty history for another vault, got %v", entries)
+	}
+}
+
+func TestScore(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		entry    entry
+		modTime  time.Time
+		expected float64
+	}{
+		{"modified this hour", entry{}, now.Add(-time.Minute), 4},
+		{"modified last month", entry{}, now.Add(-30 * 24 * time.Hour), 0.25},
+		{"accessed today", entry{Rank: 3, LastAccess: now.Add(-2 * time.Hour)}, now.Add(-30 * 24 * time.Hour), 0.25 + 3*2},
+		{"accessed this week", entry{Rank: 2, LastAccess: now.Add(-3 * 24 * time.Hour)}, now.Add(-2 * time.Hour), 2 + 2*0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := score(tt.entry, tt.modTime, now); got != tt.expected {
+				t.Errorf("score() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestAge(t *testing.T) {
+	entries := map[string]entry{
+		"big.md":   {Rank: maxTotalRank},
+		"small.md": {Rank: 1},
+	}
+	age(entries)
+
+	if _, ok := entries["small.md"]; ok {
+		t.Error("Expected low-ranked entry to be dropped")
+	}
+	if entries["big.md"].Rank != maxTotalRank*0.9 {
+		t.Errorf("Expected rank to be aged to %v, got %v", maxTotalRank*0.9, entries["big.md"].Rank)
+	}
+}
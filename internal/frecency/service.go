@@ -1,32 +1,66 @@
 package frecency
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"sort"
 	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/filetype"
+	"github.com/shalomb/ob-cli/internal/ignore"
+	"github.com/shalomb/ob-cli/internal/model"
 )
 
 // Service interface for file sorting operations
 type Service interface {
-	GetSortedFiles() ([]string, error)
+	GetSortedFiles(ctx context.Context) ([]string, error)
+	GetScoredFiles(ctx context.Context) ([]model.NoteInfo, error)
+	GetFiles(ctx context.Context, types filetype.Set) ([]model.NoteInfo, error)
+	RecordAccess(ctx context.Context, file string) error
+}
+
+// Lister lists a vault's notes without walking it, e.g. from a running
+// index daemon
+type Lister interface {
+	ListFiles(ctx context.Context) ([]model.NoteInfo, error)
 }
 
-// RealService handles real file sorting by access time
+// RealService handles real file sorting by modification time and access history
 type RealService struct {
 	notesDir string
+	history  *history
+	lister   Lister // Optional; the vault is walked when it fails
 }
 
 // MockService handles mock file sorting for testing
 type MockService struct {
-	Files []string
-	Error error
+	Files    []string
+	Error    error
+	Accessed []string
 }
 
 // NewService creates a new real frecency service
 func NewService(notesDir string) Service {
-	return &RealService{notesDir: notesDir}
+	return &RealService{
+		notesDir: notesDir,
+		history:  newHistory(notesDir),
+	}
+}
+
+// NewIndexedService creates a frecency service that asks lister for the
+// vault's notes and only walks the vault when lister fails
+func NewIndexedService(notesDir string, lister Lister) Service {
+	return &RealService{
+		notesDir: notesDir,
+		history:  newHistory(notesDir),
+		lister:   lister,
+	}
 }
 
 // NewMockService creates a new mock frecency service
@@ -37,73 +71,129 @@ func NewMockService(files []string, err error) Service {
 	}
 }
 
-// FileInfo represents a file with its modification time
-type FileInfo struct {
-	Name    string
-	ModTime time.Time
-}
-
-// GetSortedFiles returns files sorted by modification time (most recent first)
-func (s *RealService) GetSortedFiles() ([]string, error) {
-	var files []FileInfo
-	
-	err := s.walkDirectory(s.notesDir, &files)
+// GetSortedFiles returns files sorted by frecency (most relevant first).
+// Modification time counts as a visit, so files never opened through
+// ob-cli are still ordered by recency.
+func (s *RealService) GetSortedFiles(ctx context.Context) ([]string, error) {
+	files, err := s.GetScoredFiles(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk directory %s: %w", s.notesDir, err)
+		return nil, err
 	}
-	
-	// Sort by modification time (most recent first)
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].ModTime.After(files[j].ModTime)
-	})
-	
+
 	// Extract just the file names
 	result := make([]string, len(files))
 	for i, file := range files {
-		result[i] = file.Name
+		result[i] = file.Path
 	}
-	
+
 	return result, nil
 }
 
-// walkDirectory recursively walks a directory and collects markdown files
-func (s *RealService) walkDirectory(dirPath string, files *[]FileInfo) error {
+// GetScoredFiles returns files with their scores in GetSortedFiles order
+func (s *RealService) GetScoredFiles(ctx context.Context) ([]model.NoteInfo, error) {
+	return s.GetFiles(ctx, nil)
+}
+
+// GetFiles returns the vault's files of the given types, markdown notes
+// for a nil set, with their scores in GetSortedFiles order
+func (s *RealService) GetFiles(ctx context.Context, types filetype.Set) ([]model.NoteInfo, error) {
+	files, err := s.listFiles(ctx, types)
+	if err != nil {
+		return nil, err
+	}
+	
+	entries, err := s.history.load()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range files {
+		files[i].Score = score(entries[files[i].Path], files[i].Modified, now)
+	}
+
+	// Sort by score, then modification time (most recent first)
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Score != files[j].Score {
+			return files[i].Score > files[j].Score
+		}
+		return files[i].Modified.After(files[j].Modified)
+	})
+
+	return files, nil
+}
+
+// listFiles lists files of the given types from the lister when it
+// answers, which only knows notes, else by walking the vault
+func (s *RealService) listFiles(ctx context.Context, types filetype.Set) ([]model.NoteInfo, error) {
+	if s.lister != nil && types.NotesOnly() {
+		span := diag.Start(ctx, "vault index", "dir", s.notesDir)
+		files, err := s.lister.ListFiles(ctx)
+		span.End("files", len(files), "error", err)
+		if err == nil {
+			return filterFiles(files, types), nil
+		}
+	}
+
+	span := diag.Start(ctx, "vault walk", "dir", s.notesDir)
+	var files []model.NoteInfo
+	err := s.walkDirectory(ctx, s.notesDir, ignore.New(s.notesDir), types, &files)
+	span.End("files", len(files), "error", err)
+	if err != nil {
+		if _, statErr := os.Stat(s.notesDir); errors.Is(statErr, fs.ErrNotExist) {
+			return nil, errs.New(errs.ErrVaultNotFound, "vault %s does not exist", s.notesDir)
+		}
+		return nil, fmt.Errorf("failed to walk directory %s: %w", s.notesDir, err)
+	}
+	return files, nil
+}
+
+// RecordAccess records that a file was picked or opened
+func (s *RealService) RecordAccess(ctx context.Context, file string) error {
+	return s.history.record(file, time.Now())
+}
+
+// walkDirectory recursively walks a directory and collects the files of
+// the given types that aren't ignored, stopping when ctx is done
+func (s *RealService) walkDirectory(ctx context.Context, dirPath string, ignored *ignore.Matcher, types filetype.Set, files *[]model.NoteInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	entries, err := os.ReadDir(dirPath)
 	if err != nil {
 		return err
 	}
 	
 	for _, entry := range entries {
-		// Skip hidden files and directories
-		if entry.Name()[0] == '.' {
+		fullPath := filepath.Join(dirPath, entry.Name())
+
+		// Get relative path from notes directory
+		relPath, err := filepath.Rel(s.notesDir, fullPath)
+		if err != nil {
+			continue // Skip files we can't get relative path for
+		}
+
+		// Skip hidden and ignored files and directories
+		if ignored.Ignored(relPath, entry.IsDir()) {
 			continue
 		}
 		
-		fullPath := filepath.Join(dirPath, entry.Name())
-		
 		if entry.IsDir() {
 			// Recursively walk subdirectories
-			if err := s.walkDirectory(fullPath, files); err != nil {
+			if err := s.walkDirectory(ctx, fullPath, ignored, types, files); err != nil {
 				return err
 			}
 		} else {
-			// Check if it's a markdown file
-			if filepath.Ext(entry.Name()) == ".md" {
+			// Check if it's one of the wanted types
+			if types.Has(entry.Name()) {
 				// Get file info
 				info, err := entry.Info()
 				if err != nil {
 					continue // Skip files we can't get info for
 				}
 				
-				// Get relative path from notes directory
-				relPath, err := filepath.Rel(s.notesDir, fullPath)
-				if err != nil {
-					continue // Skip files we can't get relative path for
-				}
-				
-				*files = append(*files, FileInfo{
-					Name:    relPath,
-					ModTime: info.ModTime(),
+				*files = append(*files, model.NoteInfo{
+					Path:     relPath,
+					Modified: info.ModTime(),
 				})
 			}
 		}
@@ -112,10 +202,86 @@ func (s *RealService) walkDirectory(dirPath string, files *[]FileInfo) error {
 	return nil
 }
 
+// filterFiles keeps the files of the given types
+func filterFiles(files []model.NoteInfo, types filetype.Set) []model.NoteInfo {
+	kept := files[:0]
+	for _, file := range files {
+		if types.Has(file.Path) {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// ListAttachments returns the vault-relative paths of non-markdown files
+// under notesDir, skipping hidden and ignored files and directories like
+// GetSortedFiles
+func ListAttachments(notesDir string) ([]string, error) {
+	var files []string
+	ignored := ignore.New(notesDir)
+	err := filepath.WalkDir(notesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(notesDir, path)
+		if err != nil {
+			return nil
+		}
+		if path != notesDir && ignored.Ignored(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(d.Name()) == ".md" {
+			return nil
+		}
+		files = append(files, rel)
+		return nil
+	})
+	return files, err
+}
+
 // GetSortedFiles mock implementation
-func (s *MockService) GetSortedFiles() ([]string, error) {
+func (s *MockService) GetSortedFiles(ctx context.Context) ([]string, error) {
 	if s.Error != nil {
 		return nil, s.Error
 	}
 	return s.Files, nil
+}
+
+// GetScoredFiles mock implementation; files score by their position
+func (s *MockService) GetScoredFiles(ctx context.Context) ([]model.NoteInfo, error) {
+	if s.Error != nil {
+		return nil, s.Error
+	}
+	files := make([]model.NoteInfo, len(s.Files))
+	for i, name := range s.Files {
+		files[i] = model.NoteInfo{Path: name, Score: float64(len(s.Files) - i)}
+	}
+	return files, nil
+}
+
+// GetFiles mock implementation; the files of the given types score by
+// their position
+func (s *MockService) GetFiles(ctx context.Context, types filetype.Set) ([]model.NoteInfo, error) {
+	if s.Error != nil {
+		return nil, s.Error
+	}
+	var files []model.NoteInfo
+	for _, name := range s.Files {
+		if types.Has(name) {
+			files = append(files, model.NoteInfo{Path: name})
+		}
+	}
+	for i := range files {
+		files[i].Score = float64(len(files) - i)
+	}
+	return files, nil
+}
+
+// RecordAccess mock implementation
+func (s *MockService) RecordAccess(ctx context.Context, file string) error {
+	s.Accessed = append(s.Accessed, file)
+	return nil
 }
\ No newline at end of file
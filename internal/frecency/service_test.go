@@ -1,10 +1,16 @@
 package frecency
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+	"github.com/shalomb/ob-cli/internal/filetype"
+	"github.com/shalomb/ob-cli/internal/model"
 )
 
 func TestService_GetSortedFiles(t *testing.T) {
@@ -41,7 +47,7 @@ func TestService_GetSortedFiles(t *testing.T) {
 	service := NewService(tempDir)
 	
 	// Test GetSortedFiles
-	sortedFiles, err := service.GetSortedFiles()
+	sortedFiles, err := service.GetSortedFiles(context.Background())
 	if err != nil {
 		t.Fatalf("GetSortedFiles failed: %v", err)
 	}
@@ -63,7 +69,7 @@ func TestService_GetSortedFiles_EmptyDirectory(t *testing.T) {
 	tempDir := t.TempDir()
 	
 	service := NewService(tempDir)
-	sortedFiles, err := service.GetSortedFiles()
+	sortedFiles, err := service.GetSortedFiles(context.Background())
 	if err != nil {
 		t.Fatalf("GetSortedFiles failed: %v", err)
 	}
@@ -75,9 +81,9 @@ func TestService_GetSortedFiles_EmptyDirectory(t *testing.T) {
 
 func TestService_GetSortedFiles_NonExistentDirectory(t *testing.T) {
 	service := NewService("/non/existent/directory")
-	_, err := service.GetSortedFiles()
-	if err == nil {
-		t.Error("Expected error for non-existent directory, got nil")
+	_, err := service.GetSortedFiles(context.Background())
+	if !errors.Is(err, errs.ErrVaultNotFound) {
+		t.Errorf("Expected errs.ErrVaultNotFound for non-existent directory, got %v", err)
 	}
 }
 
@@ -122,7 +128,7 @@ func TestService_GetSortedFiles_WithSubdirectories(t *testing.T) {
 	service := NewService(tempDir)
 	
 	// Test GetSortedFiles
-	sortedFiles, err := service.GetSortedFiles()
+	sortedFiles, err := service.GetSortedFiles(context.Background())
 	if err != nil {
 		t.Fatalf("GetSortedFiles failed: %v", err)
 	}
@@ -175,7 +181,7 @@ func TestService_GetSortedFiles_IgnoresNonMarkdownFiles(t *testing.T) {
 	service := NewService(tempDir)
 	
 	// Test GetSortedFiles
-	sortedFiles, err := service.GetSortedFiles()
+	sortedFiles, err := service.GetSortedFiles(context.Background())
 	if err != nil {
 		t.Fatalf("GetSortedFiles failed: %v", err)
 	}
@@ -227,7 +233,7 @@ func TestService_GetSortedFiles_IgnoresHiddenFiles(t *testing.T) {
 	service := NewService(tempDir)
 	
 	// Test GetSortedFiles
-	sortedFiles, err := service.GetSortedFiles()
+	sortedFiles, err := service.GetSortedFiles(context.Background())
 	if err != nil {
 		t.Fatalf("GetSortedFiles failed: %v", err)
 	}
@@ -243,4 +249,168 @@ func TestService_GetSortedFiles_IgnoresHiddenFiles(t *testing.T) {
 			t.Errorf("Expected file %d to be %s, got %s", i, expectedFile, sortedFiles[i])
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestService_GetSortedFiles_IgnoresIgnoredFiles(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tempDir := t.TempDir()
+	for name, content := range map[string]string{
+		".gitignore":                 "node_modules/\n",
+		".obignore":                  "_attachments/\n",
+		".obsidian/app.json":         `{"userIgnoreFilters": ["Archive/"]}`,
+		"note.md":                    "",
+		"node_modules/pkg/readme.md": "",
+		"_attachments/old.md":        "",
+		"_attachments/cat.png":       "",
+		"Archive/2020.md":            "",
+		"img/dog.png":                "",
+	} {
+		path := filepath.Join(tempDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	sortedFiles, err := NewService(tempDir).GetSortedFiles(context.Background())
+	if err != nil {
+		t.Fatalf("GetSortedFiles failed: %v", err)
+	}
+	if len(sortedFiles) != 1 || sortedFiles[0] != "note.md" {
+		t.Errorf("Expected only note.md, got %v", sortedFiles)
+	}
+
+	attachments, err := ListAttachments(tempDir)
+	if err != nil {
+		t.Fatalf("ListAttachments failed: %v", err)
+	}
+	if len(attachments) != 1 || attachments[0] != filepath.Join("img", "dog.png") {
+		t.Errorf("Expected only img/dog.png, got %v", attachments)
+	}
+}
+
+func TestListAttachments(t *testing.T) {
+	tempDir := t.TempDir()
+	for _, name := range []string{"note.md", "img/cat.png", "docs/spec.pdf", ".obsidian/app.json", "img/.thumb.png"} {
+		path := filepath.Join(tempDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	files, err := ListAttachments(tempDir)
+	if err != nil {
+		t.Fatalf("ListAttachments failed: %v", err)
+	}
+
+	expected := []string{filepath.Join("docs", "spec.pdf"), filepath.Join("img", "cat.png")}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, files)
+	}
+	for i := range expected {
+		if files[i] != expected[i] {
+			t.Errorf("Expected attachment %d to be %s, got %s", i, expected[i], files[i])
+		}
+	}
+}
+
+func TestService_GetScoredFiles(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tempDir := t.TempDir()
+
+	now := time.Now()
+	for i, name := range []string{"old.md", "new.md"} {
+		path := filepath.Join(tempDir, name)
+		os.WriteFile(path, []byte("x"), 0644)
+		modTime := now.Add(time.Duration(i-2) * time.Hour)
+		os.Chtimes(path, modTime, modTime)
+	}
+
+	service := NewService(tempDir)
+	if err := service.RecordAccess(context.Background(), "old.md"); err != nil {
+		t.Fatal(err)
+	}
+	service.RecordAccess(context.Background(), "old.md")
+
+	files, err := service.GetScoredFiles(context.Background())
+	if err != nil {
+		t.Fatalf("GetScoredFiles failed: %v", err)
+	}
+	if len(files) != 2 || files[0].Path != "old.md" || files[1].Path != "new.md" {
+		t.Fatalf("Expected old.md before new.md, got %+v", files)
+	}
+	if files[0].Score <= files[1].Score || files[1].Score <= 0 {
+		t.Errorf("Expected descending positive scores, got %v and %v", files[0].Score, files[1].Score)
+	}
+}
+
+type stubLister struct {
+	files []model.NoteInfo
+	err   error
+}
+
+func (l stubLister) ListFiles(ctx context.Context) ([]model.NoteInfo, error) { return l.files, l.err }
+
+func TestService_GetSortedFiles_Lister(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tempDir := t.TempDir()
+	os.WriteFile(filepath.Join(tempDir, "on-disk.md"), []byte("x"), 0644)
+
+	now := time.Now()
+	indexed := stubLister{files: []model.NoteInfo{
+		{Path: "older.md", Modified: now.Add(-time.Hour)},
+		{Path: "newer.md", Modified: now},
+	}}
+	files, err := NewIndexedService(tempDir, indexed).GetSortedFiles(context.Background())
+	if err != nil || len(files) != 2 || files[0] != "newer.md" || files[1] != "older.md" {
+		t.Errorf("GetSortedFiles() with lister = %v, %v", files, err)
+	}
+
+	failing := stubLister{err: os.ErrNotExist}
+	files, err = NewIndexedService(tempDir, failing).GetSortedFiles(context.Background())
+	if err != nil || len(files) != 1 || files[0] != "on-disk.md" {
+		t.Errorf("GetSortedFiles() with failing lister = %v, %v", files, err)
+	}
+}
+
+func TestService_GetFiles(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	tempDir := t.TempDir()
+	for _, name := range []string{"note.md", "plan.excalidraw.md", "board.canvas", "img/cat.png", "docs/spec.pdf", "data.csv"} {
+		path := filepath.Join(tempDir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x"), 0644)
+	}
+
+	indexed := stubLister{files: []model.NoteInfo{{Path: "note.md"}, {Path: "plan.excalidraw.md"}}}
+	tests := []struct {
+		types filetype.Set
+		want  int
+	}{
+		{nil, 2},
+		{filetype.Set{filetype.Excalidraw: true}, 1},
+		{filetype.Set{filetype.Canvas: true, filetype.PDF: true}, 2},
+		{filetype.All, 6},
+	}
+	for _, tt := range tests {
+		files, err := NewIndexedService(tempDir, indexed).GetFiles(context.Background(), tt.types)
+		if err != nil {
+			t.Fatalf("GetFiles(%s) error = %v", tt.types, err)
+		}
+		if len(files) != tt.want {
+			t.Errorf("GetFiles(%s) = %v, want %d files", tt.types, files, tt.want)
+		}
+		for _, file := range files {
+			if !tt.types.Has(file.Path) {
+				t.Errorf("GetFiles(%s) listed %s", tt.types, file.Path)
+			}
+		}
+	}
+
+	mock := NewMockService([]string{"a.md", "b.canvas", "c.md"}, nil)
+	files, err := mock.GetFiles(context.Background(), filetype.Set{filetype.Canvas: true})
+	if err != nil || len(files) != 1 || files[0].Path != "b.canvas" || files[0].Score != 1 {
+		t.Errorf("MockService.GetFiles() = %v, %v", files, err)
+	}
+}
@@ -1,17 +1,38 @@
 package fzf
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
-// Service interface for fzf operations
+// Service interface for fzf operations. Cancelling ctx closes fzf and
+// returns ctx's error; the user cancelling returns errs.ErrCancelled.
 type Service interface {
-	SelectFile(files []string, query string) (string, error)
+	SelectFile(ctx context.Context, files []string, query string) (string, error)
+	SelectFiles(ctx context.Context, files []string, query string) ([]string, error)
+	SelectLine(ctx context.Context, lines []string, preview string) (string, error)
+	SelectEntries(ctx context.Context, entries []Entry, query string, multi bool, preview string) ([]string, error)
+}
+
+// Entry is a file listed with a marker before its path and a description
+// after it. Only the path is searched and returned.
+type Entry struct {
+	Marker      string // Up to 7 characters, so paths line up
+	Path        string
+	Description string
 }
 
+var errFzfMissing = errs.New(errs.ErrToolMissing, "fzf is not installed. Please install fzf: https://github.com/junegunn/fzf")
+
 // RealService handles real fzf integration
 type RealService struct{}
 
@@ -20,6 +41,7 @@ type MockService struct {
 	Selection string
 	ShouldExit bool
 	Error error
+	Preview string // Preview command of the last SelectLine
 }
 
 // NewService creates a new real fzf service
@@ -37,36 +59,41 @@ func NewMockService(selection string, shouldExit bool, err error) Service {
 }
 
 // SelectFile runs fzf to select a file from the given list
-func (s *RealService) SelectFile(files []string, query string) (string, error) {
+func (s *RealService) SelectFile(ctx context.Context, files []string, query string) (string, error) {
 	if len(files) == 0 {
 		return "", nil
 	}
 	
 	// Check if fzf is available
 	if !s.isFzfAvailable() {
-		return "", fmt.Errorf("fzf is not installed. Please install fzf: https://github.com/junegunn/fzf")
+		return "", errFzfMissing
 	}
 	
 	// Prepare fzf command with print-query to capture user input
-	cmd := exec.Command("fzf", "--height", "40%", "--border", "--print-query")
+	cmd := command(ctx, "--height", "40%", "--border", "--print-query")
 	
 	// Add query if provided
 	if query != "" {
 		cmd.Args = append(cmd.Args, "--query", query)
 	}
 	
-	// Set up stdin to provide file list
-	cmd.Stdin = strings.NewReader(strings.Join(files, "\n"))
 	cmd.Stderr = os.Stderr
 	
-	// Capture stdout to get the selection
-	output, err := cmd.Output()
+	// Provide the file list on stdin and capture stdout to get the selection
+	output, err := run(ctx, cmd, files)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if err != nil {
-		// fzf returns exit code 1 when user cancels (ESC)
-		if exitError, ok := err.(*exec.ExitError); ok && exitError.ExitCode() == 1 {
-			return "", nil // User cancelled, return empty selection
+		exitError, ok := err.(*exec.ExitError)
+		switch {
+		case ok && exitError.ExitCode() == 130:
+			return "", errs.ErrCancelled // ESC or Ctrl-C
+		case ok && exitError.ExitCode() == 1:
+			// No match: fall back to the typed query below
+		default:
+			return "", fmt.Errorf("fzf execution failed: %w", err)
 		}
-		return "", fmt.Errorf("fzf execution failed: %w", err)
 	}
 	
 	// Parse output: first line is query, second line is selection (if any)
@@ -89,21 +116,190 @@ func (s *RealService) SelectFile(files []string, query string) (string, error) {
 	return strings.TrimSpace(lines[0]), nil
 }
 
+// SelectFiles runs fzf with multi-select enabled (TAB to mark files).
+// As with SelectFile, the typed query is returned when nothing is selected.
+func (s *RealService) SelectFiles(ctx context.Context, files []string, query string) ([]string, error) {
+	return s.selectFiles(ctx, files, query, "--multi")
+}
+
+// SelectEntries runs fzf to select one entry, or several when multi is
+// set, and returns their paths. The typed query is returned when nothing
+// is selected. preview, when set, is a command showing the highlighted
+// entry, with {2} standing for its path.
+func (s *RealService) SelectEntries(ctx context.Context, entries []Entry, query string, multi bool, preview string) ([]string, error) {
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.Marker + "\t" + entry.Path + "\t" + entry.Description
+	}
+	args := []string{"--delimiter", "\t", "--nth", "2"}
+	if multi {
+		args = append(args, "--multi")
+	}
+	if preview != "" {
+		args = append(args, "--preview", preview, "--preview-window", "right,50%")
+	}
+
+	selections, err := s.selectFiles(ctx, lines, query, args...)
+	for i, selection := range selections {
+		if fields := strings.Split(selection, "\t"); len(fields) > 1 {
+			selections[i] = strings.TrimSpace(fields[1])
+		}
+	}
+	if !multi && len(selections) > 1 {
+		selections = selections[:1]
+	}
+	return selections, err
+}
+
+// selectFiles runs fzf on lines with extra arguments and returns the
+// selected lines, or the typed query when nothing is selected
+func (s *RealService) selectFiles(ctx context.Context, lines []string, query string, args ...string) ([]string, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	if !s.isFzfAvailable() {
+		return nil, errFzfMissing
+	}
+
+	cmd := command(ctx, append([]string{"--height", "40%", "--border", "--print-query"}, args...)...)
+	if query != "" {
+		cmd.Args = append(cmd.Args, "--query", query)
+	}
+	cmd.Stderr = os.Stderr
+
+	output, err := run(ctx, cmd, lines)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		exitError, ok := err.(*exec.ExitError)
+		switch {
+		case ok && exitError.ExitCode() == 130:
+			return nil, errs.ErrCancelled // ESC or Ctrl-C
+		case ok && exitError.ExitCode() == 1:
+			// No match: fall back to the typed query below
+		default:
+			return nil, fmt.Errorf("fzf execution failed: %w", err)
+		}
+	}
+
+	outputLines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	var selections []string
+	for _, line := range outputLines[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			selections = append(selections, line)
+		}
+	}
+	if len(selections) == 0 {
+		if q := strings.TrimSpace(outputLines[0]); q != "" {
+			selections = []string{q}
+		}
+	}
+	return selections, nil
+}
+
+// SelectLine runs fzf to pick one of lines, showing the output of the
+// preview command (with fzf's {} placeholders) for the highlighted line.
+// Lines are split into fields at tabs and only the first four are shown.
+func (s *RealService) SelectLine(ctx context.Context, lines []string, preview string) (string, error) {
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	if !s.isFzfAvailable() {
+		return "", errFzfMissing
+	}
+
+	cmd := command(ctx, "--height", "80%", "--border", "--no-sort",
+		"--delimiter", "\t", "--with-nth", "1..4", "--preview", preview, "--preview-window", "down,60%")
+	cmd.Stderr = os.Stderr
+
+	output, err := run(ctx, cmd, lines)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		exitError, ok := err.(*exec.ExitError)
+		if ok && exitError.ExitCode() == 130 {
+			return "", errs.ErrCancelled // ESC or Ctrl-C
+		}
+		if ok && exitError.ExitCode() == 1 {
+			return "", nil // No match
+		}
+		return "", fmt.Errorf("fzf execution failed: %w", err)
+	}
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
 // SelectFile returns mock selection for testing
-func (s *MockService) SelectFile(files []string, query string) (string, error) {
+func (s *MockService) SelectFile(ctx context.Context, files []string, query string) (string, error) {
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
 	if s.Error != nil {
 		return "", s.Error
 	}
 	
 	if s.ShouldExit {
-		return "", nil // User cancelled
+		return "", errs.ErrCancelled
 	}
 	
 	return s.Selection, nil
 }
 
+// SelectFiles returns the mock selection as a single-element list
+func (s *MockService) SelectFiles(ctx context.Context, files []string, query string) ([]string, error) {
+	selection, err := s.SelectFile(ctx, files, query)
+	if err != nil || selection == "" {
+		return nil, err
+	}
+	return []string{selection}, nil
+}
+
+// SelectEntries returns the mock selection as a single-element list
+func (s *MockService) SelectEntries(ctx context.Context, entries []Entry, query string, multi bool, preview string) ([]string, error) {
+	return s.SelectFiles(ctx, nil, query)
+}
+
+// SelectLine returns the mock selection, which should be one of lines
+func (s *MockService) SelectLine(ctx context.Context, lines []string, preview string) (string, error) {
+	s.Preview = preview
+	return s.SelectFile(ctx, lines, "")
+}
+
 // isFzfAvailable checks if fzf is installed and available
 func (s *RealService) isFzfAvailable() bool {
 	_, err := exec.LookPath("fzf")
 	return err == nil
-}
\ No newline at end of file
+}
+
+// command prepares fzf to run until ctx is done. Cancelling interrupts fzf
+// rather than killing it, so it restores the terminal.
+func command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "fzf", args...)
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 2 * time.Second
+	return cmd
+}
+
+// run runs fzf on input and returns its output. Besides the session, it
+// times spawning fzf: input is first read, by exec's copying goroutine,
+// once the process has started. When fzf is ready to use isn't known.
+func run(ctx context.Context, cmd *exec.Cmd, input []string) ([]byte, error) {
+	spawn := diag.Start(ctx, "fzf spawn", "items", len(input))
+	cmd.Stdin = &spawnReader{Reader: strings.NewReader(strings.Join(input, "\n")), span: spawn}
+	return diag.Output(ctx, cmd)
+}
+
+// spawnReader ends a span on the first read
+type spawnReader struct {
+	io.Reader
+	span *diag.Span
+	once sync.Once
+}
+
+func (r *spawnReader) Read(p []byte) (int, error) {
+	r.once.Do(func() { r.span.End() })
+	return r.Reader.Read(p)
+}
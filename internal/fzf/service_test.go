@@ -1,15 +1,26 @@
 package fzf
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
 func TestMockService_SelectFile(t *testing.T) {
 	// Test with empty file list
 	service := NewMockService("", false, nil)
 	files := []string{}
-	selection, err := service.SelectFile(files, "")
+	selection, err := service.SelectFile(context.Background(), files, "")
 	if err != nil {
 		t.Fatalf("SelectFile failed: %v", err)
 	}
@@ -20,7 +31,7 @@ func TestMockService_SelectFile(t *testing.T) {
 	// Test with mock selection
 	service = NewMockService("file1.md", false, nil)
 	files = []string{"file1.md", "file2.md", "file3.md"}
-	selection, err = service.SelectFile(files, "")
+	selection, err = service.SelectFile(context.Background(), files, "")
 	if err != nil {
 		t.Fatalf("SelectFile failed: %v", err)
 	}
@@ -32,7 +43,7 @@ func TestMockService_SelectFile(t *testing.T) {
 func TestMockService_SelectFile_WithQuery(t *testing.T) {
 	service := NewMockService("project-notes.md", false, nil)
 	files := []string{"project-notes.md", "daily-log.md", "project-ideas.md"}
-	selection, err := service.SelectFile(files, "project")
+	selection, err := service.SelectFile(context.Background(), files, "project")
 	if err != nil {
 		t.Fatalf("SelectFile with query failed: %v", err)
 	}
@@ -44,9 +55,9 @@ func TestMockService_SelectFile_WithQuery(t *testing.T) {
 func TestMockService_SelectFile_UserCancels(t *testing.T) {
 	service := NewMockService("", true, nil) // ShouldExit = true
 	files := []string{"file1.md", "file2.md", "file3.md"}
-	selection, err := service.SelectFile(files, "")
-	if err != nil {
-		t.Fatalf("SelectFile failed: %v", err)
+	selection, err := service.SelectFile(context.Background(), files, "")
+	if !errors.Is(err, errs.ErrCancelled) {
+		t.Fatalf("SelectFile error = %v, want errs.ErrCancelled", err)
 	}
 	if selection != "" {
 		t.Errorf("Expected empty selection when user cancels, got %s", selection)
@@ -57,11 +68,85 @@ func TestMockService_SelectFile_Error(t *testing.T) {
 	expectedError := errors.New("fzf not found")
 	service := NewMockService("", false, expectedError)
 	files := []string{"file1.md", "file2.md", "file3.md"}
-	_, err := service.SelectFile(files, "")
+	_, err := service.SelectFile(context.Background(), files, "")
 	if err == nil {
 		t.Fatal("Expected error, got nil")
 	}
 	if err != expectedError {
 		t.Errorf("Expected error %v, got %v", expectedError, err)
 	}
-}
\ No newline at end of file
+}
+
+func TestMockService_SelectFiles(t *testing.T) {
+	service := NewMockService("file1.md", false, nil)
+	selections, err := service.SelectFiles(context.Background(), []string{"file1.md", "file2.md"}, "")
+	if err != nil {
+		t.Fatalf("SelectFiles failed: %v", err)
+	}
+	if len(selections) != 1 || selections[0] != "file1.md" {
+		t.Errorf("Expected selections [file1.md], got %v", selections)
+	}
+
+	service = NewMockService("", true, nil)
+	selections, err = service.SelectFiles(context.Background(), []string{"file1.md"}, "")
+	if !errors.Is(err, errs.ErrCancelled) {
+		t.Fatalf("SelectFiles error = %v, want errs.ErrCancelled", err)
+	}
+	if len(selections) != 0 {
+		t.Errorf("Expected no selections when user cancels, got %v", selections)
+	}
+}
+
+func TestRun_TimesStartup(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	ctx := diag.WithLogger(context.Background(), logger)
+
+	// cat stands in for fzf, reading its input like fzf does
+	output, err := run(ctx, exec.Command("cat"), []string{"a.md", "b.md"})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if string(output) != "a.md\nb.md" {
+		t.Errorf("output = %q", output)
+	}
+	for _, want := range []string{`msg="fzf spawn" items=2`, `msg="exec cat"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log %q does not contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestRealService_SelectEntries(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake fzf is a shell script")
+	}
+	// The fake fzf prints an empty query and picks the second line
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"$@\" > \"$(dirname \"$0\")/args\"\necho\nsed -n 2p\n"
+	if err := os.WriteFile(filepath.Join(dir, "fzf"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	entries := []Entry{
+		{Marker: "md", Path: "home.md"},
+		{Marker: "canvas", Path: "plans/board.canvas", Description: "3 cards, 2 links"},
+	}
+	selections, err := NewService().SelectEntries(context.Background(), entries, "", false, "cat {2}")
+	if err != nil {
+		t.Fatalf("SelectEntries() error = %v", err)
+	}
+	if len(selections) != 1 || selections[0] != "plans/board.canvas" {
+		t.Errorf("SelectEntries() = %q, want the path only", selections)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args"))
+	for _, want := range []string{"--nth 2", "--preview cat {2}"} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("fzf args %q do not contain %q", args, want)
+		}
+	}
+}
@@ -0,0 +1,1010 @@
+package git
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/util"
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// ErrUnsupported is returned for operations the go backend doesn't have
+var ErrUnsupported = errors.New("not supported by the go git backend")
+
+// GoService handles git operations in-process with go-git, so no git binary
+// is needed. Remotes are reached over file://, or ssh:// with the ssh agent.
+type GoService struct {
+	repoDir string
+}
+
+// NewGoService creates a git service that doesn't need a git binary
+func NewGoService(repoDir string) Service {
+	return &GoService{repoDir: repoDir}
+}
+
+// repository is an opened repository and where repoDir lies in its worktree
+type repository struct {
+	*gogit.Repository
+	worktree *gogit.Worktree
+	prefix   string // repoDir relative to the worktree root, "" at the root
+}
+
+func (s *GoService) open() (*repository, error) {
+	repo, err := gogit.PlainOpenWithOptions(s.repoDir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	prefix, err := filepath.Rel(realPath(worktree.Filesystem.Root()), realPath(s.repoDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate %s in the worktree: %w", s.repoDir, err)
+	}
+	prefix = filepath.ToSlash(prefix)
+	if prefix == "." {
+		prefix = ""
+	}
+	return &repository{Repository: repo, worktree: worktree, prefix: prefix}, nil
+}
+
+func realPath(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		return resolved
+	}
+	return dir
+}
+
+// path converts a path relative to repoDir into one relative to the
+// worktree root
+func (r *repository) path(file string) string {
+	return path.Join(r.prefix, filepath.ToSlash(file))
+}
+
+// relative converts a path relative to the worktree root into one relative
+// to repoDir
+func (r *repository) relative(name string) string {
+	if r.prefix == "" {
+		return name
+	}
+	if rel, ok := strings.CutPrefix(name, r.prefix+"/"); ok {
+		return rel
+	}
+	return name
+}
+
+// FetchAsync starts fetching every remote in background, running until it
+// finishes or ctx is done
+func (s *GoService) FetchAsync(ctx context.Context) {
+	go func() {
+		if repo, err := s.open(); err == nil {
+			repo.fetchAll(ctx) // Ignore errors for async operation
+		}
+	}()
+}
+
+func (r *repository) fetchAll(ctx context.Context) error {
+	remotes, err := r.Remotes()
+	if err != nil {
+		return err
+	}
+	for _, remote := range remotes {
+		if err := r.fetch(ctx, remote.Config().Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repository) fetch(ctx context.Context, remote string) error {
+	span := diag.Start(ctx, "go-git fetch", "remote", remote)
+	err := r.FetchContext(ctx, &gogit.FetchOptions{RemoteName: remote})
+	span.End("error", err)
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) && !errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return fmt.Errorf("git fetch %s failed: %w", remote, err)
+	}
+	return nil
+}
+
+// Root returns the top-level directory of the repository's worktree
+func (s *GoService) Root(ctx context.Context) (string, error) {
+	repo, err := s.open()
+	if err != nil {
+		return "", err
+	}
+	return repo.worktree.Filesystem.Root(), nil
+}
+
+// GetStatus returns the changed files in git status --porcelain format
+func (s *GoService) GetStatus(ctx context.Context) (string, error) {
+	repo, err := s.open()
+	if err != nil {
+		return "", err
+	}
+	status, err := repo.worktree.Status()
+	if err != nil {
+		return "", fmt.Errorf("git status failed: %w", err)
+	}
+
+	var names []string
+	for name, file := range status {
+		if file.Staging != gogit.Unmodified || file.Worktree != gogit.Unmodified {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%c%c %s\n", status[name].Staging, status[name].Worktree, name)
+	}
+	return b.String(), nil
+}
+
+// GetSyncStatus returns behind/ahead counts against the upstream branch
+func (s *GoService) GetSyncStatus(ctx context.Context) (behind, ahead int, err error) {
+	repo, err := s.open()
+	if err != nil {
+		return 0, 0, nil // Ignore errors, assume up to date
+	}
+	branch, err := repo.tracking()
+	if err != nil {
+		return 0, 0, nil
+	}
+	upstream, err := repo.Reference(branch.upstream(), true)
+	if err != nil {
+		return 0, 0, nil
+	}
+
+	return repo.divergence(ctx, branch.head, upstream.Hash())
+}
+
+// trackingBranch is the checked out branch and the remote branch it tracks
+type trackingBranch struct {
+	name   plumbing.ReferenceName // e.g. refs/heads/main
+	head   plumbing.Hash
+	remote string
+	merge  plumbing.ReferenceName // The branch on the remote
+}
+
+// upstream returns the remote-tracking reference, like @{upstream}
+func (b *trackingBranch) upstream() plumbing.ReferenceName {
+	return plumbing.NewRemoteReferenceName(b.remote, b.merge.Short())
+}
+
+func (r *repository) tracking() (*trackingBranch, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return nil, errors.New("HEAD is not on a branch")
+	}
+	cfg, err := r.Config()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read git config: %w", err)
+	}
+	branch, ok := cfg.Branches[head.Name().Short()]
+	if !ok || branch.Remote == "" || branch.Merge == "" {
+		return nil, fmt.Errorf("branch %s has no upstream", head.Name().Short())
+	}
+	return &trackingBranch{name: head.Name(), head: head.Hash(), remote: branch.Remote, merge: branch.Merge}, nil
+}
+
+// divergence counts the commits reachable from only the local or only the
+// remote tip, like git rev-list --left-right --count. It walks both
+// histories at once, newest first, and stops when every commit left to
+// visit is reachable from both tips, so its cost follows the divergence
+// rather than the whole history. Commits are taken to be newer than their
+// parents, as git's own walk does.
+func (r *repository) divergence(ctx context.Context, local, remote plumbing.Hash) (behind, ahead int, err error) {
+	flags := map[plumbing.Hash]int{}
+	var queue commitQueue
+	push := func(hash plumbing.Hash, flag int) error {
+		if flags[hash]|flag == flags[hash] {
+			return nil
+		}
+		queued := flags[hash] != 0
+		flags[hash] |= flag
+		if queued {
+			return nil
+		}
+		commit, err := r.CommitObject(hash)
+		if err != nil {
+			return err
+		}
+		heap.Push(&queue, commit)
+		return nil
+	}
+	if err := push(local, fromLocal); err != nil {
+		return 0, 0, err
+	}
+	if err := push(remote, fromRemote); err != nil {
+		return 0, 0, err
+	}
+
+	for queue.Len() > 0 && queue.unique(flags) {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		commit := heap.Pop(&queue).(*object.Commit)
+		flag := flags[commit.Hash]
+		switch flag {
+		case fromLocal:
+			ahead++
+		case fromRemote:
+			behind++
+		}
+		for _, parent := range commit.ParentHashes {
+			if err := push(parent, flag); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+	return behind, ahead, nil
+}
+
+// Tips a commit is reachable from, in divergence
+const (
+	fromLocal  = 1
+	fromRemote = 2
+	fromBoth   = fromLocal | fromRemote
+)
+
+// commitQueue is a heap of commits, newest committed first
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x interface{}) { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() interface{} {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// unique reports whether a queued commit is reachable from only one tip
+func (q commitQueue) unique(flags map[plumbing.Hash]int) bool {
+	for _, commit := range q {
+		if flags[commit.Hash] != fromBoth {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncWithRemote fetches the upstream branch and brings it in without
+// stashing: a fast-forward when possible, otherwise a merge commit.
+// Uncommitted changes are kept; it refuses to sync when upstream changed a
+// file that has uncommitted changes, or that was committed differently.
+func (s *GoService) SyncWithRemote(ctx context.Context) error {
+	repo, err := s.open()
+	if err != nil {
+		return err
+	}
+	branch, err := repo.tracking()
+	if err != nil {
+		return err
+	}
+
+	if err := repo.fetch(ctx, branch.remote); err != nil {
+		return err
+	}
+
+	upstream, err := repo.Reference(branch.upstream(), true)
+	if err != nil {
+		return fmt.Errorf("upstream %s not found: %w", branch.upstream().Short(), err)
+	}
+	head, err := repo.CommitObject(branch.head)
+	if err != nil {
+		return err
+	}
+	theirs, err := repo.CommitObject(upstream.Hash())
+	if err != nil {
+		return err
+	}
+	if upToDate, err := theirs.IsAncestor(head); err != nil || upToDate {
+		return err
+	}
+
+	bases, err := head.MergeBase(theirs)
+	if err != nil {
+		return err
+	}
+	if len(bases) == 0 {
+		return fmt.Errorf("%s and %s have no common history", branch.name.Short(), branch.upstream().Short())
+	}
+	incoming, err := repo.changes(bases[0], theirs)
+	if err != nil {
+		return err
+	}
+	if err := repo.checkConflicts(incoming, bases[0], head); err != nil {
+		return err
+	}
+
+	// Nothing has changed yet; past this point the sync runs to completion
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if bases[0].Hash == head.Hash {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(branch.name, theirs.Hash)); err != nil {
+			return fmt.Errorf("fast-forward failed: %w", err)
+		}
+	} else if err := repo.commitMerge(head, theirs, incoming, branch); err != nil {
+		return fmt.Errorf("merge failed: %w", err)
+	}
+	return repo.checkout(incoming)
+}
+
+// changes returns the files changed between two commits, nil for deleted
+// files
+func (r *repository) changes(from, to *object.Commit) (map[string]*object.File, error) {
+	fromTree, err := from.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := to.Tree()
+	if err != nil {
+		return nil, err
+	}
+	changes, err := object.DiffTree(fromTree, toTree)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*object.File)
+	for _, change := range changes {
+		_, file, err := change.Files()
+		if err != nil {
+			return nil, err
+		}
+		if change.From.Name != "" {
+			files[change.From.Name] = nil
+		}
+		if file != nil {
+			files[change.To.Name] = file
+		}
+	}
+	return files, nil
+}
+
+// checkConflicts fails if applying incoming changes would overwrite
+// uncommitted changes or a different local commit of the same file
+func (r *repository) checkConflicts(incoming map[string]*object.File, base, head *object.Commit) error {
+	local, err := r.changes(base, head)
+	if err != nil {
+		return err
+	}
+	status, err := r.worktree.Status()
+	if err != nil {
+		return fmt.Errorf("git status failed: %w", err)
+	}
+
+	var conflicts []string
+	for name, file := range incoming {
+		if st, ok := status[name]; ok && (st.Staging != gogit.Unmodified || st.Worktree != gogit.Unmodified) {
+			conflicts = append(conflicts, name)
+		} else if mine, ok := local[name]; ok && !sameFile(mine, file) {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return errs.New(errs.ErrGitConflict, "cannot sync, local and upstream both changed %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+func sameFile(a, b *object.File) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Hash == b.Hash && a.Mode == b.Mode
+}
+
+// commitMerge commits head's files with the incoming changes applied, with
+// head and theirs as parents
+func (r *repository) commitMerge(head, theirs *object.Commit, incoming map[string]*object.File, branch *trackingBranch) error {
+	idx, err := treeIndex(head)
+	if err != nil {
+		return err
+	}
+	for name, file := range incoming {
+		if file == nil {
+			idx.Remove(name)
+			continue
+		}
+		entry, err := idx.Entry(name)
+		if err != nil {
+			entry = idx.Add(name)
+		}
+		entry.Hash, entry.Mode = file.Hash, file.Mode
+	}
+	message := fmt.Sprintf("Merge %s into %s", branch.upstream().Short(), branch.name.Short())
+	return r.commitIndex(idx, message, head.Hash, theirs.Hash)
+}
+
+// treeIndex returns an index holding a commit's files; an empty index for
+// nil
+func treeIndex(commit *object.Commit) (*index.Index, error) {
+	idx := &index.Index{Version: 2}
+	if commit == nil {
+		return idx, nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	err = tree.Files().ForEach(func(file *object.File) error {
+		entry := idx.Add(file.Name)
+		entry.Hash, entry.Mode = file.Hash, file.Mode
+		return nil
+	})
+	return idx, err
+}
+
+// commitIndex commits the given index, leaving the repository's index as it
+// was, and moves the current branch to the commit
+func (r *repository) commitIndex(idx *index.Index, message string, parents ...plumbing.Hash) error {
+	saved, err := r.Storer.Index()
+	if err != nil {
+		return err
+	}
+	if err := r.Storer.SetIndex(idx); err != nil {
+		return err
+	}
+	_, err = r.worktree.Commit(message, &gogit.CommitOptions{Parents: parents, AllowEmptyCommits: true})
+	if restoreErr := r.Storer.SetIndex(saved); err == nil {
+		err = restoreErr
+	}
+	return err
+}
+
+// checkout writes the incoming changes to the worktree and index, leaving
+// every other file alone
+func (r *repository) checkout(incoming map[string]*object.File) error {
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return err
+	}
+	fs := r.worktree.Filesystem
+	for name, file := range incoming {
+		if file == nil {
+			if err := fs.Remove(name); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			idx.Remove(name)
+			continue
+		}
+
+		contents, err := file.Contents()
+		if err != nil {
+			return err
+		}
+		fs.Remove(name)
+		if file.Mode == filemode.Symlink {
+			err = fs.Symlink(contents, name)
+		} else {
+			mode, _ := file.Mode.ToOSFileMode()
+			err = util.WriteFile(fs, name, []byte(contents), mode.Perm())
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		entry, err := idx.Entry(name)
+		if err != nil {
+			entry = idx.Add(name)
+		}
+		entry.Hash, entry.Mode = file.Hash, file.Mode
+		if info, err := fs.Lstat(name); err == nil {
+			entry.Size, entry.ModifiedAt = uint32(info.Size()), info.ModTime()
+		}
+	}
+	return r.Storer.SetIndex(idx)
+}
+
+// Push pushes the current branch to its upstream
+func (s *GoService) Push(ctx context.Context) error {
+	repo, err := s.open()
+	if err != nil {
+		return err
+	}
+	branch, err := repo.tracking()
+	if err != nil {
+		return err
+	}
+
+	refspec := gitconfig.RefSpec(branch.name.String() + ":" + branch.merge.String())
+	span := diag.Start(ctx, "go-git push", "remote", branch.remote, "refspec", refspec)
+	err = repo.PushContext(ctx, &gogit.PushOptions{RemoteName: branch.remote, RefSpecs: []gitconfig.RefSpec{refspec}})
+	span.End("error", err)
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("git push failed: %w", err)
+	}
+	return nil
+}
+
+// CommitFiles stages and commits only the given files, leaving anything
+// else staged for later
+func (s *GoService) CommitFiles(ctx context.Context, message string, files ...string) error {
+	repo, err := s.open()
+	if err != nil {
+		return err
+	}
+	paths := repo.paths(files)
+	if err := repo.stage(paths); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+
+	staged, err := repo.Storer.Index()
+	if err != nil {
+		return err
+	}
+	var head *object.Commit
+	if ref, err := repo.Head(); err == nil {
+		if head, err = repo.CommitObject(ref.Hash()); err != nil {
+			return err
+		}
+	}
+	committed, err := treeIndex(head)
+	if err != nil {
+		return err
+	}
+
+	// HEAD's files, with the given paths as staged
+	idx := &index.Index{Version: 2}
+	changed := false
+	for _, entry := range committed.Entries {
+		if !within(entry.Name, paths) {
+			idx.Entries = append(idx.Entries, entry)
+		} else if e, err := staged.Entry(entry.Name); err != nil || e.Hash != entry.Hash || e.Mode != entry.Mode {
+			changed = true
+		}
+	}
+	for _, entry := range staged.Entries {
+		if within(entry.Name, paths) {
+			idx.Entries = append(idx.Entries, entry)
+			if e, err := committed.Entry(entry.Name); err != nil || e.Hash != entry.Hash || e.Mode != entry.Mode {
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return errors.New("git commit failed: nothing to commit")
+	}
+
+	if err := repo.commitIndex(idx, message); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+	return nil
+}
+
+func (r *repository) paths(files []string) []string {
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = r.path(file)
+	}
+	return paths
+}
+
+// within reports whether name is one of paths or inside one of them
+func within(name string, paths []string) bool {
+	for _, p := range paths {
+		if p == "" || name == p || strings.HasPrefix(name, p+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// stage stages the current state of paths like git add --all, including
+// removals
+func (r *repository) stage(paths []string) error {
+	status, err := r.worktree.Status()
+	if err != nil {
+		return err
+	}
+	for name, file := range status {
+		if file.Worktree == gogit.Unmodified || !within(name, paths) {
+			continue
+		}
+		if _, err := r.worktree.Add(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsTracked reports whether git tracks a file
+func (s *GoService) IsTracked(ctx context.Context, file string) bool {
+	repo, err := s.open()
+	if err != nil {
+		return false
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return false
+	}
+	paths := []string{repo.path(file)}
+	for _, entry := range idx.Entries {
+		if within(entry.Name, paths) {
+			return true
+		}
+	}
+	return false
+}
+
+// StageFiles stages the current state of files, including their removal
+// (like git rm) or a move (like git mv, given both paths)
+func (s *GoService) StageFiles(ctx context.Context, files ...string) error {
+	repo, err := s.open()
+	if err != nil {
+		return err
+	}
+	if err := repo.stage(repo.paths(files)); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+	return nil
+}
+
+// History returns the commits touching a file, newest first, following
+// renames along first parents
+func (s *GoService) History(ctx context.Context, file string) ([]Commit, error) {
+	repo, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	return repo.history(ctx, file)
+}
+
+func (r *repository) history(ctx context.Context, file string) ([]Commit, error) {
+	head, err := r.Head()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var commits []Commit
+	name := r.path(file)
+	for commit != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		current := fileIn(commit, name)
+		var parent *object.Commit
+		var previous *object.File
+		previousName := name
+		if commit.NumParents() > 0 {
+			if parent, err = commit.Parent(0); err != nil {
+				return nil, fmt.Errorf("git log failed: %w", err)
+			}
+			previous = fileIn(parent, name)
+			if current != nil && previous == nil {
+				previousName = renamedFrom(ctx, parent, commit, name)
+				previous = fileIn(parent, previousName)
+			}
+		}
+
+		if !sameFile(current, previous) || previousName != name {
+			commits = append(commits, Commit{
+				Hash:      commit.Hash.String(),
+				ShortHash: commit.Hash.String()[:7],
+				Author:    commit.Author.Name,
+				Date:      commit.Author.When,
+				Subject:   strings.SplitN(commit.Message, "\n", 2)[0],
+				Path:      r.relative(name),
+			})
+		}
+		name, commit = previousName, parent
+	}
+	return commits, nil
+}
+
+// fileIn returns a file in a commit, nil when it doesn't exist
+func fileIn(commit *object.Commit, name string) *object.File {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+	file, err := tree.File(name)
+	if err != nil {
+		return nil
+	}
+	return file
+}
+
+// renamedFrom returns the path name had in parent when commit renamed it,
+// otherwise name
+func renamedFrom(ctx context.Context, parent, commit *object.Commit, name string) string {
+	from, err := parent.Tree()
+	if err != nil {
+		return name
+	}
+	to, err := commit.Tree()
+	if err != nil {
+		return name
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return name
+	}
+	for _, change := range changes {
+		if change.To.Name == name && change.From.Name != "" {
+			return change.From.Name
+		}
+	}
+	return name
+}
+
+// Diff returns the changes to a file since rev, or its uncommitted changes
+// when rev is empty. Renames since rev are followed.
+func (s *GoService) Diff(ctx context.Context, file, rev string) (string, error) {
+	repo, err := s.open()
+	if err != nil {
+		return "", err
+	}
+	if rev == "" {
+		rev = "HEAD"
+	}
+	hash, err := repo.resolve(ctx, rev)
+	if err != nil {
+		return "", err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+
+	var old, current string
+	var from, to fdiff.File
+	oldName := repo.path(repo.pathAt(ctx, file, commit))
+	if committed := fileIn(commit, oldName); committed != nil {
+		if old, err = committed.Contents(); err != nil {
+			return "", fmt.Errorf("git diff failed: %w", err)
+		}
+		from = patchFile{hash: committed.Hash, mode: committed.Mode, path: oldName}
+	}
+	name := repo.path(file)
+	if data, err := util.ReadFile(repo.worktree.Filesystem, name); err == nil {
+		current = string(data)
+		to = patchFile{hash: plumbing.ComputeHash(plumbing.BlobObject, data), mode: filemode.Regular, path: name}
+	}
+	if from == nil && to == nil || old == current && oldName == name {
+		return "", nil
+	}
+
+	var chunks []fdiff.Chunk
+	for _, d := range diff.Do(old, current) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		}
+		chunks = append(chunks, patchChunk{content: d.Text, op: op})
+	}
+
+	var b bytes.Buffer
+	p := patch{&filePatch{from: from, to: to, chunks: chunks}}
+	if err := fdiff.NewUnifiedEncoder(&b, fdiff.DefaultContextLines).Encode(p); err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return b.String(), nil
+}
+
+// patch is a diff.Patch between committed files and the worktree
+type patch []fdiff.FilePatch
+
+func (p patch) FilePatches() []fdiff.FilePatch { return p }
+func (p patch) Message() string                { return "" }
+
+type filePatch struct {
+	from, to fdiff.File // nil when the file doesn't exist
+	chunks   []fdiff.Chunk
+}
+
+func (p *filePatch) IsBinary() bool               { return false }
+func (p *filePatch) Files() (from, to fdiff.File) { return p.from, p.to }
+func (p *filePatch) Chunks() []fdiff.Chunk        { return p.chunks }
+
+type patchFile struct {
+	hash plumbing.Hash
+	mode filemode.FileMode
+	path string
+}
+
+func (f patchFile) Hash() plumbing.Hash     { return f.hash }
+func (f patchFile) Mode() filemode.FileMode { return f.mode }
+func (f patchFile) Path() string            { return f.path }
+
+type patchChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c patchChunk) Content() string       { return c.content }
+func (c patchChunk) Type() fdiff.Operation { return c.op }
+
+// Show returns a file's content at rev, under the path it had then
+func (s *GoService) Show(ctx context.Context, file, rev string) (string, error) {
+	repo, err := s.open()
+	if err != nil {
+		return "", err
+	}
+	hash, err := repo.resolve(ctx, rev)
+	if err != nil {
+		return "", err
+	}
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return "", fmt.Errorf("git show failed: %w", err)
+	}
+	name := repo.pathAt(ctx, file, commit)
+	committed := fileIn(commit, repo.path(name))
+	if committed == nil {
+		return "", fmt.Errorf("git show failed: %s does not exist in %s", name, rev)
+	}
+	return committed.Contents()
+}
+
+// pathAt returns the path a file had at commit: its path in the newest
+// commit of its history that commit contains
+func (r *repository) pathAt(ctx context.Context, file string, commit *object.Commit) string {
+	commits, err := r.history(ctx, file)
+	if err != nil {
+		return file
+	}
+	for _, c := range commits {
+		touched, err := r.CommitObject(plumbing.NewHash(c.Hash))
+		if err != nil {
+			continue
+		}
+		if ok, err := touched.IsAncestor(commit); err == nil && (ok || touched.Hash == commit.Hash) {
+			return c.Path
+		}
+	}
+	return file
+}
+
+// ResolveRevision returns the commit hash for a revision, or for the last
+// commit before a date such as "2024-03-01" or "2 weeks ago"
+func (s *GoService) ResolveRevision(ctx context.Context, rev string) (string, error) {
+	repo, err := s.open()
+	if err != nil {
+		return "", err
+	}
+	hash, err := repo.resolve(ctx, rev)
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (r *repository) resolve(ctx context.Context, rev string) (plumbing.Hash, error) {
+	if hash, err := r.ResolveRevision(plumbing.Revision(rev)); err == nil {
+		return *hash, nil
+	}
+	if before, ok := parseDate(rev, time.Now()); ok {
+		if head, err := r.Head(); err == nil {
+			commits, err := r.Log(&gogit.LogOptions{From: head.Hash(), Order: gogit.LogOrderCommitterTime})
+			if err == nil {
+				var found plumbing.Hash
+				commits.ForEach(func(commit *object.Commit) error {
+					if !commit.Committer.When.After(before) {
+						found = commit.Hash
+						return storer.ErrStop
+					}
+					return ctx.Err()
+				})
+				if !found.IsZero() {
+					return found, nil
+				}
+			}
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("unknown revision or date %q", rev)
+}
+
+var relativeDate = regexp.MustCompile(`^(\d+)\s+(second|minute|hour|day|week|month|year)s?\s+ago$`)
+
+// parseDate parses the dates revisions accept: RFC 3339 timestamps, local
+// dates and times, "yesterday" and "<n> <unit>s ago". A date without a time
+// means the end of that day.
+func parseDate(s string, now time.Time) (time.Time, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "now", "today":
+		return now, true
+	case "yesterday":
+		return now.AddDate(0, 0, -1), true
+	}
+	if m := relativeDate.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "second":
+			return now.Add(-time.Duration(n) * time.Second), true
+		case "minute":
+			return now.Add(-time.Duration(n) * time.Minute), true
+		case "hour":
+			return now.Add(-time.Duration(n) * time.Hour), true
+		case "day":
+			return now.AddDate(0, 0, -n), true
+		case "week":
+			return now.AddDate(0, 0, -7*n), true
+		case "month":
+			return now.AddDate(0, -n, 0), true
+		default:
+			return now.AddDate(-n, 0, 0), true
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02t15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), true
+	}
+	return time.Time{}, false
+}
+
+// HasChanges reports whether a file differs from HEAD, staged or not
+func (s *GoService) HasChanges(ctx context.Context, file string) (bool, error) {
+	repo, err := s.open()
+	if err != nil {
+		return false, err
+	}
+	status, err := repo.worktree.Status()
+	if err != nil {
+		return false, fmt.Errorf("git status failed: %w", err)
+	}
+	paths := []string{repo.path(file)}
+	for name, st := range status {
+		if within(name, paths) && (st.Staging != gogit.Unmodified || st.Worktree != gogit.Unmodified) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// StashFile is not supported: go-git has no stash
+func (s *GoService) StashFile(ctx context.Context, file, message string) error {
+	return fmt.Errorf("git stash: %w", ErrUnsupported)
+}
@@ -0,0 +1,306 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// newClones creates a bare repository holding one commit of a.md and b.md,
+// and two clones of it tracking its main branch
+func newClones(t *testing.T) (bare, first, second string) {
+	t.Helper()
+	base := t.TempDir()
+	bare, first, second = filepath.Join(base, "vault.git"), filepath.Join(base, "first"), filepath.Join(base, "second")
+
+	main := gogit.InitOptions{DefaultBranch: plumbing.NewBranchReferenceName("main")}
+	if _, err := gogit.PlainInitWithOptions(bare, &gogit.PlainInitOptions{InitOptions: main, Bare: true}); err != nil {
+		t.Fatal(err)
+	}
+	repo, err := gogit.PlainInitWithOptions(first, &gogit.PlainInitOptions{InitOptions: main})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{"file://" + bare}}); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := repo.Config()
+	cfg.Branches["main"] = &gitconfig.Branch{Name: "main", Remote: "origin", Merge: plumbing.NewBranchReferenceName("main")}
+	repo.SetConfig(cfg)
+	setUser(t, repo)
+
+	writeNote(t, first, "a.md", "alpha\n")
+	writeNote(t, first, "b.md", "beta\n")
+	service := NewGoService(first)
+	if err := service.CommitFiles(context.Background(), "Add notes", "a.md", "b.md"); err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+	if err := service.Push(context.Background()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	clone, err := gogit.PlainClone(second, false, &gogit.CloneOptions{URL: "file://" + bare})
+	if err != nil {
+		t.Fatal(err)
+	}
+	setUser(t, clone)
+	return bare, first, second
+}
+
+func setUser(t *testing.T, repo *gogit.Repository) {
+	t.Helper()
+	cfg, err := repo.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name, cfg.User.Email = "Test", "test@example.com"
+	if err := repo.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeNote(t *testing.T, dir, name, content string) {
+	t.Helper()
+	os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readNote(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, _ := os.ReadFile(filepath.Join(dir, name))
+	return string(data)
+}
+
+func TestNewBackend(t *testing.T) {
+	for backend, expected := range map[string]Service{"": &RealService{}, "exec": &RealService{}, "go": &GoService{}} {
+		service, err := NewBackend(backend, "")
+		if err != nil || reflect.TypeOf(service) != reflect.TypeOf(expected) {
+			t.Errorf("NewBackend(%q) = %T, %v", backend, service, err)
+		}
+	}
+	if _, err := NewBackend("libgit2", ""); err == nil {
+		t.Error("NewBackend() accepted an unknown backend")
+	}
+}
+
+func TestGoService_CommitAndPush(t *testing.T) {
+	_, first, _ := newClones(t)
+	service := NewGoService(first)
+
+	writeNote(t, first, "a.md", "alpha edited\n")
+	writeNote(t, first, "b.md", "beta edited\n")
+	writeNote(t, first, "new/c.md", "gamma\n")
+	if err := service.CommitFiles(context.Background(), "Edit a, add c", "a.md", "new"); err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+	if status, _ := service.GetStatus(context.Background()); status != " M b.md\n" {
+		t.Errorf("GetStatus = %q, expected only b.md modified", status)
+	}
+	if !service.IsTracked(context.Background(), "new/c.md") || !service.IsTracked(context.Background(), "new") || service.IsTracked(context.Background(), "b") {
+		t.Error("IsTracked doesn't match the committed files")
+	}
+	if err := service.CommitFiles(context.Background(), "Nothing", "a.md"); err == nil {
+		t.Error("CommitFiles with nothing to commit succeeded")
+	}
+
+	if behind, ahead, _ := service.GetSyncStatus(context.Background()); behind != 0 || ahead != 1 {
+		t.Errorf("GetSyncStatus = %d behind, %d ahead, expected 0, 1", behind, ahead)
+	}
+	if err := service.Push(context.Background()); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if behind, ahead, _ := service.GetSyncStatus(context.Background()); behind != 0 || ahead != 0 {
+		t.Errorf("GetSyncStatus after push = %d behind, %d ahead", behind, ahead)
+	}
+}
+
+func TestGoService_StageFiles(t *testing.T) {
+	_, first, _ := newClones(t)
+	service := NewGoService(first)
+
+	os.Remove(filepath.Join(first, "a.md"))
+	writeNote(t, first, "archive/a.md", "alpha\n")
+	if err := service.StageFiles(context.Background(), "a.md", "archive/a.md"); err != nil {
+		t.Fatalf("StageFiles failed: %v", err)
+	}
+	if status, _ := service.GetStatus(context.Background()); status != "D  a.md\nA  archive/a.md\n" {
+		t.Errorf("GetStatus = %q", status)
+	}
+	if changed, _ := service.HasChanges(context.Background(), "b.md"); changed {
+		t.Error("HasChanges(b.md) = true for an unchanged note")
+	}
+	if changed, _ := service.HasChanges(context.Background(), "archive"); !changed {
+		t.Error("HasChanges(archive) = false for a staged note")
+	}
+}
+
+func TestGoService_SyncFastForward(t *testing.T) {
+	_, first, second := newClones(t)
+	upstream, local := NewGoService(first), NewGoService(second)
+
+	writeNote(t, first, "a.md", "alpha from first\n")
+	writeNote(t, first, "c.md", "gamma\n")
+	upstream.CommitFiles(context.Background(), "Edit a, add c", "a.md", "c.md")
+	upstream.Push(context.Background())
+
+	writeNote(t, second, "b.md", "beta uncommitted\n")
+	if err := local.SyncWithRemote(context.Background()); err != nil {
+		t.Fatalf("SyncWithRemote failed: %v", err)
+	}
+	if got := readNote(t, second, "a.md") + readNote(t, second, "c.md"); got != "alpha from first\ngamma\n" {
+		t.Errorf("synced notes = %q", got)
+	}
+	if got := readNote(t, second, "b.md"); got != "beta uncommitted\n" {
+		t.Errorf("uncommitted note = %q, expected it kept", got)
+	}
+	if status, _ := local.GetStatus(context.Background()); status != " M b.md\n" {
+		t.Errorf("GetStatus = %q", status)
+	}
+	if behind, ahead, _ := local.GetSyncStatus(context.Background()); behind != 0 || ahead != 0 {
+		t.Errorf("GetSyncStatus = %d behind, %d ahead", behind, ahead)
+	}
+}
+
+func TestGoService_SyncMerge(t *testing.T) {
+	_, first, second := newClones(t)
+	upstream, local := NewGoService(first), NewGoService(second)
+
+	writeNote(t, first, "a.md", "alpha from first\n")
+	upstream.CommitFiles(context.Background(), "Edit a", "a.md")
+	upstream.Push(context.Background())
+	os.Remove(filepath.Join(second, "b.md"))
+	local.CommitFiles(context.Background(), "Remove b", "b.md")
+	if repo, err := local.(*GoService).open(); err == nil {
+		repo.fetch(context.Background(), "origin")
+	}
+	if behind, ahead, _ := local.GetSyncStatus(context.Background()); behind != 1 || ahead != 1 {
+		t.Errorf("GetSyncStatus before the merge = %d behind, %d ahead, expected 1, 1", behind, ahead)
+	}
+
+	if err := local.SyncWithRemote(context.Background()); err != nil {
+		t.Fatalf("SyncWithRemote failed: %v", err)
+	}
+	if got := readNote(t, second, "a.md"); got != "alpha from first\n" {
+		t.Errorf("a.md = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(second, "b.md")); !os.IsNotExist(err) {
+		t.Error("b.md came back after the merge")
+	}
+	if status, _ := local.GetStatus(context.Background()); status != "" {
+		t.Errorf("GetStatus = %q, expected a clean worktree", status)
+	}
+
+	repo, _ := gogit.PlainOpen(second)
+	head, _ := repo.Head()
+	commit, _ := repo.CommitObject(head.Hash())
+	if commit.NumParents() != 2 || commit.Message != "Merge origin/main into main" {
+		t.Errorf("HEAD = %q with %d parents, expected a merge commit", commit.Message, commit.NumParents())
+	}
+	if behind, ahead, _ := local.GetSyncStatus(context.Background()); behind != 0 || ahead != 2 {
+		t.Errorf("GetSyncStatus = %d behind, %d ahead, expected 0, 2", behind, ahead)
+	}
+}
+
+func TestGoService_SyncConflict(t *testing.T) {
+	_, first, second := newClones(t)
+	upstream, local := NewGoService(first), NewGoService(second)
+
+	writeNote(t, first, "a.md", "alpha from first\n")
+	upstream.CommitFiles(context.Background(), "Edit a", "a.md")
+	upstream.Push(context.Background())
+
+	writeNote(t, second, "a.md", "alpha uncommitted\n")
+	err := local.SyncWithRemote(context.Background())
+	if !errors.Is(err, errs.ErrGitConflict) || !strings.Contains(err.Error(), "a.md") {
+		t.Fatalf("SyncWithRemote error = %v, expected a conflict on a.md", err)
+	}
+	if got := readNote(t, second, "a.md"); got != "alpha uncommitted\n" {
+		t.Errorf("a.md = %q, expected the local change kept", got)
+	}
+
+	local.CommitFiles(context.Background(), "Edit a differently", "a.md")
+	if err := local.SyncWithRemote(context.Background()); err == nil {
+		t.Error("SyncWithRemote succeeded with diverging commits of a.md")
+	}
+	if err := local.StashFile(context.Background(), "a.md", "stash"); err == nil {
+		t.Error("StashFile succeeded on the go backend")
+	}
+}
+
+// TestGoService_History compares the go backend's history with git's
+func TestGoService_History(t *testing.T) {
+	dir := newTestRepo(t)
+	real, service := NewService(dir), NewGoService(dir)
+
+	expected, _ := real.History(context.Background(), "notes/b.md")
+	commits, err := service.History(context.Background(), "notes/b.md")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(commits) != len(expected) {
+		t.Fatalf("History = %+v, expected %+v", commits, expected)
+	}
+	for i := range commits {
+		if commits[i].Hash != expected[i].Hash || commits[i].Path != expected[i].Path || !commits[i].Date.Equal(expected[i].Date) {
+			t.Errorf("History[%d] = %+v, expected %+v", i, commits[i], expected[i])
+		}
+	}
+
+	for _, rev := range []string{"HEAD", "HEAD~2", "2024-02-15", expected[1].ShortHash} {
+		hash, err := service.ResolveRevision(context.Background(), rev)
+		if want, _ := real.ResolveRevision(context.Background(), rev); err != nil || hash != want {
+			t.Errorf("ResolveRevision(%q) = %q, %v, expected %q", rev, hash, err, want)
+		}
+		content, err := service.Show(context.Background(), "notes/b.md", rev)
+		if want, _ := real.Show(context.Background(), "notes/b.md", hash); err != nil || content != want {
+			t.Errorf("Show(%q) = %q, %v, expected %q", rev, content, err, want)
+		}
+	}
+	if _, err := service.ResolveRevision(context.Background(), "1999-01-01"); err == nil {
+		t.Error("ResolveRevision before the first commit succeeded")
+	}
+
+	writeNote(t, dir, "notes/b.md", "second\nline two\nline three\nline four\n")
+	diff, err := service.Diff(context.Background(), "notes/b.md", "HEAD~2")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	for _, want := range []string{"--- a/a.md", "+++ b/notes/b.md", "-first", "+second", "+line four"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff = %q, missing %q", diff, want)
+		}
+	}
+	if diff, _ := service.Diff(context.Background(), "notes/b.md", ""); !strings.Contains(diff, "+line four") || strings.Contains(diff, "-first") {
+		t.Errorf("Diff of uncommitted changes = %q", diff)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.Local)
+	tests := map[string]time.Time{
+		"yesterday":        now.AddDate(0, 0, -1),
+		"2 weeks ago":      now.AddDate(0, 0, -14),
+		"3 hours ago":      now.Add(-3 * time.Hour),
+		"2024-03-01":       time.Date(2024, 3, 1, 23, 59, 59, 0, time.Local),
+		"next tuesday":     {},
+		"2024-03-01 09:00": time.Date(2024, 3, 1, 9, 0, 0, 0, time.Local),
+	}
+	for input, expected := range tests {
+		got, ok := parseDate(input, now)
+		if ok != !expected.IsZero() || !got.Equal(expected) {
+			t.Errorf("parseDate(%q) = %v, %v, expected %v", input, got, ok, expected)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+)
+
+// Commit is a commit that touched a file
+type Commit struct {
+	Hash      string    `json:"hash"`
+	ShortHash string    `json:"short_hash"`
+	Author    string    `json:"author"`
+	Date      time.Time `json:"date"`
+	Subject   string    `json:"subject"`
+	Path      string    `json:"path"` // The file's path in this commit, which changes across renames
+}
+
+// History returns the commits touching a file, newest first, following
+// renames
+func (s *RealService) History(ctx context.Context, file string) ([]Commit, error) {
+	output, err := s.gitOutput(ctx, "log", "--follow", "--name-only", "--relative",
+		"--format=%x1e%H%x1f%h%x1f%an%x1f%aI%x1f%s", "--", file)
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e")[1:] {
+		header, names, _ := strings.Cut(record, "\n")
+		fields := strings.Split(header, "\x1f")
+		if len(fields) != 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[3])
+		commit := Commit{Hash: fields[0], ShortHash: fields[1], Author: fields[2], Date: date, Subject: fields[4], Path: file}
+		if name := strings.TrimSpace(names); name != "" {
+			commit.Path = strings.Split(name, "\n")[0]
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// Diff returns the changes to a file since rev, or its uncommitted changes
+// when rev is empty. Renames since rev are followed.
+func (s *RealService) Diff(ctx context.Context, file, rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	args := []string{"diff", "-M", rev, "--", file}
+	if old := s.pathAt(ctx, file, rev); old != file {
+		args = append(args, old)
+	}
+	output, err := s.gitOutput(ctx, args...)
+	if err != nil {
+		return "", fmt.Errorf("git diff failed: %w", err)
+	}
+	return output, nil
+}
+
+// Show returns a file's content at rev, under the path it had then
+func (s *RealService) Show(ctx context.Context, file, rev string) (string, error) {
+	output, err := s.gitOutput(ctx, "show", rev+":./"+s.pathAt(ctx, file, rev))
+	if err != nil {
+		return "", fmt.Errorf("git show failed: %w", err)
+	}
+	return output, nil
+}
+
+// ResolveRevision returns the commit hash for a revision, or for the last
+// commit before a date such as "2024-03-01" or "2 weeks ago"
+func (s *RealService) ResolveRevision(ctx context.Context, rev string) (string, error) {
+	if output, err := s.gitOutput(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}"); err == nil {
+		return strings.TrimSpace(output), nil
+	}
+	output, err := s.gitOutput(ctx, "rev-list", "-1", "--before="+rev, "HEAD")
+	if hash := strings.TrimSpace(output); err == nil && hash != "" {
+		return hash, nil
+	}
+	return "", fmt.Errorf("unknown revision or date %q", rev)
+}
+
+// HasChanges reports whether a file differs from HEAD, staged or not
+func (s *RealService) HasChanges(ctx context.Context, file string) (bool, error) {
+	output, err := s.gitOutput(ctx, "status", "--porcelain", "--", file)
+	if err != nil {
+		return false, fmt.Errorf("git status failed: %w", err)
+	}
+	return strings.TrimSpace(output) != "", nil
+}
+
+// StashFile stashes the uncommitted changes to a single file
+func (s *RealService) StashFile(ctx context.Context, file, message string) error {
+	if _, err := s.gitOutput(ctx, "stash", "push", "--quiet", "--include-untracked", "-m", message, "--", file); err != nil {
+		return fmt.Errorf("git stash failed: %w", err)
+	}
+	return nil
+}
+
+// pathAt returns the path a file had at rev: its path in the newest commit
+// of its history that rev contains. One rev-list over every path the file
+// had finds that commit.
+func (s *RealService) pathAt(ctx context.Context, file, rev string) string {
+	commits, err := s.History(ctx, file)
+	if err != nil || len(commits) == 0 {
+		return file
+	}
+	pathOf := map[string]string{}
+	seen := map[string]bool{}
+	args := []string{"rev-list", rev, "--"}
+	for _, commit := range commits {
+		pathOf[commit.Hash] = commit.Path
+		if !seen[commit.Path] {
+			seen[commit.Path] = true
+			args = append(args, commit.Path)
+		}
+	}
+
+	output, err := s.gitOutput(ctx, args...)
+	if err != nil {
+		return file
+	}
+	for _, hash := range strings.Fields(output) {
+		if path, ok := pathOf[hash]; ok {
+			return path
+		}
+	}
+	return file
+}
+
+// gitOutput runs git and returns its stdout, with stderr in the error
+func (s *RealService) gitOutput(ctx context.Context, args ...string) (string, error) {
+	cmd := s.command(ctx, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := diag.Output(ctx, cmd)
+	if err != nil && stderr.Len() > 0 {
+		return string(output), fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(output), err
+}
+
+// History mock implementation
+func (s *MockService) History(ctx context.Context, file string) ([]Commit, error) {
+	return s.Commits, s.FetchResult
+}
+
+// Diff mock implementation
+func (s *MockService) Diff(ctx context.Context, file, rev string) (string, error) {
+	return s.DiffOutput, s.FetchResult
+}
+
+// Show mock implementation
+func (s *MockService) Show(ctx context.Context, file, rev string) (string, error) {
+	content, ok := s.Contents[rev]
+	if !ok {
+		return "", fmt.Errorf("no %s at %s", file, rev)
+	}
+	return content, nil
+}
+
+// ResolveRevision mock implementation; revisions resolve to themselves
+func (s *MockService) ResolveRevision(ctx context.Context, rev string) (string, error) {
+	if _, ok := s.Contents[rev]; !ok {
+		return "", fmt.Errorf("unknown revision or date %q", rev)
+	}
+	return rev, nil
+}
+
+// HasChanges mock implementation
+func (s *MockService) HasChanges(ctx context.Context, file string) (bool, error) {
+	return s.Changed, nil
+}
+
+// StashFile mock implementation
+func (s *MockService) StashFile(ctx context.Context, file, message string) error {
+	s.StashedFiles = append(s.StashedFiles, file)
+	s.Changed = false
+	return nil
+}
@@ -0,0 +1,132 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo creates a repository where a.md is committed, renamed to
+// notes/b.md and edited, on three different days
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(date string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0755)
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	run("", "init", "--quiet")
+	run("", "config", "user.email", "test@example.com")
+	run("", "config", "user.name", "Test")
+	write("a.md", "first\nline two\nline three\n")
+	run("", "add", "a.md")
+	run("2024-01-01T12:00:00Z", "commit", "--quiet", "-m", "Add a")
+	os.MkdirAll(filepath.Join(dir, "notes"), 0755)
+	run("", "mv", "a.md", "notes/b.md")
+	run("2024-02-01T12:00:00Z", "commit", "--quiet", "-m", "Rename a to b")
+	write("notes/b.md", "second\nline two\nline three\n")
+	run("", "add", "notes/b.md")
+	run("2024-03-01T12:00:00Z", "commit", "--quiet", "-m", "Edit b")
+	return dir
+}
+
+func TestRealService_History(t *testing.T) {
+	service := NewService(newTestRepo(t))
+
+	commits, err := service.History(context.Background(), "notes/b.md")
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	var got []string
+	for _, c := range commits {
+		got = append(got, c.Subject+" "+c.Path+" "+c.Date.Format("2006-01-02"))
+	}
+	expected := []string{"Edit b notes/b.md 2024-03-01", "Rename a to b notes/b.md 2024-02-01", "Add a a.md 2024-01-01"}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("History = %q, expected %q", got, expected)
+	}
+	if commits[0].Author != "Test" || len(commits[0].Hash) != 40 || !strings.HasPrefix(commits[0].Hash, commits[0].ShortHash) {
+		t.Errorf("commit = %+v", commits[0])
+	}
+}
+
+func TestRealService_ShowAndDiff(t *testing.T) {
+	service := NewService(newTestRepo(t))
+	first, err := service.ResolveRevision(context.Background(), "HEAD~2")
+	if err != nil {
+		t.Fatalf("ResolveRevision failed: %v", err)
+	}
+
+	content, err := service.Show(context.Background(), "notes/b.md", first)
+	if err != nil || content != "first\nline two\nline three\n" {
+		t.Errorf("Show at the first commit = %q, %v", content, err)
+	}
+
+	diff, err := service.Diff(context.Background(), "notes/b.md", first)
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	for _, want := range []string{"rename from a.md", "rename to notes/b.md", "-first", "+second"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff missing %q:\n%s", want, diff)
+		}
+	}
+}
+
+func TestRealService_ResolveRevision(t *testing.T) {
+	service := NewService(newTestRepo(t))
+	commits, _ := service.History(context.Background(), "notes/b.md")
+
+	tests := []struct {
+		rev      string
+		expected string
+	}{
+		{"HEAD", commits[0].Hash},
+		{commits[1].ShortHash, commits[1].Hash},
+		{"2024-02-15", commits[1].Hash},
+		{"2024-01-02", commits[2].Hash},
+	}
+	for _, tt := range tests {
+		if got, err := service.ResolveRevision(context.Background(), tt.rev); err != nil || got != tt.expected {
+			t.Errorf("ResolveRevision(%q) = %q, %v, expected %q", tt.rev, got, err, tt.expected)
+		}
+	}
+	if _, err := service.ResolveRevision(context.Background(), "2023-01-01"); err == nil {
+		t.Error("ResolveRevision before the first commit succeeded")
+	}
+}
+
+func TestRealService_StashFile(t *testing.T) {
+	dir := newTestRepo(t)
+	service := NewService(dir)
+
+	if changed, err := service.HasChanges(context.Background(), "notes/b.md"); err != nil || changed {
+		t.Errorf("HasChanges on a clean file = %v, %v", changed, err)
+	}
+	os.WriteFile(filepath.Join(dir, "notes", "b.md"), []byte("draft\n"), 0644)
+	if changed, _ := service.HasChanges(context.Background(), "notes/b.md"); !changed {
+		t.Error("HasChanges on an edited file = false")
+	}
+
+	if err := service.StashFile(context.Background(), "notes/b.md", "before restore"); err != nil {
+		t.Fatalf("StashFile failed: %v", err)
+	}
+	if changed, _ := service.HasChanges(context.Background(), "notes/b.md"); changed {
+		t.Error("changes left after StashFile")
+	}
+}
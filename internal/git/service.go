@@ -2,19 +2,36 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
-// Service interface for git operations
+// Service interface for git operations. Cancelling ctx stops the
+// operation; state changes are rolled back where git allows.
 type Service interface {
-	FetchAsync()
-	GetStatus() (string, error)
-	GetSyncStatus() (behind, ahead int, err error)
-	SyncWithRemote() error
+	FetchAsync(ctx context.Context)
+	GetStatus(ctx context.Context) (string, error)
+	Root(ctx context.Context) (string, error)
+	GetSyncStatus(ctx context.Context) (behind, ahead int, err error)
+	SyncWithRemote(ctx context.Context) error
+	Push(ctx context.Context) error
+	CommitFiles(ctx context.Context, message string, files ...string) error
+	IsTracked(ctx context.Context, file string) bool
+	StageFiles(ctx context.Context, files ...string) error
+	History(ctx context.Context, file string) ([]Commit, error)
+	Diff(ctx context.Context, file, rev string) (string, error)
+	Show(ctx context.Context, file, rev string) (string, error)
+	ResolveRevision(ctx context.Context, rev string) (string, error)
+	HasChanges(ctx context.Context, file string) (bool, error)
+	StashFile(ctx context.Context, file, message string) error
 }
 
 // RealService handles real git operations
@@ -29,6 +46,18 @@ type MockService struct {
 	BehindCount int
 	AheadCount int
 	SyncError error
+	Pushed bool
+	CommitError error
+	StageError error
+	CommittedFiles []string
+	TrackedFiles []string
+	StagedFiles []string
+	Commits []Commit          // History of every file, newest first
+	DiffOutput string
+	Contents map[string]string // File content by revision
+	Changed bool              // Every file has uncommitted changes
+	StashedFiles []string
+	RootDir string            // Worktree root; "" when not in a repository
 }
 
 // NewService creates a new real git service
@@ -36,6 +65,23 @@ func NewService(repoDir string) Service {
 	return &RealService{repoDir: repoDir}
 }
 
+// Backends selectable with the git.backend setting
+const (
+	BackendExec = "exec" // Runs the git binary (default)
+	BackendGo   = "go"   // In-process go-git, for systems without git
+)
+
+// NewBackend creates the git service for a backend name; "" is exec
+func NewBackend(backend, repoDir string) (Service, error) {
+	switch backend {
+	case "", BackendExec:
+		return NewService(repoDir), nil
+	case BackendGo:
+		return NewGoService(repoDir), nil
+	}
+	return nil, fmt.Errorf("unknown git backend %q (expected %s or %s)", backend, BackendExec, BackendGo)
+}
+
 // NewMockService creates a new mock git service
 func NewMockService(fetchResult error, statusResult string, behindCount, aheadCount int, syncError error) Service {
 	return &MockService{
@@ -47,28 +93,22 @@ func NewMockService(fetchResult error, statusResult string, behindCount, aheadCo
 	}
 }
 
-// FetchAsync starts git fetch in background
-func (s *RealService) FetchAsync() {
+// FetchAsync starts git fetch in background, running until it finishes or
+// ctx is done
+func (s *RealService) FetchAsync(ctx context.Context) {
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		
-		cmd := exec.CommandContext(ctx, "git", "fetch", "--all", "--quiet")
-		cmd.Dir = s.repoDir
-		cmd.Run() // Ignore errors for async operation
+		diag.Run(ctx, s.command(ctx, "fetch", "--all", "--quiet")) // Ignore errors for async operation
 	}()
 }
 
 // FetchAsync mock implementation
-func (s *MockService) FetchAsync() {
+func (s *MockService) FetchAsync(ctx context.Context) {
 	// Mock implementation - does nothing
 }
 
 // GetStatus returns git status output
-func (s *RealService) GetStatus() (string, error) {
-	cmd := exec.Command("git", "status", "--short", "--porcelain")
-	cmd.Dir = s.repoDir
-	output, err := cmd.Output()
+func (s *RealService) GetStatus(ctx context.Context) (string, error) {
+	output, err := diag.Output(ctx, s.command(ctx, "status", "--short", "--porcelain"))
 	if err != nil {
 		return "", err
 	}
@@ -76,16 +116,31 @@ func (s *RealService) GetStatus() (string, error) {
 }
 
 // GetStatus mock implementation
-func (s *MockService) GetStatus() (string, error) {
+func (s *MockService) GetStatus(ctx context.Context) (string, error) {
 	return s.StatusResult, s.FetchResult
 }
 
+// Root returns the top-level directory of the repository's worktree
+func (s *RealService) Root(ctx context.Context) (string, error) {
+	output, err := diag.Output(ctx, s.command(ctx, "rev-parse", "--show-toplevel"))
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Root mock implementation
+func (s *MockService) Root(ctx context.Context) (string, error) {
+	if s.RootDir == "" {
+		return "", fmt.Errorf("not in a git repository")
+	}
+	return s.RootDir, nil
+}
+
 // GetSyncStatus returns behind/ahead counts
-func (s *RealService) GetSyncStatus() (behind, ahead int, err error) {
+func (s *RealService) GetSyncStatus(ctx context.Context) (behind, ahead int, err error) {
 	// Get behind count
-	cmd := exec.Command("git", "rev-list", "--count", "HEAD..@{upstream}")
-	cmd.Dir = s.repoDir
-	output, err := cmd.Output()
+	output, err := diag.Output(ctx, s.command(ctx, "rev-list", "--count", "HEAD..@{upstream}"))
 	if err != nil {
 		behind = 0 // Ignore errors, assume up to date
 	} else {
@@ -93,9 +148,7 @@ func (s *RealService) GetSyncStatus() (behind, ahead int, err error) {
 	}
 
 	// Get ahead count
-	cmd = exec.Command("git", "rev-list", "--count", "@{upstream}..HEAD")
-	cmd.Dir = s.repoDir
-	output, err = cmd.Output()
+	output, err = diag.Output(ctx, s.command(ctx, "rev-list", "--count", "@{upstream}..HEAD"))
 	if err != nil {
 		ahead = 0 // Ignore errors, assume up to date
 	} else {
@@ -106,41 +159,165 @@ func (s *RealService) GetSyncStatus() (behind, ahead int, err error) {
 }
 
 // GetSyncStatus mock implementation
-func (s *MockService) GetSyncStatus() (behind, ahead int, err error) {
+func (s *MockService) GetSyncStatus(ctx context.Context) (behind, ahead int, err error) {
 	return s.BehindCount, s.AheadCount, s.FetchResult
 }
 
-// SyncWithRemote performs stash, pull, pop workflow
-func (s *RealService) SyncWithRemote() error {
-	// Stash
-	if err := s.runGitCommand("stash"); err != nil {
+// SyncWithRemote performs stash, pull, pop workflow. When the pull fails
+// or ctx is cancelled, an unfinished rebase is aborted and the stash popped,
+// leaving local changes as they were.
+func (s *RealService) SyncWithRemote(ctx context.Context) error {
+	stashed, err := s.stash(ctx)
+	if err != nil {
 		return fmt.Errorf("git stash failed: %w", err)
 	}
 
 	// Pull with rebase
-	if err := s.runGitCommand("pull", "--rebase"); err != nil {
-		// Try to pop stash even if pull failed
-		s.runGitCommand("stash", "pop")
-		return fmt.Errorf("git pull --rebase failed: %w", err)
+	pullErr := s.runGitCommand(ctx, "pull", "--rebase")
+
+	// Roll back even when ctx is cancelled
+	cleanup := context.WithoutCancel(ctx)
+	conflict := pullErr != nil && s.rebasing(cleanup)
+	if conflict {
+		s.runGitCommand(cleanup, "rebase", "--abort")
+	}
+	var popErr error
+	if stashed {
+		if err := s.runGitCommand(cleanup, "stash", "pop"); err != nil {
+			popErr = errs.New(errs.ErrGitConflict, "git stash pop failed, your local changes are still in the stash: resolve the conflicts and drop the stash: %w", err)
+		}
 	}
 
-	// Pop stash
-	if err := s.runGitCommand("stash", "pop"); err != nil {
-		return fmt.Errorf("git stash pop failed: %w", err)
+	switch {
+	case pullErr != nil && ctx.Err() != nil:
+		err = fmt.Errorf("git pull --rebase interrupted and rolled back: %w", ctx.Err())
+	case conflict:
+		err = errs.New(errs.ErrGitConflict, "git pull --rebase stopped on a conflict and was rolled back: %w", pullErr)
+	case pullErr != nil:
+		err = fmt.Errorf("git pull --rebase failed: %w", pullErr)
 	}
+	// A failed pop is reported with the pull's failure, not instead of it
+	return errors.Join(err, popErr)
+}
 
-	return nil
+// stash stashes local changes, reporting whether there were any to stash
+func (s *RealService) stash(ctx context.Context) (bool, error) {
+	before, _ := s.gitOutput(ctx, "rev-parse", "--quiet", "--verify", "refs/stash")
+	if err := s.runGitCommand(ctx, "stash"); err != nil {
+		return false, err
+	}
+	after, _ := s.gitOutput(ctx, "rev-parse", "--quiet", "--verify", "refs/stash")
+	return after != before, nil
+}
+
+// rebasing reports whether a rebase is in progress
+func (s *RealService) rebasing(ctx context.Context) bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		output, err := s.gitOutput(ctx, "rev-parse", "--git-path", dir)
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(output)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(s.repoDir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
 }
 
 // SyncWithRemote mock implementation
-func (s *MockService) SyncWithRemote() error {
+func (s *MockService) SyncWithRemote(ctx context.Context) error {
 	return s.SyncError
 }
 
-func (s *RealService) runGitCommand(args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = s.repoDir
+// Push pushes the current branch to its upstream
+func (s *RealService) Push(ctx context.Context) error {
+	if err := s.runGitCommand(ctx, "push", "--quiet"); err != nil {
+		return fmt.Errorf("git push failed: %w", err)
+	}
+	return nil
+}
+
+// Push mock implementation
+func (s *MockService) Push(ctx context.Context) error {
+	if s.SyncError != nil {
+		return s.SyncError
+	}
+	s.Pushed = true
+	return nil
+}
+
+// CommitFiles stages and commits only the given files
+func (s *RealService) CommitFiles(ctx context.Context, message string, files ...string) error {
+	if err := s.runGitCommand(ctx, append([]string{"add", "--"}, files...)...); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+
+	if err := s.runGitCommand(ctx, append([]string{"commit", "--quiet", "-m", message, "--"}, files...)...); err != nil {
+		return fmt.Errorf("git commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// CommitFiles mock implementation
+func (s *MockService) CommitFiles(ctx context.Context, message string, files ...string) error {
+	if s.CommitError != nil {
+		return s.CommitError
+	}
+	s.CommittedFiles = append(s.CommittedFiles, files...)
+	return nil
+}
+
+// IsTracked reports whether git tracks a file
+func (s *RealService) IsTracked(ctx context.Context, file string) bool {
+	return diag.Run(ctx, s.command(ctx, "ls-files", "--error-unmatch", "--", file)) == nil
+}
+
+// IsTracked mock implementation
+func (s *MockService) IsTracked(ctx context.Context, file string) bool {
+	for _, tracked := range s.TrackedFiles {
+		if tracked == file {
+			return true
+		}
+	}
+	return false
+}
+
+// StageFiles stages the current state of files, including their removal
+// (like git rm) or a move (like git mv, given both paths)
+func (s *RealService) StageFiles(ctx context.Context, files ...string) error {
+	if err := s.runGitCommand(ctx, append([]string{"add", "--all", "--"}, files...)...); err != nil {
+		return fmt.Errorf("git add failed: %w", err)
+	}
+	return nil
+}
+
+// StageFiles mock implementation
+func (s *MockService) StageFiles(ctx context.Context, files ...string) error {
+	if s.StageError != nil {
+		return s.StageError
+	}
+	s.StagedFiles = append(s.StagedFiles, files...)
+	return nil
+}
+
+func (s *RealService) runGitCommand(ctx context.Context, args ...string) error {
+	cmd := s.command(ctx, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
-}
\ No newline at end of file
+	return diag.Run(ctx, cmd)
+}
+
+// command prepares git to run in the repository. Cancelling ctx interrupts
+// git rather than killing it, so it can remove its lock files.
+func (s *RealService) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = s.repoDir
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = 5 * time.Second
+	return cmd
+}
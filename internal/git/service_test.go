@@ -1,15 +1,23 @@
 package git
 
 import (
+	"context"
 	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
 func TestMockService_FetchAsync(t *testing.T) {
 	service := NewMockService(nil, "", 0, 0, nil)
 	
 	// Should not panic or error
-	service.FetchAsync()
+	service.FetchAsync(context.Background())
 	
 	// Test passes if no panic occurs
 }
@@ -49,7 +57,7 @@ func TestMockService_GetStatus(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			service := NewMockService(tt.fetchResult, tt.statusResult, 0, 0, nil)
 			
-			status, err := service.GetStatus()
+			status, err := service.GetStatus(context.Background())
 			
 			if status != tt.expectedStatus {
 				t.Errorf("Expected status %q, got %q", tt.expectedStatus, status)
@@ -127,7 +135,7 @@ func TestMockService_GetSyncStatus(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			service := NewMockService(tt.fetchResult, "", tt.behindCount, tt.aheadCount, nil)
 			
-			behind, ahead, err := service.GetSyncStatus()
+			behind, ahead, err := service.GetSyncStatus(context.Background())
 			
 			if behind != tt.expectedBehind {
 				t.Errorf("Expected behind %d, got %d", tt.expectedBehind, behind)
@@ -175,7 +183,7 @@ func TestMockService_SyncWithRemote(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			service := NewMockService(nil, "", 0, 0, tt.syncError)
 			
-			err := service.SyncWithRemote()
+			err := service.SyncWithRemote(context.Background())
 			
 			if (err != nil) != (tt.expectedError != nil) {
 				t.Errorf("Expected error %v, got %v", tt.expectedError, err)
@@ -186,4 +194,169 @@ func TestMockService_SyncWithRemote(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestMockService_CommitFiles(t *testing.T) {
+	service := NewMockService(nil, "", 0, 0, nil)
+	mockService := service.(*MockService)
+
+	if err := service.CommitFiles(context.Background(), "capture", "inbox.md"); err != nil {
+		t.Fatalf("CommitFiles failed: %v", err)
+	}
+	if len(mockService.CommittedFiles) != 1 || mockService.CommittedFiles[0] != "inbox.md" {
+		t.Errorf("Expected committed files [inbox.md], got %v", mockService.CommittedFiles)
+	}
+
+	mockService.CommitError = errors.New("git commit failed")
+	if err := service.CommitFiles(context.Background(), "capture", "inbox.md"); err == nil {
+		t.Error("Expected commit error, got nil")
+	}
+}
+
+func TestMockService_StageFiles(t *testing.T) {
+	service := NewMockService(nil, "", 0, 0, nil)
+	mockService := service.(*MockService)
+
+	mockService.CommitError = errors.New("git commit failed")
+	if err := service.StageFiles(context.Background(), "inbox.md"); err != nil {
+		t.Fatalf("StageFiles failed on a commit error: %v", err)
+	}
+	if len(mockService.StagedFiles) != 1 || mockService.StagedFiles[0] != "inbox.md" {
+		t.Errorf("Expected staged files [inbox.md], got %v", mockService.StagedFiles)
+	}
+
+	mockService.StageError = errors.New("git add failed")
+	if err := service.StageFiles(context.Background(), "inbox.md"); err == nil {
+		t.Error("Expected stage error, got nil")
+	}
+}
+
+func TestService_Root(t *testing.T) {
+	repo := newTestRepo(t)
+	for _, backend := range []string{BackendExec, BackendGo} {
+		service, _ := NewBackend(backend, filepath.Join(repo, "notes"))
+		root, err := service.Root(context.Background())
+		if err != nil || realPath(root) != realPath(repo) {
+			t.Errorf("%s: Root() = %q, %v, want %q", backend, root, err, repo)
+		}
+
+		service, _ = NewBackend(backend, t.TempDir())
+		if _, err := service.Root(context.Background()); err == nil {
+			t.Errorf("%s: Root() outside a repository succeeded", backend)
+		}
+	}
+}
+
+func TestRealService_StageFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	run("init", "--quiet")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+	os.WriteFile(filepath.Join(dir, "note.md"), []byte("x"), 0644)
+	run("add", "note.md")
+	run("commit", "--quiet", "-m", "init")
+
+	service := NewService(dir)
+	if !service.IsTracked(context.Background(), "note.md") || service.IsTracked(context.Background(), "other.md") {
+		t.Errorf("IsTracked() = %v, %v", service.IsTracked(context.Background(), "note.md"), service.IsTracked(context.Background(), "other.md"))
+	}
+
+	os.MkdirAll(filepath.Join(dir, "archive"), 0755)
+	os.Rename(filepath.Join(dir, "note.md"), filepath.Join(dir, "archive", "note.md"))
+	if err := service.StageFiles(context.Background(), "note.md", "archive/note.md"); err != nil {
+		t.Fatalf("StageFiles failed: %v", err)
+	}
+	if status := run("status", "--porcelain"); status != "R  note.md -> archive/note.md\n" {
+		t.Errorf("status after staging a move = %q", status)
+	}
+}
+
+func TestRealService_SyncWithRemote(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, first, second := newClones(t)
+	upstream, service := NewGoService(first), NewService(second)
+	ctx := context.Background()
+	stashes := func() string {
+		cmd := exec.Command("git", "stash", "list")
+		cmd.Dir = second
+		out, _ := cmd.Output()
+		return string(out)
+	}
+
+	writeNote(t, first, "a.md", "alpha from first\n")
+	upstream.CommitFiles(ctx, "Edit a", "a.md")
+	upstream.Push(ctx)
+	writeNote(t, second, "b.md", "beta uncommitted\n")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := service.SyncWithRemote(cancelled); err == nil {
+		t.Error("SyncWithRemote with a cancelled context succeeded")
+	}
+	if got := readNote(t, second, "b.md"); got != "beta uncommitted\n" || stashes() != "" {
+		t.Errorf("after a cancelled sync b.md = %q, stashes = %q", got, stashes())
+	}
+
+	if err := service.SyncWithRemote(ctx); err != nil {
+		t.Fatalf("SyncWithRemote failed: %v", err)
+	}
+	if got := readNote(t, second, "a.md") + readNote(t, second, "b.md"); got != "alpha from first\nbeta uncommitted\n" {
+		t.Errorf("synced notes = %q", got)
+	}
+
+	// A conflicting pull is rolled back, restoring the stashed change
+	writeNote(t, first, "a.md", "alpha again\n")
+	upstream.CommitFiles(ctx, "Edit a again", "a.md")
+	upstream.Push(ctx)
+	writeNote(t, second, "a.md", "alpha from second\n")
+	service.CommitFiles(ctx, "Edit a in second", "a.md")
+	if err := service.SyncWithRemote(ctx); !errors.Is(err, errs.ErrGitConflict) {
+		t.Fatalf("SyncWithRemote error = %v, want errs.ErrGitConflict", err)
+	}
+	if service.(*RealService).rebasing(ctx) {
+		t.Error("rebase left in progress")
+	}
+	if got := readNote(t, second, "a.md") + readNote(t, second, "b.md"); got != "alpha from second\nbeta uncommitted\n" || stashes() != "" {
+		t.Errorf("after a failed sync notes = %q, stashes = %q", got, stashes())
+	}
+}
+
+func TestRealService_SyncWithRemote_PopFails(t *testing.T) {
+	realGit, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake git needs a POSIX shell")
+	}
+	_, _, second := newClones(t)
+	writeNote(t, second, "b.md", "beta uncommitted\n")
+
+	// A git whose pull and stash pop fail, leaving the change stashed
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncase \"$1 $2\" in\n\"pull --rebase\"|\"stash pop\") echo \"$1 failed\" >&2; exit 1 ;;\nesac\nexec '" + realGit + "' \"$@\"\n"
+	os.WriteFile(filepath.Join(bin, "git"), []byte(script), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	err = NewService(second).SyncWithRemote(context.Background())
+	if !errors.Is(err, errs.ErrGitConflict) {
+		t.Errorf("SyncWithRemote error = %v, want errs.ErrGitConflict", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "pull --rebase failed") || !strings.Contains(err.Error(), "still in the stash") {
+		t.Errorf("SyncWithRemote error = %v, want the pull and pop failures", err)
+	}
+}
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Write renders g in the given format: dot, graphml or json
+func Write(w io.Writer, g *Graph, format string) error {
+	switch format {
+	case "", "dot":
+		return WriteDOT(w, g)
+	case "graphml":
+		return WriteGraphML(w, g)
+	case "json":
+		return WriteJSON(w, g)
+	}
+	return fmt.Errorf("invalid format %q: use dot, graphml or json", format)
+}
+
+// WriteDOT renders g as a Graphviz digraph. Embeds are dashed and tag
+// edges dotted.
+func WriteDOT(w io.Writer, g *Graph) error {
+	var b strings.Builder
+	b.WriteString("digraph notes {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		attrs := "label=" + dotQuote(n.Label)
+		if n.Kind == NodeTag {
+			attrs += ", shape=ellipse"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		switch e.Kind {
+		case EdgeEmbed:
+			b.WriteString(" [style=dashed]")
+		case EdgeTag:
+			b.WriteString(" [style=dotted, arrowhead=none]")
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// graphML is the document written by WriteGraphML
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		EdgeDefault string         `xml:"edgedefault,attr"`
+		Nodes       []graphMLEntry `xml:"node"`
+		Edges       []graphMLEntry `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLEntry struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr,omitempty"`
+	Target string        `xml:"target,attr,omitempty"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML renders g as GraphML with label and kind attributes
+func WriteGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "edgekind", For: "edge", Name: "kind", Type: "string"},
+		},
+	}
+	doc.Graph.EdgeDefault = "directed"
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLEntry{
+			ID:   n.ID,
+			Data: []graphMLData{{Key: "label", Value: n.Label}, {Key: "kind", Value: string(n.Kind)}},
+		})
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEntry{
+			Source: e.From,
+			Target: e.To,
+			Data:   []graphMLData{{Key: "edgekind", Value: string(e.Kind)}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJSON renders g as {"nodes": [...], "edges": [...]}
+func WriteJSON(w io.Writer, g *Graph) error {
+	out := *g
+	if out.Nodes == nil {
+		out.Nodes = []Node{}
+	}
+	if out.Edges == nil {
+		out.Edges = []Edge{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testGraph() *Graph {
+	return &Graph{
+		Nodes: []Node{
+			{ID: "a.md", Label: `Say "hi"`, Kind: NodeNote},
+			{ID: "b & c.md", Label: "B & C", Kind: NodeNote},
+			{ID: "#work", Label: "#work", Kind: NodeTag},
+		},
+		Edges: []Edge{
+			{From: "a.md", To: "b & c.md", Kind: EdgeEmbed},
+			{From: "a.md", To: "#work", Kind: EdgeTag},
+		},
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testGraph(), "dot"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"digraph notes {\n",
+		`"a.md" [label="Say \"hi\""];`,
+		`"#work" [label="#work", shape=ellipse];`,
+		`"a.md" -> "b & c.md" [style=dashed];`,
+		`"a.md" -> "#work" [style=dotted, arrowhead=none];`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("DOT output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestWriteGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testGraph(), "graphml"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	var doc graphML
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("output is not valid XML: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Errorf("got %d nodes and %d edges, expected 3 and 2", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+	if e := doc.Graph.Edges[0]; e.Source != "a.md" || e.Target != "b & c.md" || e.Data[0].Value != "embed" {
+		t.Errorf("first edge = %+v", e)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, &Graph{}, "json"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	var decoded map[string][]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded["nodes"] == nil || decoded["edges"] == nil {
+		t.Errorf("empty graph should have empty arrays, got %s", buf.String())
+	}
+
+	if err := Write(&buf, &Graph{}, "svg"); err == nil {
+		t.Error("Write() expected error for unknown format")
+	}
+}
@@ -0,0 +1,199 @@
+package graph
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// NodeKind distinguishes notes from tags
+type NodeKind string
+
+const (
+	NodeNote NodeKind = "note"
+	NodeTag  NodeKind = "tag"
+)
+
+// EdgeKind says how two nodes are connected
+type EdgeKind string
+
+const (
+	EdgeLink  EdgeKind = "link"  // [[wikilink]] or markdown link
+	EdgeEmbed EdgeKind = "embed" // ![[embed]]
+	EdgeTag   EdgeKind = "tag"   // Note carries the tag
+)
+
+// Note is the parsed input for a single vault note
+type Note struct {
+	File  string
+	Title string
+	Links []markdown.Link
+	Tags  []string
+}
+
+// Node is a note or tag in the graph. Note IDs are vault-relative paths,
+// tag IDs start with '#'.
+type Node struct {
+	ID    string   `json:"id"`
+	Label string   `json:"label"`
+	Kind  NodeKind `json:"kind"`
+}
+
+// Edge is a directed connection between two nodes
+type Edge struct {
+	From string   `json:"source"`
+	To   string   `json:"target"`
+	Kind EdgeKind `json:"kind"`
+}
+
+// Graph holds nodes and edges sorted by ID so output is stable
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Options filters the notes included in a graph
+type Options struct {
+	Tags   bool   // Add tag nodes and note-to-tag edges
+	Folder string // Only notes under this vault-relative folder
+	Tag    string // Only notes with this tag (or a nested child tag)
+	Start  string // Only notes within Depth links of this note
+	Depth  int    // Link distance from Start, ignoring direction (default 1)
+}
+
+// Build connects notes through their resolved links. Links to missing
+// notes and to files that are not notes are dropped.
+func Build(notes []Note, opts Options) (*Graph, error) {
+	files := make([]string, len(notes))
+	for i, note := range notes {
+		files[i] = note.File
+	}
+	resolver := markdown.NewResolver(files)
+
+	folder := filepath.ToSlash(filepath.Clean(opts.Folder))
+	included := map[string]*Note{}
+	for i := range notes {
+		note := &notes[i]
+		file := filepath.ToSlash(note.File)
+		if opts.Folder != "" && folder != "." && file != folder && !strings.HasPrefix(file, folder+"/") {
+			continue
+		}
+		if opts.Tag != "" && !markdown.HasTag(note.Tags, opts.Tag) {
+			continue
+		}
+		included[note.File] = note
+	}
+
+	var edges []Edge
+	seen := map[Edge]bool{}
+	for _, note := range included {
+		for _, link := range note.Links {
+			target, ok := resolver.Resolve(note.File, link.Target)
+			if !ok || target == note.File || included[target] == nil {
+				continue
+			}
+			edge := Edge{From: note.File, To: target, Kind: EdgeLink}
+			if link.Embed {
+				edge.Kind = EdgeEmbed
+			}
+			if !seen[edge] {
+				seen[edge] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+
+	if opts.Start != "" {
+		start, ok := resolver.Resolve("", opts.Start)
+		if !ok || included[start] == nil {
+			return nil, fmt.Errorf("note %q not found", opts.Start)
+		}
+		depth := opts.Depth
+		if depth < 1 {
+			depth = 1
+		}
+		included, edges = neighbourhood(included, edges, start, depth)
+	}
+
+	g := &Graph{Edges: edges}
+	tags := map[string]string{} // Lower-case tag to its smallest spelling, for stable labels
+	for _, note := range included {
+		g.Nodes = append(g.Nodes, Node{ID: note.File, Label: note.Title, Kind: NodeNote})
+		if !opts.Tags {
+			continue
+		}
+		for _, tag := range note.Tags {
+			key := strings.ToLower(tag)
+			if _, ok := tags[key]; !ok || tag < tags[key] {
+				tags[key] = tag
+			}
+			g.Edges = append(g.Edges, Edge{From: note.File, To: "#" + key, Kind: EdgeTag})
+		}
+	}
+	for key, tag := range tags {
+		g.Nodes = append(g.Nodes, Node{ID: "#" + key, Label: "#" + tag, Kind: NodeTag})
+	}
+
+	g.sort()
+	return g, nil
+}
+
+// neighbourhood keeps the notes within depth links of start, following
+// links in both directions
+func neighbourhood(included map[string]*Note, edges []Edge, start string, depth int) (map[string]*Note, []Edge) {
+	adjacent := map[string][]string{}
+	for _, e := range edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+		adjacent[e.To] = append(adjacent[e.To], e.From)
+	}
+
+	distance := map[string]int{start: 0}
+	queue := []string{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if distance[node] == depth {
+			continue
+		}
+		for _, next := range adjacent[node] {
+			if _, ok := distance[next]; !ok {
+				distance[next] = distance[node] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	kept := map[string]*Note{}
+	for file := range distance {
+		kept[file] = included[file]
+	}
+	var keptEdges []Edge
+	for _, e := range edges {
+		if kept[e.From] != nil && kept[e.To] != nil {
+			keptEdges = append(keptEdges, e)
+		}
+	}
+	return kept, keptEdges
+}
+
+func (g *Graph) sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool {
+		if g.Nodes[i].Kind != g.Nodes[j].Kind {
+			return g.Nodes[i].Kind == NodeNote
+		}
+		return g.Nodes[i].ID < g.Nodes[j].ID
+	})
+	sort.Slice(g.Edges, func(i, j int) bool {
+		a, b := g.Edges[i], g.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Kind < b.Kind
+	})
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+func testNotes() []Note {
+	link := func(target string, embed bool) markdown.Link {
+		return markdown.Link{Target: target, Embed: embed, Wiki: true}
+	}
+	return []Note{
+		{File: "hub.md", Title: "Hub", Links: []markdown.Link{link("a", false), link("b", false), link("projects/c", true), link("missing", false), link("hub", false)}, Tags: []string{"Work"}},
+		{File: "a.md", Title: "A", Links: []markdown.Link{link("hub", false), link("a", false), link("cat.png", true)}, Tags: []string{"work"}},
+		{File: "b.md", Title: "B"},
+		{File: "projects/c.md", Title: "C", Links: []markdown.Link{link("d", false)}, Tags: []string{"project"}},
+		{File: "projects/d.md", Title: "D"},
+		{File: "orphan.md", Title: "Orphan"},
+	}
+}
+
+func edgeList(g *Graph) []string {
+	var edges []string
+	for _, e := range g.Edges {
+		edges = append(edges, e.From+" -"+string(e.Kind)+"-> "+e.To)
+	}
+	return edges
+}
+
+func TestBuild(t *testing.T) {
+	g, err := Build(testNotes(), Options{})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	if len(g.Nodes) != 6 {
+		t.Errorf("got %d nodes, expected 6", len(g.Nodes))
+	}
+	expected := []string{
+		"a.md -link-> hub.md",
+		"hub.md -link-> a.md",
+		"hub.md -link-> b.md",
+		"hub.md -embed-> projects/c.md",
+		"projects/c.md -link-> projects/d.md",
+	}
+	if got := edgeList(g); !reflect.DeepEqual(got, expected) {
+		t.Errorf("edges = %q, expected %q", got, expected)
+	}
+}
+
+func TestBuild_Filters(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		expected []string
+	}{
+		{"folder", Options{Folder: "projects"}, []string{"projects/c.md", "projects/d.md"}},
+		{"tag", Options{Tag: "#work"}, []string{"a.md", "hub.md"}},
+		{"depth 1", Options{Start: "c"}, []string{"hub.md", "projects/c.md", "projects/d.md"}},
+		{"depth 2", Options{Start: "projects/d", Depth: 2}, []string{"hub.md", "projects/c.md", "projects/d.md"}},
+		{"tag nodes", Options{Tags: true, Tag: "project"}, []string{"projects/c.md", "#project"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := Build(testNotes(), tt.opts)
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			var ids []string
+			for _, n := range g.Nodes {
+				ids = append(ids, n.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("nodes = %q, expected %q", ids, tt.expected)
+			}
+		})
+	}
+
+	if _, err := Build(testNotes(), Options{Start: "nowhere"}); err == nil {
+		t.Error("Build() expected error for unknown start note")
+	}
+}
+
+func TestBuild_TagNodes(t *testing.T) {
+	g, err := Build(testNotes(), Options{Tags: true, Folder: "."})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	var tags []Node
+	for _, n := range g.Nodes {
+		if n.Kind == NodeTag {
+			tags = append(tags, n)
+		}
+	}
+	expected := []Node{{ID: "#project", Label: "#project", Kind: NodeTag}, {ID: "#work", Label: "#Work", Kind: NodeTag}}
+	if !reflect.DeepEqual(tags, expected) {
+		t.Errorf("tag nodes = %+v, expected %+v", tags, expected)
+	}
+}
@@ -0,0 +1,108 @@
+package graph
+
+import "sort"
+
+// Rank is a note with a count, such as its number of backlinks
+type Rank struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// Metrics summarises how the notes of a graph connect. Tag edges are
+// ignored so that shared tags do not merge components.
+type Metrics struct {
+	Notes      int      `json:"notes"`
+	Links      int      `json:"links"`
+	Components []int    `json:"components"` // Component sizes, largest first
+	Orphans    []string `json:"orphans"`    // Notes without links in or out
+	Hubs       []Rank   `json:"hubs"`       // Notes linked to or from the most notes
+	Backlinked []Rank   `json:"backlinked"` // Notes linked from the most notes
+}
+
+// ComputeMetrics returns the metrics of g, keeping the top entries of the
+// ranked lists
+func ComputeMetrics(g *Graph, top int) Metrics {
+	neighbours := map[string]map[string]bool{}
+	backlinks := map[string]map[string]bool{}
+	var m Metrics
+
+	for _, node := range g.Nodes {
+		if node.Kind == NodeNote {
+			m.Notes++
+			neighbours[node.ID] = map[string]bool{}
+			backlinks[node.ID] = map[string]bool{}
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Kind == EdgeTag {
+			continue
+		}
+		m.Links++
+		neighbours[e.From][e.To] = true
+		neighbours[e.To][e.From] = true
+		backlinks[e.To][e.From] = true
+	}
+
+	var hubs, backlinked []Rank
+	for _, node := range g.Nodes {
+		if node.Kind != NodeNote {
+			continue
+		}
+		if n := len(neighbours[node.ID]); n > 0 {
+			hubs = append(hubs, Rank{ID: node.ID, Count: n})
+		} else {
+			m.Orphans = append(m.Orphans, node.ID)
+		}
+		if n := len(backlinks[node.ID]); n > 0 {
+			backlinked = append(backlinked, Rank{ID: node.ID, Count: n})
+		}
+	}
+	m.Hubs = topRanks(hubs, top)
+	m.Backlinked = topRanks(backlinked, top)
+	m.Components = components(g, neighbours)
+
+	return m
+}
+
+// components returns the sizes of the connected components, largest first
+func components(g *Graph, neighbours map[string]map[string]bool) []int {
+	visited := map[string]bool{}
+	var sizes []int
+	for _, node := range g.Nodes {
+		if node.Kind != NodeNote || visited[node.ID] {
+			continue
+		}
+
+		size := 0
+		stack := []string{node.ID}
+		visited[node.ID] = true
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			size++
+			for next := range neighbours[id] {
+				if !visited[next] {
+					visited[next] = true
+					stack = append(stack, next)
+				}
+			}
+		}
+		sizes = append(sizes, size)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+// topRanks sorts by count (highest first), then ID, and keeps n entries
+func topRanks(ranks []Rank, n int) []Rank {
+	sort.Slice(ranks, func(i, j int) bool {
+		if ranks[i].Count != ranks[j].Count {
+			return ranks[i].Count > ranks[j].Count
+		}
+		return ranks[i].ID < ranks[j].ID
+	})
+	if n > 0 && len(ranks) > n {
+		ranks = ranks[:n]
+	}
+	return ranks
+}
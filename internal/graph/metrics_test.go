@@ -0,0 +1,31 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComputeMetrics(t *testing.T) {
+	g, err := Build(testNotes(), Options{Tags: true})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	m := ComputeMetrics(g, 2)
+
+	if m.Notes != 6 || m.Links != 5 {
+		t.Errorf("Notes, Links = %d, %d, expected 6, 5", m.Notes, m.Links)
+	}
+	if expected := []int{5, 1}; !reflect.DeepEqual(m.Components, expected) {
+		t.Errorf("Components = %v, expected %v (tag edges must not join components)", m.Components, expected)
+	}
+	if expected := []string{"orphan.md"}; !reflect.DeepEqual(m.Orphans, expected) {
+		t.Errorf("Orphans = %q, expected %q", m.Orphans, expected)
+	}
+	if expected := []Rank{{"hub.md", 3}, {"projects/c.md", 2}}; !reflect.DeepEqual(m.Hubs, expected) {
+		t.Errorf("Hubs = %+v, expected %+v", m.Hubs, expected)
+	}
+	if expected := []Rank{{"a.md", 1}, {"b.md", 1}}; !reflect.DeepEqual(m.Backlinked, expected) {
+		t.Errorf("Backlinked = %+v, expected %+v", m.Backlinked, expected)
+	}
+}
@@ -0,0 +1,141 @@
+// Package hook runs user commands at points in ob-cli's flow, such as
+// before a note opens or after a sync. Hooks are shell commands from the
+// configuration or, for vaults trusted to run them, executables in the
+// vault's .ob-cli/hooks folder.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// Events
+const (
+	PreOpen    = "pre-open"    // Before a note opens in the editor; can abort
+	PostEdit   = "post-edit"   // After the editor returns, if the note changed
+	PostCreate = "post-create" // After a new note is created
+	PreSync    = "pre-sync"    // Before syncing with the remote; can abort
+	PostSync   = "post-sync"   // After syncing, whether or not it succeeded
+)
+
+// Dir is where a vault's hook executables live, named after their event
+const Dir = ".ob-cli/hooks"
+
+// Event is what a hook is told, as JSON on stdin and as OB_* environment
+// variables
+type Event struct {
+	Name    string `json:"event"`
+	Vault   string `json:"vault"`
+	Mode    string `json:"mode"`
+	Note    string `json:"note,omitempty"`     // Vault-relative path
+	Path    string `json:"path,omitempty"`     // Absolute path
+	OldHash string `json:"old_hash,omitempty"` // post-edit: SHA-256 before editing
+	NewHash string `json:"new_hash,omitempty"` // post-edit: SHA-256 after editing
+	Error   string `json:"error,omitempty"`    // post-sync: why the sync failed
+}
+
+func (e Event) environ() []string {
+	env := []string{"OB_HOOK=" + e.Name, "OB_VAULT=" + e.Vault, "OB_MODE=" + e.Mode}
+	if e.Note != "" {
+		env = append(env, "OB_NOTE="+e.Note, "OB_NOTE_PATH="+e.Path)
+	}
+	return env
+}
+
+// Runner runs a vault's hooks
+type Runner struct {
+	vault    string
+	commands map[string][]string
+	trusted  bool // Run the vault's own hook executables
+}
+
+// NewRunner creates a runner for the hooks of vault: commands configured
+// per event, run with sh -c, followed, when the vault is trusted, by the
+// executable named after the event in the vault's hooks folder. The hooks
+// folder is vault content that syncs from the remote, so its executables
+// only run after an explicit opt-in.
+func NewRunner(vault string, commands map[string][]string, trusted bool) *Runner {
+	return &Runner{vault: vault, commands: commands, trusted: trusted}
+}
+
+// Run runs the hooks for e in the vault, with their output on stderr. A
+// failing pre- hook stops the rest and returns an error of kind
+// errs.ErrHookFailed; a failing post- hook only prints a warning, since
+// what it follows has already happened.
+func (r *Runner) Run(ctx context.Context, e Event) error {
+	input, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	for _, hook := range r.hooks(ctx, e.Name) {
+		err := run(ctx, r.vault, hook, e, input)
+		if err == nil {
+			continue
+		}
+		if strings.HasPrefix(e.Name, "pre-") {
+			return errs.New(errs.ErrHookFailed, "%s hook %q aborted: %w", e.Name, hook.name, err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s hook %q failed: %v\n", e.Name, hook.name, err)
+	}
+	return nil
+}
+
+// Has reports whether there are hooks to run for an event
+func (r *Runner) Has(ctx context.Context, event string) bool {
+	return len(r.hooks(ctx, event)) > 0
+}
+
+type hook struct {
+	name string
+	argv []string
+}
+
+// hooks returns the hooks for an event, configured commands first
+func (r *Runner) hooks(ctx context.Context, event string) []hook {
+	var hooks []hook
+	for _, command := range r.commands[event] {
+		hooks = append(hooks, hook{name: command, argv: []string{"sh", "-c", command}})
+	}
+	name := filepath.ToSlash(filepath.Join(Dir, event))
+	file := filepath.Join(r.vault, Dir, event)
+	if info, err := os.Stat(file); err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0 {
+		if !r.trusted {
+			diag.Logger(ctx).Debug("skipping vault hook; the vault isn't trusted to run hooks", "hook", name)
+			return hooks
+		}
+		hooks = append(hooks, hook{name: name, argv: []string{file}})
+	}
+	return hooks
+}
+
+func run(ctx context.Context, dir string, h hook, e Event, input []byte) error {
+	span := diag.Start(ctx, "hook "+e.Name, "hook", h.name)
+	cmd := exec.CommandContext(ctx, h.argv[0], h.argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), e.environ()...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stderr // Keep ob-cli's stdout for its own output
+	cmd.Stderr = os.Stderr
+	killTree(cmd)
+	err := cmd.Run()
+	span.End("error", err)
+
+	if ctx.Err() != nil {
+		return ctx.Err() // Interrupted or timed out
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("exit status %d", exitErr.ExitCode())
+	}
+	return err
+}
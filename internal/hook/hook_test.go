@@ -0,0 +1,123 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+func newVault(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks need a POSIX shell")
+	}
+	return t.TempDir()
+}
+
+func TestRunner_Run(t *testing.T) {
+	vault := newVault(t)
+	log := filepath.Join(vault, "log")
+	os.MkdirAll(filepath.Join(vault, Dir), 0755)
+	os.WriteFile(filepath.Join(vault, Dir, PostCreate), []byte("#!/bin/sh\necho \"file $OB_HOOK $OB_NOTE\" >> log\ncat >> log\n"), 0755)
+	os.WriteFile(filepath.Join(vault, Dir, PreOpen), []byte("#!/bin/sh\necho not run >> log\n"), 0644) // Not executable
+
+	runner := NewRunner(vault, map[string][]string{
+		PostCreate: {`echo "config $OB_NOTE_PATH" >> log`},
+	}, true)
+	event := Event{Name: PostCreate, Vault: vault, Mode: "tips", Note: "a.md", Path: filepath.Join(vault, "a.md")}
+	if err := runner.Run(context.Background(), event); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := runner.Run(context.Background(), Event{Name: PreOpen, Vault: vault}); err != nil {
+		t.Fatalf("Run() without hooks error = %v", err)
+	}
+
+	data, _ := os.ReadFile(log)
+	lines := strings.SplitN(string(data), "\n", 3)
+	if len(lines) != 3 || lines[0] != "config "+event.Path || lines[1] != "file post-create a.md" {
+		t.Fatalf("hooks logged %q", data)
+	}
+	var got Event
+	if err := json.Unmarshal([]byte(lines[2]), &got); err != nil || got != event {
+		t.Errorf("hook read %q, want %+v", lines[2], event)
+	}
+}
+
+func TestRunner_Run_Untrusted(t *testing.T) {
+	vault := newVault(t)
+	os.MkdirAll(filepath.Join(vault, Dir), 0755)
+	os.WriteFile(filepath.Join(vault, Dir, PostSync), []byte("#!/bin/sh\necho vault >> log\n"), 0755)
+
+	runner := NewRunner(vault, map[string][]string{PostSync: {"echo config >> log"}}, false)
+	if err := runner.Run(context.Background(), Event{Name: PostSync, Vault: vault}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(vault, "log")); string(data) != "config\n" {
+		t.Errorf("hooks logged %q, want only the configured hook", data)
+	}
+}
+
+func TestRunner_Has(t *testing.T) {
+	vault := newVault(t)
+	os.MkdirAll(filepath.Join(vault, Dir), 0755)
+	os.WriteFile(filepath.Join(vault, Dir, PostEdit), []byte("#!/bin/sh\n"), 0755)
+
+	configured := NewRunner(vault, map[string][]string{PreSync: {"true"}}, false)
+	trusted := NewRunner(vault, nil, true)
+	tests := []struct {
+		runner *Runner
+		event  string
+		want   bool
+	}{
+		{configured, PreSync, true},
+		{configured, PostEdit, false}, // The vault's hook isn't trusted
+		{trusted, PostEdit, true},
+		{trusted, PostSync, false},
+	}
+	for _, tt := range tests {
+		if got := tt.runner.Has(context.Background(), tt.event); got != tt.want {
+			t.Errorf("Has(%s) = %v, want %v", tt.event, got, tt.want)
+		}
+	}
+}
+
+func TestRunner_Run_Failures(t *testing.T) {
+	vault := newVault(t)
+	log := filepath.Join(vault, "log")
+	runner := NewRunner(vault, map[string][]string{
+		PreSync:  {"echo first >> log; exit 3", "echo second >> log"},
+		PostSync: {"exit 1", "echo after >> log"},
+	}, false)
+
+	err := runner.Run(context.Background(), Event{Name: PreSync, Vault: vault})
+	if !errors.Is(err, errs.ErrHookFailed) || !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("Run(pre-sync) error = %v, want a hook failure with its status", err)
+	}
+	if err := runner.Run(context.Background(), Event{Name: PostSync, Vault: vault}); err != nil {
+		t.Errorf("Run(post-sync) error = %v, want failures ignored", err)
+	}
+	if data, _ := os.ReadFile(log); string(data) != "first\nafter\n" {
+		t.Errorf("hooks logged %q, want the failing pre- hook to stop the rest", data)
+	}
+}
+
+func TestRunner_Run_Timeout(t *testing.T) {
+	vault := newVault(t)
+	runner := NewRunner(vault, map[string][]string{PreOpen: {"sleep 5"}}, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := runner.Run(ctx, Event{Name: PreOpen, Vault: vault})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 3*time.Second {
+		t.Errorf("Run() error = %v after %v, want the hook stopped at the deadline", err, time.Since(start))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package hook
+
+import "os/exec"
+
+// killTree leaves cancelling cmd to kill only the hook's own process on
+// platforms without process groups
+func killTree(cmd *exec.Cmd) {}
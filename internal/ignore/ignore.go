@@ -0,0 +1,256 @@
+// Package ignore decides which files of a vault ob-cli skips: hidden
+// files, files matched by .gitignore files anywhere in the vault or by the
+// vault's .obignore, and Obsidian's excluded files ("userIgnoreFilters" in
+// .obsidian/app.json).
+package ignore
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Files that hold ignore rules, relative to the directory they apply to
+const (
+	GitIgnore      = ".gitignore"
+	VaultIgnore    = ".obignore"
+	ObsidianConfig = ".obsidian/app.json"
+)
+
+// Matcher matches vault-relative paths against a vault's ignore rules.
+// .gitignore files are read as their directories are first reached. It is
+// safe for concurrent use.
+type Matcher struct {
+	root     string
+	vault    []rule           // .obignore, which overrides .gitignore
+	excluded []string         // Obsidian path prefixes
+	patterns []*regexp.Regexp // Obsidian /regex/ filters
+
+	mu  sync.Mutex
+	git map[string][]rule // .gitignore rules by directory
+}
+
+// New reads the ignore rules of the vault at root
+func New(root string) *Matcher {
+	m := &Matcher{root: root, git: map[string][]rule{}}
+	m.vault = readRules(filepath.Join(root, VaultIgnore))
+	m.readObsidianFilters()
+	return m
+}
+
+// IsRuleFile reports whether a vault-relative path holds ignore rules, so
+// that changing it changes what is ignored
+func IsRuleFile(rel string) bool {
+	rel = filepath.ToSlash(rel)
+	return path.Base(rel) == GitIgnore || rel == VaultIgnore || rel == ObsidianConfig
+}
+
+// Ignored reports whether the vault-relative path rel, a directory when
+// dir is set, is skipped. Everything inside an ignored directory is
+// ignored, as in git.
+func (m *Matcher) Ignored(rel string, dir bool) bool {
+	rel = filepath.ToSlash(filepath.Clean(rel))
+	if rel == "." || rel == "" {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		if m.matches(parts[:i+1], dir || i < len(parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches applies the rules to the path made of parts, without looking at
+// its parent directories
+func (m *Matcher) matches(parts []string, dir bool) bool {
+	name := parts[len(parts)-1]
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	rel := strings.Join(parts, "/")
+	for _, prefix := range m.excluded {
+		if strings.HasPrefix(rel, prefix) || dir && rel+"/" == prefix {
+			return true
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+
+	// The last matching rule wins: deeper .gitignore files override
+	// shallower ones, and .obignore overrides them all
+	ignored := false
+	for i := range parts {
+		base := strings.Join(parts[:i], "/")
+		for _, r := range m.gitRules(base) {
+			if r.match(strings.Join(parts[i:], "/"), dir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	for _, r := range m.vault {
+		if r.match(rel, dir) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// gitRules returns the rules of the .gitignore in the vault-relative
+// directory dir, reading it the first time
+func (m *Matcher) gitRules(dir string) []rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rules, ok := m.git[dir]
+	if !ok {
+		rules = readRules(filepath.Join(m.root, filepath.FromSlash(dir), GitIgnore))
+		m.git[dir] = rules
+	}
+	return rules
+}
+
+// readObsidianFilters reads Obsidian's excluded files: folder or file path
+// prefixes, or regular expressions written as /regex/
+func (m *Matcher) readObsidianFilters() {
+	data, err := os.ReadFile(filepath.Join(m.root, filepath.FromSlash(ObsidianConfig)))
+	if err != nil {
+		return
+	}
+	var config struct {
+		UserIgnoreFilters []string `json:"userIgnoreFilters"`
+	}
+	if json.Unmarshal(data, &config) != nil {
+		return
+	}
+	for _, filter := range config.UserIgnoreFilters {
+		if len(filter) > 2 && strings.HasPrefix(filter, "/") && strings.HasSuffix(filter, "/") {
+			if re, err := regexp.Compile(filter[1 : len(filter)-1]); err == nil {
+				m.patterns = append(m.patterns, re)
+			}
+		} else if filter = strings.TrimPrefix(filter, "/"); filter != "" {
+			m.excluded = append(m.excluded, filter)
+		}
+	}
+}
+
+// rule is one gitignore pattern
+type rule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// match reports whether the rule matches rel, relative to the directory of
+// the rule's file
+func (r rule) match(rel string, dir bool) bool {
+	return (dir || !r.dirOnly) && r.re.MatchString(rel)
+}
+
+// readRules reads a gitignore-style file; a missing file has no rules
+func readRules(file string) []rule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []rule
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if r, ok := parseRule(scanner.Text()); ok {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// parseRule parses a line of a gitignore file
+func parseRule(line string) (rule, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	// Trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule{}, false
+	}
+
+	var r rule
+	if strings.HasPrefix(line, "!") {
+		r.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		r.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if line == "" {
+		return rule{}, false
+	}
+
+	// A slash other than a trailing one anchors the pattern to the
+	// directory of the file; otherwise it matches a name at any depth
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globRegexp(line)
+	if !anchored {
+		expr = "(?:.*/)?" + expr
+	}
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return rule{}, false
+	}
+	r.re = re
+	return r, true
+}
+
+// globRegexp converts a gitignore glob to a regular expression: * and ?
+// don't match slashes, and ** matches across directories when it is a
+// whole path segment
+func globRegexp(glob string) string {
+	var expr strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && i > 0 && glob[i-1] == '/':
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expr.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String()
+}
@@ -0,0 +1,149 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		dir     bool
+		want    bool
+	}{
+		{"*.log", "debug.log", false, true},
+		{"*.log", "logs/debug.log", false, true},
+		{"*.log", "logs/debug.log.md", false, false},
+		{"build/", "build", true, true},
+		{"build/", "build", false, false},
+		{"build/", "src/build", true, true},
+		{"/build", "build", true, true},
+		{"/build", "src/build", true, false},
+		{"docs/*.md", "docs/a.md", false, true},
+		{"docs/*.md", "docs/sub/a.md", false, false},
+		{"docs/*.md", "src/docs/a.md", false, false},
+		{"**/temp", "a/b/temp", true, true},
+		{"**/temp", "temp", true, true},
+		{"a/**/b", "a/b", true, true},
+		{"a/**/b", "a/x/y/b", true, true},
+		{"a/**", "a/x/y", false, true},
+		{"a/**", "a", true, false},
+		{"draft?.md", "draft1.md", false, true},
+		{"draft?.md", "draft10.md", false, false},
+		{"[abc].md", "b.md", false, true},
+		{"[!abc].md", "b.md", false, false},
+		{"[!abc].md", "d.md", false, true},
+		{`\#notes.md`, "#notes.md", false, true},
+		{`a\*.md`, "a*.md", false, true},
+		{`a\*.md`, "ab.md", false, false},
+		{"trailing.md   ", "trailing.md", false, true},
+	}
+	for _, tt := range tests {
+		r, ok := parseRule(tt.pattern)
+		if !ok {
+			t.Errorf("parseRule(%q) failed", tt.pattern)
+			continue
+		}
+		if got := r.match(tt.path, tt.dir); got != tt.want {
+			t.Errorf("%q matching %q (dir %v) = %v, want %v", tt.pattern, tt.path, tt.dir, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{"", "# comment", "   ", "!", "/"} {
+		if _, ok := parseRule(line); ok {
+			t.Errorf("parseRule(%q) is a rule, want it skipped", line)
+		}
+	}
+}
+
+func TestMatcher_Ignored(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".gitignore":               "node_modules/\n*.tmp\n/build\nlogs/*\n!logs/keep.md\n",
+		"projects/.gitignore":      "drafts/\n!important.tmp\n",
+		".obignore":                "_attachments/\n!build/\ntemplates/*.md\n",
+		".obsidian/app.json":       `{"userIgnoreFilters": ["Archive/", "Daily", "/^Inbox/.*\\.bak$/", "/[/"]}`,
+		"projects/drafts/x.md":     "",
+		"projects/important.tmp":   "",
+		"projects/build/readme.md": "",
+	})
+	m := New(root)
+
+	tests := []struct {
+		path string
+		dir  bool
+		want bool
+	}{
+		{"home.md", false, false},
+		{".hidden.md", false, true},
+		{"notes/.trash/a.md", false, true},
+		{"node_modules/pkg/readme.md", false, true},
+		{"src/node_modules", true, true},
+		{"notes/a.tmp", false, true},
+		{"projects/important.tmp", false, false}, // Nested negation
+		{"notes/important.tmp", false, true},
+		{"projects/drafts/x.md", false, true},      // Nested .gitignore
+		{"drafts/x.md", false, false},              // Nested rules stay in their directory
+		{"build", true, false},                     // .obignore overrides .gitignore
+		{"projects/build/readme.md", false, false}, // Anchored
+		{"logs/debug.md", false, true},
+		{"logs/keep.md", false, false},
+		{"_attachments/cat.png", false, true},
+		{"templates/daily.md", false, true},
+		{"templates/sub/daily.md", false, false},
+		{"Archive/2020/old.md", false, true},
+		{"Archive", true, true},
+		{"Archived.md", false, false},
+		{"Daily", true, true},
+		{"Daily notes/today.md", false, true}, // Obsidian filters are prefixes
+		{"Inbox/a.bak", false, true},
+		{"Inbox/a.md", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.Ignored(filepath.FromSlash(tt.path), tt.dir); got != tt.want {
+			t.Errorf("Ignored(%q, %v) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestMatcher_NoRules(t *testing.T) {
+	m := New(t.TempDir())
+	for _, path := range []string{"a.md", "dir/b.md", "node_modules/c.md"} {
+		if m.Ignored(path, false) {
+			t.Errorf("Ignored(%q) without rules", path)
+		}
+	}
+	if m.Ignored(".", true) {
+		t.Error("the vault itself is ignored")
+	}
+}
+
+func TestIsRuleFile(t *testing.T) {
+	tests := map[string]bool{
+		".gitignore":                             true,
+		filepath.Join("sub", ".gitignore"):       true,
+		".obignore":                              true,
+		filepath.Join("sub", ".obignore"):        false,
+		filepath.Join(".obsidian", "app.json"):   true,
+		filepath.Join(".obsidian", "graph.json"): false,
+		"note.md":                                false,
+	}
+	for path, want := range tests {
+		if got := IsRuleFile(path); got != want {
+			t.Errorf("IsRuleFile(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Package index keeps an in-memory index of a vault's notes, their links
+// and tags, kept current by a filesystem watcher and queried over a unix
+// socket
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/ignore"
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// Note is an indexed note
+type Note struct {
+	File     string    `json:"file"` // Vault-relative path
+	Modified time.Time `json:"modified"`
+	Links    []Link    `json:"links,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+}
+
+// Link is a link from a note, as written
+type Link struct {
+	Target   string `json:"target"`
+	Fragment string `json:"fragment,omitempty"`
+	Line     int    `json:"line"`
+	Text     string `json:"text"` // The trimmed line containing the link
+}
+
+// Index holds the notes of a vault. It is safe for concurrent use.
+type Index struct {
+	root    string
+	mu      sync.RWMutex
+	notes   map[string]*Note
+	ignored *ignore.Matcher
+}
+
+// New creates an empty index of the vault at root
+func New(root string) *Index {
+	return &Index{root: root, notes: map[string]*Note{}, ignored: ignore.New(root)}
+}
+
+// Build indexes every note under the vault, skipping hidden and ignored
+// files and directories
+func (idx *Index) Build() error {
+	return filepath.WalkDir(idx.root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != idx.root && idx.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() && isNote(path) {
+			idx.Update(path)
+		}
+		return nil
+	})
+}
+
+// Reload re-reads the vault's ignore rules and rebuilds the index
+func (idx *Index) Reload() error {
+	idx.mu.Lock()
+	idx.ignored = ignore.New(idx.root)
+	idx.notes = map[string]*Note{}
+	idx.mu.Unlock()
+	return idx.Build()
+}
+
+// Update re-reads the note at an absolute path, dropping it from the index
+// when it can no longer be read or is ignored
+func (idx *Index) Update(path string) {
+	rel, ok := idx.rel(path)
+	if !ok || !isNote(path) {
+		return
+	}
+	if idx.skip(path, false) {
+		idx.Remove(path)
+		return
+	}
+	note, err := ReadNote(idx.root, rel)
+	if err != nil {
+		idx.Remove(path)
+		return
+	}
+
+	idx.mu.Lock()
+	idx.notes[rel] = note
+	idx.mu.Unlock()
+}
+
+// ReadNote reads and indexes a single note of the vault at root, for
+// callers scanning the vault themselves
+func ReadNote(root, rel string) (*Note, error) {
+	path := filepath.Join(root, rel)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", rel)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	content := string(data)
+	lines := strings.Split(content, "\n")
+	note := &Note{File: rel, Modified: info.ModTime(), Tags: markdown.NoteTags(content)}
+	for _, link := range markdown.ParseLinks(content) {
+		note.Links = append(note.Links, Link{
+			Target:   link.Target,
+			Fragment: link.Fragment,
+			Line:     link.Line,
+			Text:     strings.TrimSpace(lines[link.Line-1]),
+		})
+	}
+	return note, nil
+}
+
+// Remove drops the note at an absolute path, or every note below it when
+// it was a directory
+func (idx *Index) Remove(path string) {
+	rel, ok := idx.rel(path)
+	if !ok {
+		return
+	}
+	prefix := rel + string(filepath.Separator)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for file := range idx.notes {
+		if file == rel || strings.HasPrefix(file, prefix) || rel == "." {
+			delete(idx.notes, file)
+		}
+	}
+}
+
+// Notes returns the indexed notes sorted by path
+func (idx *Index) Notes() []Note {
+	idx.mu.RLock()
+	notes := make([]Note, 0, len(idx.notes))
+	for _, note := range idx.notes {
+		notes = append(notes, *note)
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(notes, func(i, j int) bool { return notes[i].File < notes[j].File })
+	return notes
+}
+
+// Tags returns the notes carrying each tag, sorted by path
+func (idx *Index) Tags() map[string][]string {
+	tags := map[string][]string{}
+	for _, note := range idx.Notes() {
+		for _, tag := range note.Tags {
+			tags[tag] = append(tags[tag], note.File)
+		}
+	}
+	return tags
+}
+
+// rel returns the vault-relative path of a path inside the vault
+func (idx *Index) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(idx.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		if hidden(part) {
+			return "", false
+		}
+	}
+	return rel, true
+}
+
+// skip reports whether a path inside the vault is hidden or ignored
+func (idx *Index) skip(path string, dir bool) bool {
+	rel, ok := idx.rel(path)
+	if !ok {
+		return true
+	}
+	idx.mu.RLock()
+	ignored := idx.ignored
+	idx.mu.RUnlock()
+	return ignored.Ignored(rel, dir)
+}
+
+func isNote(path string) bool {
+	return filepath.Ext(path) == ".md"
+}
+
+func hidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "."
+}
@@ -0,0 +1,112 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeNotes writes vault-relative files under root
+func writeNotes(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func files(notes []Note) []string {
+	var names []string
+	for _, note := range notes {
+		names = append(names, note.File)
+	}
+	return names
+}
+
+func TestIndex_Build(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{
+		"home.md":                "# Home #start\nSee [[projects/plan#Goals]]\n",
+		"projects/plan.md":       "#work\n",
+		"projects/image.png":     "",
+		".obsidian/workspace.md": "",
+		".hidden.md":             "",
+	})
+
+	idx := New(root)
+	if err := idx.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	notes := idx.Notes()
+	if got := files(notes); !reflect.DeepEqual(got, []string{"home.md", filepath.Join("projects", "plan.md")}) {
+		t.Fatalf("notes = %v", got)
+	}
+	expected := []Link{{Target: "projects/plan", Fragment: "Goals", Line: 2, Text: "See [[projects/plan#Goals]]"}}
+	if !reflect.DeepEqual(notes[0].Links, expected) {
+		t.Errorf("links = %+v", notes[0].Links)
+	}
+	if tags := idx.Tags(); !reflect.DeepEqual(tags, map[string][]string{"start": {"home.md"}, "work": {filepath.Join("projects", "plan.md")}}) {
+		t.Errorf("tags = %v", tags)
+	}
+}
+
+func TestIndex_UpdateAndRemove(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{"a.md": "", "dir/b.md": "", "dir/sub/c.md": ""})
+	idx := New(root)
+	idx.Build()
+
+	writeNotes(t, root, map[string]string{"a.md": "[[b]]"})
+	idx.Update(filepath.Join(root, "a.md"))
+	if notes := idx.Notes(); len(notes[0].Links) != 1 {
+		t.Errorf("updated links = %+v", notes[0].Links)
+	}
+
+	os.Remove(filepath.Join(root, "a.md"))
+	idx.Update(filepath.Join(root, "a.md"))
+	idx.Remove(filepath.Join(root, "dir", "sub"))
+	if got := files(idx.Notes()); !reflect.DeepEqual(got, []string{filepath.Join("dir", "b.md")}) {
+		t.Errorf("notes = %v", got)
+	}
+
+	idx.Update(filepath.Join(filepath.Dir(root), "outside.md"))
+	if got := files(idx.Notes()); len(got) != 1 {
+		t.Errorf("notes after update outside the vault = %v", got)
+	}
+}
+
+func TestIndex_Ignored(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{
+		".gitignore":         "build/\n",
+		".obsidian/app.json": `{"userIgnoreFilters": ["Archive/"]}`,
+		"home.md":            "",
+		"build/out.md":       "",
+		"Archive/old.md":     "",
+	})
+	idx := New(root)
+	if err := idx.Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got := files(idx.Notes()); !reflect.DeepEqual(got, []string{"home.md"}) {
+		t.Fatalf("notes = %v", got)
+	}
+
+	idx.Update(filepath.Join(root, "build", "out.md"))
+	if got := files(idx.Notes()); len(got) != 1 {
+		t.Errorf("notes after updating an ignored note = %v", got)
+	}
+
+	writeNotes(t, root, map[string]string{".gitignore": ""})
+	if err := idx.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := files(idx.Notes()); !reflect.DeepEqual(got, []string{filepath.Join("build", "out.md"), "home.md"}) {
+		t.Errorf("notes after reload = %v", got)
+	}
+}
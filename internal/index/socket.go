@@ -0,0 +1,128 @@
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/api"
+	"github.com/shalomb/ob-cli/internal/model"
+)
+
+// SocketPath returns the daemon socket for a vault, under
+// $XDG_RUNTIME_DIR/ob-cli or a per-user temporary directory
+func SocketPath(notesDir string) string {
+	abs, err := filepath.Abs(notesDir)
+	if err != nil {
+		abs = notesDir
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := hex.EncodeToString(sum[:8]) + ".sock"
+
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "ob-cli-"+strconv.Itoa(os.Getuid()))
+	}
+	return filepath.Join(dir, "ob-cli", name)
+}
+
+// Handler serves an index over HTTP: GET /notes and GET /tags
+func Handler(idx *Index) http.Handler {
+	return api.NewHandler([]api.Route{
+		{Method: http.MethodGet, Path: "/notes", Handle: func(*http.Request) (interface{}, error) {
+			return idx.Notes(), nil
+		}},
+		{Method: http.MethodGet, Path: "/tags", Handle: func(*http.Request) (interface{}, error) {
+			return idx.Tags(), nil
+		}},
+	}, "")
+}
+
+// Client queries a running daemon. Every method fails fast when no daemon
+// listens on the socket, so callers can fall back to scanning the vault.
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient creates a client for the daemon listening on socket
+func NewClient(socket string) *Client {
+	dialer := &net.Dialer{Timeout: 100 * time.Millisecond}
+	return &Client{
+		socket: socket,
+		http: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Running reports whether a daemon accepts connections on the socket
+func (c *Client) Running(ctx context.Context) bool {
+	dialer := net.Dialer{Timeout: 100 * time.Millisecond}
+	conn, err := dialer.DialContext(ctx, "unix", c.socket)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Notes returns the daemon's indexed notes
+func (c *Client) Notes(ctx context.Context) ([]Note, error) {
+	var notes []Note
+	err := c.get(ctx, "/notes", &notes)
+	return notes, err
+}
+
+// Tags returns the notes carrying each tag
+func (c *Client) Tags(ctx context.Context) (map[string][]string, error) {
+	var tags map[string][]string
+	err := c.get(ctx, "/tags", &tags)
+	return tags, err
+}
+
+// ListFiles lists notes with their modification times and tags for
+// frecency sorting
+func (c *Client) ListFiles(ctx context.Context) ([]model.NoteInfo, error) {
+	notes, err := c.Notes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]model.NoteInfo, len(notes))
+	for i, note := range notes {
+		files[i] = model.NoteInfo{Path: note.File, Modified: note.Modified, Tags: note.Tags}
+	}
+	return files, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	if _, err := os.Stat(c.socket); err != nil {
+		return fmt.Errorf("daemon not running: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://ob-cli"+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query daemon: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("daemon returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
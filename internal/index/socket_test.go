@@ -0,0 +1,63 @@
+package index
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shalomb/ob-cli/internal/api"
+)
+
+func TestSocketPath(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+
+	a, b := SocketPath("/notes/a"), SocketPath("/notes/b")
+	if !strings.HasPrefix(a, "/run/user/1000/ob-cli/") || !strings.HasSuffix(a, ".sock") {
+		t.Errorf("SocketPath() = %s", a)
+	}
+	if a == b || a != SocketPath("/notes/a/") {
+		t.Errorf("SocketPath() is not one socket per vault: %s, %s", a, b)
+	}
+}
+
+func TestClient(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{"a.md": "#tag [[b]]", "b.md": ""})
+	idx := New(root)
+	idx.Build()
+
+	socket := filepath.Join(t.TempDir(), "index.sock")
+	client := NewClient(socket)
+	if client.Running(context.Background()) {
+		t.Error("Running() without a daemon")
+	}
+	if _, err := client.ListFiles(context.Background()); err == nil {
+		t.Error("ListFiles() without a daemon succeeded")
+	}
+
+	listener, err := api.Listen("", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: Handler(idx)}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if !client.Running(context.Background()) {
+		t.Error("Running() with a daemon = false")
+	}
+	files, err := client.ListFiles(context.Background())
+	if err != nil || len(files) != 2 || files[0].Path != "a.md" || files[0].Modified.IsZero() {
+		t.Errorf("ListFiles() = %+v, %v", files, err)
+	}
+	notes, err := client.Notes(context.Background())
+	if err != nil || len(notes[0].Links) != 1 || notes[0].Links[0].Target != "b" {
+		t.Errorf("Notes() = %+v, %v", notes, err)
+	}
+	tags, err := client.Tags(context.Background())
+	if err != nil || len(tags["tag"]) != 1 {
+		t.Errorf("Tags() = %v, %v", tags, err)
+	}
+}
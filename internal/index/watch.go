@@ -0,0 +1,103 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/shalomb/ob-cli/internal/ignore"
+)
+
+// Watch keeps idx current until stop is closed. Every directory of the
+// vault is watched, new directories included. Events are not trusted for
+// what happened; the path is re-examined instead, which covers renames,
+// deletes and editors that save by writing a temporary file and renaming
+// it over the note. When a .gitignore, the .obignore or Obsidian's settings
+// change, the index is rebuilt under the new rules.
+func Watch(idx *Index, stop <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watchTree(watcher, idx, idx.root, false); err != nil {
+		return err
+	}
+	// Obsidian's settings are hidden from the walk, but hold excluded files
+	watcher.Add(filepath.Join(idx.root, filepath.Dir(ignore.ObsidianConfig)))
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			refresh(watcher, idx, event.Name)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			if err == fsnotify.ErrEventOverflow {
+				// Events were lost; rescan to catch up
+				idx.Remove(idx.root)
+				if err := idx.Build(); err != nil {
+					return fmt.Errorf("failed to rescan: %w", err)
+				}
+			}
+		}
+	}
+}
+
+// refresh brings the index in line with a changed path
+func refresh(watcher *fsnotify.Watcher, idx *Index, path string) {
+	if rel, err := filepath.Rel(idx.root, path); err == nil && ignore.IsRuleFile(rel) {
+		// What is ignored changed; newly unignored directories need watching
+		if err := idx.Reload(); err == nil {
+			watchTree(watcher, idx, idx.root, false)
+		}
+		return
+	}
+	if _, ok := idx.rel(path); !ok {
+		return // Hidden, e.g. .git or an editor's temporary file
+	}
+	info, err := os.Stat(path)
+	switch {
+	case err != nil:
+		idx.Remove(path) // Deleted or renamed away
+	case idx.skip(path, info.IsDir()):
+		return // Ignored
+	case info.IsDir():
+		// A new or moved-in directory; its notes arrive without events
+		watchTree(watcher, idx, path, true)
+	default:
+		idx.Update(path)
+	}
+}
+
+// watchTree watches a directory and its subdirectories, indexing their
+// notes when index is set
+func watchTree(watcher *fsnotify.Watcher, idx *Index, dir string, index bool) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // Vanished while walking
+		}
+		if path != dir && idx.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if err := watcher.Add(path); err != nil {
+				return fmt.Errorf("failed to watch %s: %w", path, err)
+			}
+		} else if index {
+			idx.Update(path)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,89 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// waitFor polls the index until its notes match expected
+func waitFor(t *testing.T, idx *Index, expected []string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got := files(idx.Notes())
+		if reflect.DeepEqual(got, expected) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("notes = %v, expected %v", got, expected)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	root := t.TempDir()
+	writeNotes(t, root, map[string]string{"a.md": "", "dir/b.md": ""})
+	idx := New(root)
+	idx.Build()
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- Watch(idx, stop) }()
+	defer func() {
+		close(stop)
+		if err := <-done; err != nil {
+			t.Errorf("Watch() error = %v", err)
+		}
+	}()
+	time.Sleep(50 * time.Millisecond) // Let the watches be added
+
+	b := filepath.Join("dir", "b.md")
+
+	// Create
+	writeNotes(t, root, map[string]string{"new.md": "[[a]]"})
+	waitFor(t, idx, []string{"a.md", b, "new.md"})
+
+	// Rename
+	os.Rename(filepath.Join(root, "new.md"), filepath.Join(root, "renamed.md"))
+	waitFor(t, idx, []string{"a.md", b, "renamed.md"})
+
+	// Atomic save: write a temporary file and rename it over the note
+	writeNotes(t, root, map[string]string{".a.md.tmp": "#saved"})
+	os.Rename(filepath.Join(root, ".a.md.tmp"), filepath.Join(root, "a.md"))
+	deadline := time.Now().Add(5 * time.Second)
+	for idx.Tags()["saved"] == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("atomic save not indexed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Delete
+	os.Remove(filepath.Join(root, "renamed.md"))
+	waitFor(t, idx, []string{"a.md", b})
+
+	// A directory moved into the vault brings its notes
+	outside := t.TempDir()
+	writeNotes(t, outside, map[string]string{"moved/c.md": ""})
+	os.Rename(filepath.Join(outside, "moved"), filepath.Join(root, "moved"))
+	waitFor(t, idx, []string{"a.md", b, filepath.Join("moved", "c.md")})
+
+	// Notes created in the new directory are seen too
+	writeNotes(t, root, map[string]string{"moved/d.md": ""})
+	waitFor(t, idx, []string{"a.md", b, filepath.Join("moved", "c.md"), filepath.Join("moved", "d.md")})
+
+	// Removing a directory drops its notes
+	os.RemoveAll(filepath.Join(root, "dir"))
+	waitFor(t, idx, []string{"a.md", filepath.Join("moved", "c.md"), filepath.Join("moved", "d.md")})
+
+	// Ignored notes stay out, and changing the rules rebuilds the index
+	writeNotes(t, root, map[string]string{".gitignore": "moved/\n"})
+	waitFor(t, idx, []string{"a.md"})
+	writeNotes(t, root, map[string]string{"moved/e.md": ""})
+	writeNotes(t, root, map[string]string{".gitignore": ""})
+	waitFor(t, idx, []string{"a.md", filepath.Join("moved", "c.md"), filepath.Join("moved", "d.md"), filepath.Join("moved", "e.md")})
+}
@@ -0,0 +1,385 @@
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// maxCompletions caps the notes offered for one completion request; the
+// list is marked incomplete so clients ask again as the user types
+const maxCompletions = 100
+
+// completion offers note names after "[[", in frecency order, and the
+// headings of the linked note after "[[note#"
+func (s *Server) completion(params json.RawMessage) (interface{}, error) {
+	var p textDocumentPosition
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	v, rel, err := s.vault(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	empty := map[string]interface{}{"isIncomplete": false, "items": []CompletionItem{}}
+	lines := strings.Split(s.text(v, rel), "\n")
+	if p.Position.Line >= len(lines) {
+		return empty, nil
+	}
+	line := strings.TrimRight(lines[p.Position.Line], "\r")
+	cursor := byteOffset(line, p.Position.Character)
+	before := line[:cursor]
+	open := strings.LastIndex(before, "[[")
+	if open < 0 || strings.Contains(before[open:], "]]") || strings.Contains(before[open:], "|") {
+		return empty, nil
+	}
+	typed := before[open+2:]
+	closing := "]]"
+	if strings.HasPrefix(line[cursor:], "]]") {
+		closing = ""
+	}
+
+	var items []CompletionItem
+	if hash := strings.Index(typed, "#"); hash >= 0 {
+		target, ok := v.resolver.Resolve(rel, typed[:hash])
+		if !ok {
+			return empty, nil
+		}
+		editRange := lineRange(p.Position.Line, line, open+2+hash+1, cursor)
+		for i, h := range markdown.Headings(s.text(v, target)) {
+			items = append(items, CompletionItem{
+				Label:      h.Text,
+				Kind:       completionKindReference,
+				Detail:     strings.Repeat("#", h.Level) + " " + h.Text,
+				SortText:   fmt.Sprintf("%05d", i),
+				FilterText: h.Text,
+				TextEdit:   &TextEdit{Range: editRange, NewText: h.Text + closing},
+			})
+		}
+		return map[string]interface{}{"isIncomplete": false, "items": nonNil(items)}, nil
+	}
+
+	query := strings.ToLower(typed)
+	editRange := lineRange(p.Position.Line, line, open+2, cursor)
+	for i, note := range v.notes {
+		if len(items) == maxCompletions {
+			break
+		}
+		if note == rel || !strings.Contains(strings.ToLower(note), query) {
+			continue
+		}
+		name := linkName(v, note)
+		items = append(items, CompletionItem{
+			Label:      name,
+			Kind:       completionKindFile,
+			Detail:     note,
+			SortText:   fmt.Sprintf("%05d", i),
+			FilterText: name,
+			TextEdit:   &TextEdit{Range: editRange, NewText: name + closing},
+		})
+	}
+	return map[string]interface{}{"isIncomplete": len(items) == maxCompletions, "items": nonNil(items)}, nil
+}
+
+// definition jumps from a link to its note, or to the linked heading
+func (s *Server) definition(params json.RawMessage) (interface{}, error) {
+	var p textDocumentPosition
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	v, rel, err := s.vault(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	link, ok := linkAt(s.text(v, rel), p.Position)
+	if !ok {
+		return nil, nil
+	}
+	target, ok := v.resolver.Resolve(rel, link.Target)
+	if !ok {
+		return nil, nil
+	}
+
+	loc := Location{URI: s.uri(v, target)}
+	if link.Fragment != "" {
+		if h, ok := markdown.FindHeading(markdown.Headings(s.text(v, target)), link.Fragment); ok {
+			loc.Range = Range{Start: Position{Line: h.Line - 1}, End: Position{Line: h.Line - 1}}
+		}
+	}
+	return []Location{loc}, nil
+}
+
+// references lists the links to the note or heading under the cursor: the
+// link's target when on a link, the heading when on a heading line, else
+// the current note
+func (s *Server) references(params json.RawMessage) (interface{}, error) {
+	var p textDocumentPosition
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	v, rel, err := s.vault(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	content := s.text(v, rel)
+	target, fragment := rel, ""
+	if link, ok := linkAt(content, p.Position); ok {
+		if target, ok = v.resolver.Resolve(rel, link.Target); !ok {
+			return []Location{}, nil
+		}
+		fragment = link.Fragment
+	} else {
+		for _, h := range markdown.Headings(content) {
+			if h.Line == p.Position.Line+1 {
+				fragment = h.Text
+			}
+		}
+	}
+
+	locations := []Location{}
+	for _, ref := range s.linksTo(v, target) {
+		if fragment != "" && !strings.EqualFold(strings.TrimSpace(ref.link.Fragment), strings.TrimSpace(fragment)) {
+			continue
+		}
+		locations = append(locations, Location{URI: s.uri(v, ref.file), Range: ref.rng})
+	}
+	return locations, nil
+}
+
+// rename renames the note under the cursor (the link target, or the
+// current note) and rewrites every link to it
+func (s *Server) rename(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		textDocumentPosition
+		NewName string `json:"newName"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	if !s.renameFiles {
+		return nil, fmt.Errorf("the editor does not support renaming files")
+	}
+	v, rel, err := s.vault(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	target := rel
+	if link, ok := linkAt(s.text(v, rel), p.Position); ok {
+		if target, ok = v.resolver.Resolve(rel, link.Target); !ok {
+			return nil, fmt.Errorf("link target %q does not exist", link.Target)
+		}
+	}
+	if filepath.Ext(target) != ".md" {
+		return nil, fmt.Errorf("only notes can be renamed")
+	}
+
+	newRel, err := renamedPath(target, p.NewName)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := v.resolver.Resolve("", "/"+filepath.ToSlash(newRel)); ok && !strings.EqualFold(newRel, target) {
+		return nil, fmt.Errorf("%s already exists", newRel)
+	}
+
+	ambiguous := false
+	newBase := strings.ToLower(path.Base(filepath.ToSlash(newRel)))
+	for _, note := range v.notes {
+		if note != target && strings.ToLower(path.Base(filepath.ToSlash(note))) == newBase {
+			ambiguous = true
+		}
+	}
+
+	edits := map[string][]TextEdit{}
+	var order []string
+	for _, ref := range s.linksTo(v, target) {
+		if _, ok := edits[ref.file]; !ok {
+			order = append(order, ref.file)
+		}
+		edits[ref.file] = append(edits[ref.file], TextEdit{
+			Range:   ref.rng,
+			NewText: rewriteLink(ref.link, ref.file, newRel, ambiguous),
+		})
+	}
+
+	var changes []interface{}
+	for _, file := range order {
+		change := TextDocumentEdit{Edits: edits[file]}
+		change.TextDocument.URI = s.uri(v, file)
+		if doc, ok := s.docs[filepath.Join(v.root, file)]; ok {
+			version := doc.version
+			change.TextDocument.Version = &version
+		}
+		changes = append(changes, change)
+	}
+	changes = append(changes, RenameFile{Kind: "rename", OldURI: s.uri(v, target), NewURI: s.uri(v, newRel)})
+
+	return WorkspaceEdit{DocumentChanges: changes}, nil
+}
+
+// publishDiagnostics reports the links of a document whose note or
+// heading does not exist
+func (s *Server) publishDiagnostics(uri string) error {
+	v, rel, err := s.vault(uri)
+	if err != nil {
+		return nil // Not in a vault; nothing to check
+	}
+
+	content := s.text(v, rel)
+	lines := strings.Split(content, "\n")
+	diagnostics := []Diagnostic{}
+	for _, link := range markdown.ParseLinks(content) {
+		rng := lineRange(link.Line-1, strings.TrimRight(lines[link.Line-1], "\r"), link.Start, link.End)
+		target, ok := v.resolver.Resolve(rel, link.Target)
+		if !ok {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range: rng, Severity: severityWarning, Source: "ob-cli",
+				Message: fmt.Sprintf("Unresolved link: %s", link.Target),
+			})
+			continue
+		}
+		if link.Fragment == "" || strings.HasPrefix(link.Fragment, "^") || filepath.Ext(target) != ".md" {
+			continue
+		}
+		if _, ok := markdown.FindHeading(markdown.Headings(s.text(v, target)), link.Fragment); !ok {
+			diagnostics = append(diagnostics, Diagnostic{
+				Range: rng, Severity: severityWarning, Source: "ob-cli",
+				Message: fmt.Sprintf("Heading %q not found in %s", link.Fragment, target),
+			})
+		}
+	}
+
+	return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         uri,
+		"diagnostics": diagnostics,
+	})
+}
+
+// reference is a link to a note found in the vault
+type reference struct {
+	file string
+	link markdown.Link
+	rng  Range
+}
+
+// linksTo finds every link in the vault's notes that resolves to target
+func (s *Server) linksTo(v *vaultView, target string) []reference {
+	var refs []reference
+	for _, file := range v.notes {
+		content := s.text(v, file)
+		if content == "" {
+			continue
+		}
+		lines := strings.Split(content, "\n")
+		for _, link := range markdown.ParseLinks(content) {
+			if resolved, ok := v.resolver.Resolve(file, link.Target); !ok || resolved != target {
+				continue
+			}
+			line := strings.TrimRight(lines[link.Line-1], "\r")
+			refs = append(refs, reference{file: file, link: link, rng: lineRange(link.Line-1, line, link.Start, link.End)})
+		}
+	}
+	return refs
+}
+
+// linkAt returns the link under a position, skipping code and frontmatter
+func linkAt(content string, pos Position) (markdown.Link, bool) {
+	lines := strings.Split(content, "\n")
+	if pos.Line >= len(lines) {
+		return markdown.Link{}, false
+	}
+	cursor := byteOffset(strings.TrimRight(lines[pos.Line], "\r"), pos.Character)
+	for _, link := range markdown.ParseLinks(content) {
+		if link.Line == pos.Line+1 && link.Start <= cursor && cursor <= link.End {
+			return link, true
+		}
+	}
+	return markdown.Link{}, false
+}
+
+// linkName is the shortest wikilink target that resolves to note: its
+// name when that is unambiguous, else its path, without ".md"
+func linkName(v *vaultView, note string) string {
+	name := strings.TrimSuffix(path.Base(filepath.ToSlash(note)), ".md")
+	if resolved, ok := v.resolver.Resolve("", name); ok && resolved == note {
+		return name
+	}
+	return strings.TrimSuffix(filepath.ToSlash(note), ".md")
+}
+
+// renamedPath turns a rename's new name into a vault-relative note path.
+// Names without a folder stay in the note's folder.
+func renamedPath(old, newName string) (string, error) {
+	newName = strings.TrimSpace(filepath.FromSlash(newName))
+	if newName == "" {
+		return "", fmt.Errorf("empty name")
+	}
+	if filepath.Ext(newName) != ".md" {
+		newName += ".md"
+	}
+	if !strings.ContainsRune(newName, filepath.Separator) {
+		newName = filepath.Join(filepath.Dir(old), newName)
+	}
+	newName = filepath.Clean(newName)
+	if filepath.IsAbs(newName) || newName == ".." || strings.HasPrefix(newName, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the vault", newName)
+	}
+	return newName, nil
+}
+
+// rewriteLink renders a link to a renamed note, keeping its style,
+// fragment and alias. Wikilinks written by name keep using the name unless
+// it becomes ambiguous; markdown links get a path relative to their note.
+func rewriteLink(link markdown.Link, from, newRel string, ambiguous bool) string {
+	slashed := filepath.ToSlash(newRel)
+	prefix := ""
+	if link.Embed {
+		prefix = "!"
+	}
+
+	if link.Wiki {
+		target := strings.TrimSuffix(slashed, ".md")
+		if !strings.Contains(link.Target, "/") && !ambiguous {
+			target = path.Base(target)
+		}
+		if strings.HasSuffix(strings.ToLower(link.Target), ".md") {
+			target += ".md"
+		}
+		if link.Fragment != "" {
+			target += "#" + link.Fragment
+		}
+		if link.Alias != "" {
+			target += "|" + link.Alias
+		}
+		return prefix + "[[" + target + "]]"
+	}
+
+	dest, err := filepath.Rel(filepath.Dir(from), newRel)
+	if err != nil {
+		dest = newRel
+	}
+	segments := strings.Split(filepath.ToSlash(dest), "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	dest = strings.Join(segments, "/")
+	if link.Fragment != "" {
+		dest += "#" + link.Fragment
+	}
+	return prefix + "[" + link.Alias + "](" + dest + ")"
+}
+
+func nonNil(items []CompletionItem) []CompletionItem {
+	if items == nil {
+		return []CompletionItem{}
+	}
+	return items
+}
@@ -0,0 +1,225 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The subset of LSP types the server uses
+// (https://microsoft.github.io/language-server-protocol/specification)
+
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"` // UTF-16 code units
+}
+
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+type CompletionItem struct {
+	Label      string    `json:"label"`
+	Kind       int       `json:"kind"`
+	Detail     string    `json:"detail,omitempty"`
+	SortText   string    `json:"sortText"`
+	FilterText string    `json:"filterText"`
+	TextEdit   *TextEdit `json:"textEdit,omitempty"`
+}
+
+const (
+	completionKindFile      = 17
+	completionKindReference = 18
+)
+
+type TextDocumentEdit struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version *int   `json:"version"`
+	} `json:"textDocument"`
+	Edits []TextEdit `json:"edits"`
+}
+
+type RenameFile struct {
+	Kind   string `json:"kind"` // Always "rename"
+	OldURI string `json:"oldUri"`
+	NewURI string `json:"newUri"`
+}
+
+type WorkspaceEdit struct {
+	DocumentChanges []interface{} `json:"documentChanges"` // TextDocumentEdit or RenameFile
+}
+
+type textDocumentPosition struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+	Position Position `json:"position"`
+}
+
+// request is an incoming JSON-RPC 2.0 request or notification
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is a successful reply; result is always present, even if null
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result"`
+}
+
+type errorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Error   *rpcError       `json:"error"`
+}
+
+// serverRequest is a request from the server to the client
+type serverRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      string      `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// JSON-RPC and LSP error codes
+const (
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeRequestFailed  = -32803
+)
+
+// readMessage reads one Content-Length framed message
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length %q", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// writeMessage writes one Content-Length framed message
+func writeMessage(w io.Writer, msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// uriToPath converts a file:// URI to a local path
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("unsupported document URI %q", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathToURI converts a local path to a file:// URI
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// utf16Column converts a byte offset in line to a UTF-16 column
+func utf16Column(line string, offset int) int {
+	if offset > len(line) {
+		offset = len(line)
+	}
+	col := 0
+	for _, r := range line[:offset] {
+		col += utf16Len(r)
+	}
+	return col
+}
+
+// byteOffset converts a UTF-16 column in line to a byte offset
+func byteOffset(line string, column int) int {
+	col := 0
+	for i, r := range line {
+		if col >= column {
+			return i
+		}
+		col += utf16Len(r)
+	}
+	return len(line)
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= utf8.MaxRune {
+		return 2 // Surrogate pair
+	}
+	return 1
+}
+
+// lineRange returns the range of bytes [start, end) on a line
+func lineRange(lineNo int, line string, start, end int) Range {
+	return Range{
+		Start: Position{Line: lineNo, Character: utf16Column(line, start)},
+		End:   Position{Line: lineNo, Character: utf16Column(line, end)},
+	}
+}
@@ -0,0 +1,65 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUTF16Conversions(t *testing.T) {
+	line := "a😀é[[x]]"
+
+	tests := []struct {
+		offset int
+		column int
+	}{
+		{0, 0},
+		{1, 1},
+		{5, 3}, // After the emoji: 4 bytes, 2 UTF-16 units
+		{7, 4},
+		{len(line), 9},
+	}
+
+	for _, tt := range tests {
+		if got := utf16Column(line, tt.offset); got != tt.column {
+			t.Errorf("utf16Column(%d) = %d, expected %d", tt.offset, got, tt.column)
+		}
+		if got := byteOffset(line, tt.column); got != tt.offset {
+			t.Errorf("byteOffset(%d) = %d, expected %d", tt.column, got, tt.offset)
+		}
+	}
+}
+
+func TestMessageFraming(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeMessage(&buf, map[string]int{"id": 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "Content-Length: 8\r\n\r\n{\"id\":1}" {
+		t.Errorf("writeMessage() = %q", got)
+	}
+
+	body, err := readMessage(bufio.NewReader(&buf))
+	if err != nil || string(body) != `{"id":1}` {
+		t.Errorf("readMessage() = %q, %v", body, err)
+	}
+
+	if _, err := readMessage(bufio.NewReader(strings.NewReader("Content-Type: x\r\n\r\n{}"))); err == nil {
+		t.Error("readMessage() without Content-Length succeeded")
+	}
+}
+
+func TestURIConversions(t *testing.T) {
+	path := "/notes/my plan.md"
+	uri := pathToURI(path)
+	if uri != "file:///notes/my%20plan.md" {
+		t.Errorf("pathToURI() = %q", uri)
+	}
+	if got, err := uriToPath(uri); err != nil || got != path {
+		t.Errorf("uriToPath() = %q, %v", got, err)
+	}
+	if _, err := uriToPath("untitled:1"); err == nil {
+		t.Error("uriToPath() accepted a non-file URI")
+	}
+}
@@ -0,0 +1,336 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/frecency"
+	"github.com/shalomb/ob-cli/internal/markdown"
+)
+
+// Options connects the server to the vaults of the documents it serves
+type Options struct {
+	// FindRoot returns the vault directory containing a document
+	FindRoot func(path string) (string, error)
+	// Notes lists the vault-relative notes of a vault, most relevant first
+	Notes func(root string) ([]string, error)
+}
+
+// Server is a markdown language server for wikilinks over stdio
+type Server struct {
+	opts        Options
+	out         io.Writer
+	docs        map[string]*document  // Open documents by absolute path
+	views       map[string]*vaultView // Vault views by root, until a file is saved or changes
+	renameFiles bool                  // Client accepts file renames in workspace edits
+	watchFiles  bool                  // Client registers file watchers on request
+	shutdown    bool
+}
+
+// document is the editor's copy of an open file
+type document struct {
+	version int
+	text    string
+}
+
+// vaultView is a snapshot of a vault used to answer requests
+type vaultView struct {
+	root     string
+	notes    []string           // Most relevant first
+	files    []string           // Notes and attachments
+	resolver *markdown.Resolver // Resolves links to files
+}
+
+// errExit ends Serve after an exit notification
+var errExit = errors.New("exit")
+
+// NewServer creates a language server
+func NewServer(opts Options) *Server {
+	return &Server{opts: opts, docs: map[string]*document{}, views: map[string]*vaultView{}}
+}
+
+// Serve handles messages from in until the client sends exit or closes
+// the stream. It returns an error when exit arrives without a shutdown
+// request, as the protocol asks servers to exit with status 1 then.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+	for {
+		body, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			continue // Nothing sensible to reply to
+		}
+
+		if err := s.handle(req); err == errExit {
+			if !s.shutdown {
+				return fmt.Errorf("exit without shutdown")
+			}
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches one message and writes the reply for requests
+func (s *Server) handle(req request) error {
+	var result interface{}
+	var err error
+
+	switch req.Method {
+	case "initialize":
+		result, err = s.initialize(req.Params)
+	case "":
+		return nil // A reply to a request of the server's
+	case "initialized":
+		return s.initialized()
+	case "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil
+	case "workspace/didChangeWatchedFiles":
+		s.views = map[string]*vaultView{}
+		return nil
+	case "shutdown":
+		s.shutdown = true
+	case "exit":
+		return errExit
+	case "textDocument/didOpen":
+		return s.didOpen(req.Params)
+	case "textDocument/didChange":
+		return s.didChange(req.Params)
+	case "textDocument/didSave":
+		return s.didSave(req.Params)
+	case "textDocument/didClose":
+		return s.didClose(req.Params)
+	case "textDocument/completion":
+		result, err = s.completion(req.Params)
+	case "textDocument/definition":
+		result, err = s.definition(req.Params)
+	case "textDocument/references":
+		result, err = s.references(req.Params)
+	case "textDocument/rename":
+		result, err = s.rename(req.Params)
+	default:
+		if req.ID == nil {
+			return nil // Unknown notification
+		}
+		return s.replyError(req.ID, codeMethodNotFound, fmt.Sprintf("method %q not supported", req.Method))
+	}
+
+	if req.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return s.replyError(req.ID, codeRequestFailed, err.Error())
+	}
+	return writeMessage(s.out, response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+func (s *Server) replyError(id json.RawMessage, code int, msg string) error {
+	return writeMessage(s.out, errorResponse{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: msg}})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return writeMessage(s.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var init struct {
+		Capabilities struct {
+			Workspace struct {
+				WorkspaceEdit struct {
+					DocumentChanges    bool     `json:"documentChanges"`
+					ResourceOperations []string `json:"resourceOperations"`
+				} `json:"workspaceEdit"`
+				DidChangeWatchedFiles struct {
+					DynamicRegistration bool `json:"dynamicRegistration"`
+				} `json:"didChangeWatchedFiles"`
+			} `json:"workspace"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(params, &init); err != nil {
+		return nil, fmt.Errorf("invalid initialize params: %w", err)
+	}
+	edit := init.Capabilities.Workspace.WorkspaceEdit
+	for _, op := range edit.ResourceOperations {
+		if op == "rename" && edit.DocumentChanges {
+			s.renameFiles = true
+		}
+	}
+	s.watchFiles = init.Capabilities.Workspace.DidChangeWatchedFiles.DynamicRegistration
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    1, // Full document on every change
+				"save":      true,
+			},
+			"completionProvider": map[string]interface{}{"triggerCharacters": []string{"[", "#"}},
+			"definitionProvider": true,
+			"referencesProvider": true,
+			"renameProvider":     true,
+		},
+		"serverInfo": map[string]interface{}{"name": "ob-cli"},
+	}, nil
+}
+
+// initialized asks the client to report changes to vault files, which the
+// cached vault views are dropped on
+func (s *Server) initialized() error {
+	if !s.watchFiles {
+		return nil
+	}
+	return writeMessage(s.out, serverRequest{
+		JSONRPC: "2.0",
+		ID:      "watch",
+		Method:  "client/registerCapability",
+		Params: map[string]interface{}{
+			"registrations": []interface{}{map[string]interface{}{
+				"id":     "watch",
+				"method": "workspace/didChangeWatchedFiles",
+				"registerOptions": map[string]interface{}{
+					"watchers": []interface{}{map[string]interface{}{"globPattern": "**/*"}},
+				},
+			}},
+		},
+	})
+}
+
+func (s *Server) didOpen(params json.RawMessage) error {
+	var p struct {
+		TextDocument struct {
+			URI     string `json:"uri"`
+			Version int    `json:"version"`
+			Text    string `json:"text"`
+		} `json:"textDocument"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	path, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return nil
+	}
+	s.docs[path] = &document{version: p.TextDocument.Version, text: p.TextDocument.Text}
+	return s.publishDiagnostics(p.TextDocument.URI)
+}
+
+func (s *Server) didChange(params json.RawMessage) error {
+	var p struct {
+		TextDocument struct {
+			URI     string `json:"uri"`
+			Version int    `json:"version"`
+		} `json:"textDocument"`
+		ContentChanges []struct {
+			Text string `json:"text"`
+		} `json:"contentChanges"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || len(p.ContentChanges) == 0 {
+		return nil
+	}
+	path, err := uriToPath(p.TextDocument.URI)
+	if err != nil {
+		return nil
+	}
+	// Only the buffer changes; the vault view stays cached until a save
+	s.docs[path] = &document{version: p.TextDocument.Version, text: p.ContentChanges[len(p.ContentChanges)-1].Text}
+	return s.publishDiagnostics(p.TextDocument.URI)
+}
+
+func (s *Server) didSave(params json.RawMessage) error {
+	var p textDocumentPosition
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	s.views = map[string]*vaultView{} // A saved note may be new, or a new link target
+	return s.publishDiagnostics(p.TextDocument.URI)
+}
+
+func (s *Server) didClose(params json.RawMessage) error {
+	var p textDocumentPosition
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil
+	}
+	if path, err := uriToPath(p.TextDocument.URI); err == nil {
+		delete(s.docs, path)
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         p.TextDocument.URI,
+		"diagnostics": []Diagnostic{},
+	})
+}
+
+// vault returns the vault of a document and the document's path in it
+func (s *Server) vault(uri string) (*vaultView, string, error) {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil, "", err
+	}
+	root, err := s.opts.FindRoot(path)
+	if err != nil {
+		return nil, "", err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, "", fmt.Errorf("%s is outside the vault %s", path, root)
+	}
+
+	v, ok := s.views[root]
+	if !ok {
+		notes, err := s.opts.Notes(root)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list notes: %w", err)
+		}
+		attachments, _ := frecency.ListAttachments(root)
+		files := append(append([]string(nil), notes...), attachments...)
+		v = &vaultView{root: root, notes: notes, files: files, resolver: markdown.NewResolver(files)}
+		s.views[root] = v
+	}
+
+	if filepath.Ext(rel) == ".md" && !contains(v.notes, rel) {
+		// A new, unsaved note, resolved in a view of its own
+		files := append(append([]string(nil), v.files...), rel)
+		return &vaultView{root: root, notes: v.notes, files: files, resolver: markdown.NewResolver(files)}, rel, nil
+	}
+	return v, rel, nil
+}
+
+// text returns a vault file's content, preferring the open editor copy
+func (s *Server) text(v *vaultView, rel string) string {
+	path := filepath.Join(v.root, rel)
+	if doc, ok := s.docs[path]; ok {
+		return doc.text
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func (s *Server) uri(v *vaultView, rel string) string {
+	return pathToURI(filepath.Join(v.root, rel))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,316 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestVault writes notes to a temporary vault and returns its root and
+// server options listing the notes in the given order
+func newTestVault(t *testing.T, notes map[string]string, order []string) (string, Options) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range notes {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, Options{
+		FindRoot: func(string) (string, error) { return root, nil },
+		Notes:    func(string) ([]string, error) { return order, nil },
+	}
+}
+
+// message is any server-to-client message
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// session runs a server over the given client messages and returns the
+// responses by request id and the notifications in order
+func session(t *testing.T, opts Options, msgs ...string) (map[string]message, []message) {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range msgs {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	var out bytes.Buffer
+	if err := NewServer(opts).Serve(&in, &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	responses := map[string]message{}
+	var notifications []message
+	reader := bufio.NewReader(&out)
+	for {
+		body, err := readMessage(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.ID != nil {
+			responses[string(msg.ID)] = msg
+		} else {
+			notifications = append(notifications, msg)
+		}
+	}
+	return responses, notifications
+}
+
+const initialize = `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"capabilities":{"workspace":{"workspaceEdit":{"documentChanges":true,"resourceOperations":["create","rename"]}}}}}`
+
+func didOpen(uri, text string) string {
+	params, _ := json.Marshal(map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "markdown", "version": 3, "text": text},
+	})
+	return fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":%s}`, params)
+}
+
+func positionRequest(id int, method, uri string, line, character int, extra string) string {
+	return fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":{"textDocument":{"uri":%q},"position":{"line":%d,"character":%d}%s}}`,
+		id, method, uri, line, character, extra)
+}
+
+var testNotes = map[string]string{
+	"home.md":          "# Home\nSee [[plan#Goals]] and [the plan](projects/plan.md)\n[[missing]] [[plan#Nowhere]]\n",
+	"projects/plan.md": "# Plan\n## Goals\nShip it\n",
+	"projects/idea.md": "Back to [[home]]\n",
+}
+
+func TestServer_Lifecycle(t *testing.T) {
+	_, opts := newTestVault(t, testNotes, []string{"home.md"})
+	responses, _ := session(t, opts,
+		initialize,
+		`{"jsonrpc":"2.0","id":1,"method":"workspace/symbol","params":{}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	)
+
+	var init struct {
+		Capabilities struct {
+			RenameProvider bool `json:"renameProvider"`
+		} `json:"capabilities"`
+	}
+	json.Unmarshal(responses["0"].Result, &init)
+	if !init.Capabilities.RenameProvider {
+		t.Errorf("initialize result = %s", responses["0"].Result)
+	}
+	if responses["1"].Error == nil || responses["1"].Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method reply = %+v", responses["1"])
+	}
+	if string(responses["2"].Result) != "null" {
+		t.Errorf("shutdown result = %s", responses["2"].Result)
+	}
+
+	exit := `{"jsonrpc":"2.0","method":"exit"}`
+	in := strings.NewReader(fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(exit), exit))
+	if err := NewServer(opts).Serve(in, io.Discard); err == nil {
+		t.Error("exit without shutdown succeeded")
+	}
+}
+
+func TestServer_Diagnostics(t *testing.T) {
+	root, opts := newTestVault(t, testNotes, []string{"home.md", "projects/plan.md", "projects/idea.md"})
+	uri := pathToURI(filepath.Join(root, "home.md"))
+	_, notifications := session(t, opts, initialize, didOpen(uri, testNotes["home.md"]))
+
+	if len(notifications) != 1 || notifications[0].Method != "textDocument/publishDiagnostics" {
+		t.Fatalf("notifications = %+v", notifications)
+	}
+	var params struct {
+		Diagnostics []Diagnostic `json:"diagnostics"`
+	}
+	json.Unmarshal(notifications[0].Params, &params)
+
+	expected := []struct {
+		message string
+		start   Position
+	}{
+		{"Unresolved link: missing", Position{Line: 2, Character: 0}},
+		{`Heading "Nowhere" not found in projects/plan.md`, Position{Line: 2, Character: 12}},
+	}
+	if len(params.Diagnostics) != len(expected) {
+		t.Fatalf("diagnostics = %+v", params.Diagnostics)
+	}
+	for i, e := range expected {
+		d := params.Diagnostics[i]
+		if d.Message != e.message || d.Range.Start != e.start || d.Severity != severityWarning {
+			t.Errorf("diagnostic %d = %+v, expected %q at %+v", i, d, e.message, e.start)
+		}
+	}
+}
+
+func TestServer_VaultCache(t *testing.T) {
+	root, opts := newTestVault(t, testNotes, []string{"home.md", "projects/plan.md", "projects/idea.md"})
+	listed := 0
+	notes := opts.Notes
+	opts.Notes = func(root string) ([]string, error) {
+		listed++
+		return notes(root)
+	}
+	uri := pathToURI(filepath.Join(root, "home.md"))
+	didChange := fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didChange","params":{"textDocument":{"uri":%q,"version":4},"contentChanges":[{"text":"[["}]}}`, uri)
+	responses, _ := session(t, opts,
+		`{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"capabilities":{"workspace":{"didChangeWatchedFiles":{"dynamicRegistration":true}}}}}`,
+		`{"jsonrpc":"2.0","method":"initialized","params":{}}`,
+		`{"jsonrpc":"2.0","id":"watch","result":null}`,
+		didOpen(uri, testNotes["home.md"]),
+		didChange,
+		positionRequest(1, "textDocument/completion", uri, 0, 2, ""),
+		`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"`+uri+`"}}}`,
+		`{"jsonrpc":"2.0","method":"workspace/didChangeWatchedFiles","params":{"changes":[]}}`,
+		positionRequest(2, "textDocument/completion", uri, 0, 2, ""),
+	)
+
+	if responses[`"watch"`].Method != "client/registerCapability" {
+		t.Errorf("file watcher registration = %+v", responses[`"watch"`])
+	}
+	if responses["1"].Error != nil || responses["2"].Error != nil {
+		t.Errorf("completion errors = %+v, %+v", responses["1"].Error, responses["2"].Error)
+	}
+	// Once for the open buffer, its change and the first completion, then
+	// again after the save and after the file change
+	if listed != 3 {
+		t.Errorf("notes listed %d times, expected 3", listed)
+	}
+}
+
+func TestServer_Completion(t *testing.T) {
+	root, opts := newTestVault(t, testNotes, []string{"projects/plan.md", "home.md", "projects/idea.md"})
+	uri := pathToURI(filepath.Join(root, "projects/idea.md"))
+	text := "Back to [[home]]\n[[\n[[plan#G]]\n"
+	responses, _ := session(t, opts,
+		initialize,
+		didOpen(uri, text),
+		positionRequest(1, "textDocument/completion", uri, 1, 2, ""),
+		positionRequest(2, "textDocument/completion", uri, 2, 8, ""),
+		positionRequest(3, "textDocument/completion", uri, 0, 3, ""),
+	)
+
+	var notes struct {
+		Items []CompletionItem `json:"items"`
+	}
+	json.Unmarshal(responses["1"].Result, &notes)
+	if len(notes.Items) != 2 || notes.Items[0].Label != "plan" || notes.Items[1].Label != "home" {
+		t.Fatalf("note completion = %+v", notes.Items)
+	}
+	if edit := notes.Items[0].TextEdit; edit.NewText != "plan]]" || edit.Range.Start.Character != 2 || edit.Range.End.Character != 2 {
+		t.Errorf("note completion edit = %+v", edit)
+	}
+
+	var headings struct {
+		Items []CompletionItem `json:"items"`
+	}
+	json.Unmarshal(responses["2"].Result, &headings)
+	if len(headings.Items) != 2 || headings.Items[1].Label != "Goals" || headings.Items[1].TextEdit.NewText != "Goals" {
+		t.Errorf("heading completion = %+v", headings.Items)
+	}
+
+	var none struct {
+		Items []CompletionItem `json:"items"`
+	}
+	json.Unmarshal(responses["3"].Result, &none)
+	if len(none.Items) != 0 {
+		t.Errorf("completion outside a link = %+v", none.Items)
+	}
+}
+
+func TestServer_DefinitionAndReferences(t *testing.T) {
+	root, opts := newTestVault(t, testNotes, []string{"home.md", "projects/plan.md", "projects/idea.md"})
+	home := pathToURI(filepath.Join(root, "home.md"))
+	plan := pathToURI(filepath.Join(root, "projects/plan.md"))
+	responses, _ := session(t, opts,
+		initialize,
+		positionRequest(1, "textDocument/definition", home, 1, 8, ""),
+		positionRequest(2, "textDocument/definition", home, 0, 2, ""),
+		positionRequest(3, "textDocument/references", plan, 2, 0, ""),
+		positionRequest(4, "textDocument/references", plan, 1, 3, ""),
+	)
+
+	var def []Location
+	json.Unmarshal(responses["1"].Result, &def)
+	if len(def) != 1 || def[0].URI != plan || def[0].Range.Start.Line != 1 {
+		t.Errorf("definition = %+v", def)
+	}
+	if string(responses["2"].Result) != "null" {
+		t.Errorf("definition outside a link = %s", responses["2"].Result)
+	}
+
+	var refs []Location
+	json.Unmarshal(responses["3"].Result, &refs)
+	if len(refs) != 3 {
+		t.Errorf("references to note = %+v", refs)
+	}
+	var headingRefs []Location
+	json.Unmarshal(responses["4"].Result, &headingRefs)
+	if len(headingRefs) != 1 || headingRefs[0].URI != home || headingRefs[0].Range.Start != (Position{Line: 1, Character: 4}) {
+		t.Errorf("references to heading = %+v", headingRefs)
+	}
+}
+
+func TestServer_Rename(t *testing.T) {
+	root, opts := newTestVault(t, testNotes, []string{"home.md", "projects/plan.md", "projects/idea.md"})
+	plan := pathToURI(filepath.Join(root, "projects/plan.md"))
+	home := pathToURI(filepath.Join(root, "home.md"))
+	responses, _ := session(t, opts,
+		initialize,
+		didOpen(home, testNotes["home.md"]),
+		positionRequest(1, "textDocument/rename", plan, 0, 0, `,"newName":"roadmap"`),
+		positionRequest(2, "textDocument/rename", plan, 0, 0, `,"newName":"projects/idea"`),
+	)
+
+	var edit struct {
+		DocumentChanges []struct {
+			Kind         string `json:"kind"`
+			NewURI       string `json:"newUri"`
+			TextDocument struct {
+				URI     string `json:"uri"`
+				Version *int   `json:"version"`
+			} `json:"textDocument"`
+			Edits []TextEdit `json:"edits"`
+		} `json:"documentChanges"`
+	}
+	if err := json.Unmarshal(responses["1"].Result, &edit); err != nil || len(edit.DocumentChanges) != 2 {
+		t.Fatalf("rename = %s", responses["1"].Result)
+	}
+
+	change := edit.DocumentChanges[0]
+	if change.TextDocument.URI != home || change.TextDocument.Version == nil || *change.TextDocument.Version != 3 {
+		t.Errorf("rename document = %+v", change.TextDocument)
+	}
+	var texts []string
+	for _, e := range change.Edits {
+		texts = append(texts, e.NewText)
+	}
+	if got := strings.Join(texts, " "); got != "[[roadmap#Goals]] [the plan](projects/roadmap.md) [[roadmap#Nowhere]]" {
+		t.Errorf("rename edits = %s", got)
+	}
+
+	rename := edit.DocumentChanges[1]
+	if rename.Kind != "rename" || rename.NewURI != pathToURI(filepath.Join(root, "projects/roadmap.md")) {
+		t.Errorf("rename file = %+v", rename)
+	}
+
+	if responses["2"].Error == nil || !strings.Contains(responses["2"].Error.Message, "already exists") {
+		t.Errorf("rename onto an existing note = %+v", responses["2"])
+	}
+}
@@ -0,0 +1,87 @@
+package markdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter parses the leading "---" YAML block of a note. Notes without
+// frontmatter return a nil map.
+func Frontmatter(content string) (map[string]interface{}, error) {
+	lines := strings.Split(content, "\n")
+	end := frontmatterEnd(lines)
+	if end == 0 {
+		return nil, nil
+	}
+
+	fm := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end-1], "\n")), &fm); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	return fm, nil
+}
+
+// Body returns the note content after the frontmatter
+func Body(content string) string {
+	lines := strings.Split(content, "\n")
+	return strings.Join(lines[frontmatterEnd(lines):], "\n")
+}
+
+// DateField returns a frontmatter value as a date. Both YAML timestamps
+// and "YYYY-MM-DD" strings (optionally followed by a time) are accepted.
+func DateField(fm map[string]interface{}, key string) (time.Time, bool) {
+	switch v := fm[key].(type) {
+	case time.Time:
+		y, m, d := v.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local), true
+	case string:
+		if len(v) >= len(DateLayout) {
+			if date, err := time.ParseInLocation(DateLayout, v[:len(DateLayout)], time.Local); err == nil {
+				return date, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// StringList returns a frontmatter value that may be a single string, a
+// comma separated string or a YAML list (as Obsidian allows for tags and
+// aliases)
+func StringList(fm map[string]interface{}, key string) []string {
+	var values []string
+	switch v := fm[key].(type) {
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if s := strings.TrimSpace(fmt.Sprint(item)); item != nil && s != "" {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// Title returns a note's title: the frontmatter title, else the first
+// heading, else the file name without extension
+func Title(file, content string) string {
+	if fm, err := Frontmatter(content); err == nil {
+		if title, ok := fm["title"].(string); ok && strings.TrimSpace(title) != "" {
+			return strings.TrimSpace(title)
+		}
+	}
+
+	if headings := Headings(content); len(headings) > 0 {
+		return headings[0].Text
+	}
+
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
@@ -0,0 +1,85 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFrontmatter(t *testing.T) {
+	content := "---\ntitle: Standup\ndate: 2024-03-05\ntags: [work, meetings]\naliases: daily, sync\n---\n# Notes\n"
+
+	fm, err := Frontmatter(content)
+	if err != nil {
+		t.Fatalf("Frontmatter() error = %v", err)
+	}
+	if fm["title"] != "Standup" {
+		t.Errorf("title = %v, expected Standup", fm["title"])
+	}
+
+	date, ok := DateField(fm, "date")
+	if expected := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local); !ok || !date.Equal(expected) {
+		t.Errorf("DateField(date) = %v, %v, expected %v", date, ok, expected)
+	}
+	if got := StringList(fm, "tags"); !reflect.DeepEqual(got, []string{"work", "meetings"}) {
+		t.Errorf("StringList(tags) = %q", got)
+	}
+	if got := StringList(fm, "aliases"); !reflect.DeepEqual(got, []string{"daily", "sync"}) {
+		t.Errorf("StringList(aliases) = %q", got)
+	}
+	if got := Body(content); got != "# Notes\n" {
+		t.Errorf("Body() = %q", got)
+	}
+}
+
+func TestFrontmatter_NoneOrInvalid(t *testing.T) {
+	fm, err := Frontmatter("# Just a heading\n")
+	if fm != nil || err != nil {
+		t.Errorf("Frontmatter() without block = %v, %v, expected nil, nil", fm, err)
+	}
+
+	if _, err := Frontmatter("---\ntitle: [unclosed\n---\n"); err == nil {
+		t.Error("Frontmatter() expected error for invalid YAML")
+	}
+}
+
+func TestDateField(t *testing.T) {
+	expected := time.Date(2024, 3, 5, 0, 0, 0, 0, time.Local)
+	tests := []struct {
+		name  string
+		value interface{}
+		ok    bool
+	}{
+		{"yaml timestamp", time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC), true},
+		{"date string", "2024-03-05", true},
+		{"datetime string", "2024-03-05T09:00", true},
+		{"other string", "next tuesday", false},
+		{"number", 20240305, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			date, ok := DateField(map[string]interface{}{"date": tt.value}, "date")
+			if ok != tt.ok || (ok && !date.Equal(expected)) {
+				t.Errorf("DateField(%v) = %v, %v", tt.value, date, ok)
+			}
+		})
+	}
+}
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"---\ntitle: From Frontmatter\n---\n# Heading\n", "From Frontmatter"},
+		{"---\ndate: 2024-03-05\n---\nintro\n## Second Level\n", "Second Level"},
+		{"#tag only\nplain text\n", "meeting"},
+	}
+
+	for _, tt := range tests {
+		if got := Title("notes/meeting.md", tt.content); got != tt.expected {
+			t.Errorf("Title(%q) = %q, expected %q", tt.content, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package markdown
+
+import "strings"
+
+// Heading is an ATX heading ("## Text") of a note
+type Heading struct {
+	Level int
+	Text  string
+	Line  int
+}
+
+// Headings returns the headings of a note outside frontmatter and code
+func Headings(content string) []Heading {
+	var headings []Heading
+	EachLine(content, func(n int, line string) {
+		if level, text, ok := parseATXHeading(line); ok {
+			headings = append(headings, Heading{Level: level, Text: text, Line: n})
+		}
+	})
+	return headings
+}
+
+// FindHeading returns the heading matching a link fragment, ignoring case
+// and surrounding space
+func FindHeading(headings []Heading, fragment string) (Heading, bool) {
+	fragment = strings.TrimSpace(fragment)
+	for _, h := range headings {
+		if strings.EqualFold(h.Text, fragment) {
+			return h, true
+		}
+	}
+	return Heading{}, false
+}
+
+func parseATXHeading(line string) (int, string, bool) {
+	if !strings.HasPrefix(line, "#") {
+		return 0, "", false
+	}
+	text := strings.TrimLeft(line, "#")
+	level := len(line) - len(text)
+	if level > 6 || (text != "" && !strings.HasPrefix(text, " ") && !strings.HasPrefix(text, "\t")) {
+		return 0, "", false
+	}
+	text = strings.TrimSpace(text)
+	if closed := strings.TrimRight(text, "#"); closed == "" || strings.HasSuffix(closed, " ") {
+		text = strings.TrimSpace(closed) // Optional closing sequence: "## Title ##"
+	}
+	return level, text, text != ""
+}
@@ -0,0 +1,27 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeadings(t *testing.T) {
+	content := "---\ntitle: x\n---\n# Title\n#tag line\n## Closed ##\n```\n# code\n```\n### C#\n####### too deep\n"
+
+	expected := []Heading{
+		{Level: 1, Text: "Title", Line: 4},
+		{Level: 2, Text: "Closed", Line: 6},
+		{Level: 3, Text: "C#", Line: 10},
+	}
+	headings := Headings(content)
+	if !reflect.DeepEqual(headings, expected) {
+		t.Errorf("Headings() = %+v, expected %+v", headings, expected)
+	}
+
+	if h, ok := FindHeading(headings, " closed "); !ok || h.Line != 6 {
+		t.Errorf("FindHeading(closed) = %+v, %v", h, ok)
+	}
+	if _, ok := FindHeading(headings, "missing"); ok {
+		t.Error("FindHeading(missing) should not match")
+	}
+}
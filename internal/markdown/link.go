@@ -0,0 +1,239 @@
+package markdown
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Link is a reference from a note to another note or file, written as a
+// [[wikilink]] or a [markdown](link.md)
+type Link struct {
+	Target   string // Note name or path as written, without fragment or alias
+	Fragment string // Heading or ^block reference after '#'
+	Alias    string // Display text after '|' or between the brackets
+	Embed    bool   // ![[...]] or ![](...)
+	Wiki     bool   // Written as a wikilink
+	Line     int
+	Start    int // Byte offset of the link in its line
+	End      int // Byte offset just after the link
+}
+
+var (
+	wikiLink     = regexp.MustCompile(`(!?)\[\[([^\[\]\n]+?)\]\]`)
+	markdownLink = regexp.MustCompile(`(!?)\[([^\[\]]*)\]\(\s*(<[^>]+>|[^)\s]+)(?:\s+"[^"]*")?\s*\)`)
+	urlScheme    = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+)
+
+// ParseLinks returns the links to local notes and files in a note, skipping
+// frontmatter, code blocks, inline code and external URLs
+func ParseLinks(content string) []Link {
+	var links []Link
+	EachLine(content, func(n int, line string) {
+		scanLinks(line, func(link Link, start, end int) {
+			link.Line, link.Start, link.End = n, start, end
+			links = append(links, link)
+		})
+	})
+	return links
+}
+
+// ParseLineLinks returns the local links in a single line of text
+func ParseLineLinks(line string) []Link {
+	var links []Link
+	scanLinks(line, func(link Link, start, end int) {
+		link.Start, link.End = start, end
+		links = append(links, link)
+	})
+	return links
+}
+
+// ReplaceLinks rewrites every local link in content with the text returned
+// by fn, leaving code blocks, inline code and frontmatter untouched
+func ReplaceLinks(content string, fn func(Link) string) string {
+	lines := strings.Split(content, "\n")
+	EachLine(content, func(n int, line string) {
+		var b strings.Builder
+		last := 0
+		scanLinks(line, func(link Link, start, end int) {
+			link.Line, link.Start, link.End = n, start, end
+			b.WriteString(line[last:start])
+			b.WriteString(fn(link))
+			last = end
+		})
+		if last > 0 {
+			b.WriteString(line[last:])
+			lines[n-1] = b.String()
+		}
+	})
+	return strings.Join(lines, "\n")
+}
+
+// scanLinks calls fn with each local link in a line and its byte offsets,
+// in order of appearance
+func scanLinks(line string, fn func(link Link, start, end int)) {
+	type match struct {
+		link       Link
+		start, end int
+	}
+	var matches []match
+
+	for _, span := range codeFreeSpans(line) {
+		text := line[span[0]:span[1]]
+		for _, m := range wikiLink.FindAllStringSubmatchIndex(text, -1) {
+			link := parseWikiLink(text[m[4]:m[5]])
+			link.Embed = m[3] > m[2]
+			if link.Target == "" && link.Fragment == "" {
+				continue
+			}
+			matches = append(matches, match{link, span[0] + m[0], span[0] + m[1]})
+		}
+		for _, m := range markdownLink.FindAllStringSubmatchIndex(text, -1) {
+			link, ok := parseMarkdownLink(text[m[4]:m[5]], text[m[6]:m[7]])
+			if !ok {
+				continue
+			}
+			link.Embed = m[3] > m[2]
+			matches = append(matches, match{link, span[0] + m[0], span[0] + m[1]})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	end := 0
+	for _, m := range matches {
+		if m.start < end {
+			continue // Overlaps a previous link, e.g. [x]([[y]])
+		}
+		fn(m.link, m.start, m.end)
+		end = m.end
+	}
+}
+
+// codeFreeSpans returns the [start, end) byte ranges of a line outside
+// `inline code`
+func codeFreeSpans(line string) [][2]int {
+	var spans [][2]int
+	start := 0
+	for {
+		open := strings.Index(line[start:], "`")
+		if open < 0 {
+			break
+		}
+		open += start
+		ticks := len(line[open:]) - len(strings.TrimLeft(line[open:], "`"))
+		delim := line[open : open+ticks]
+		close := strings.Index(line[open+ticks:], delim)
+		if close < 0 {
+			break
+		}
+		spans = append(spans, [2]int{start, open})
+		start = open + ticks + close + ticks
+	}
+	return append(spans, [2]int{start, len(line)})
+}
+
+// parseWikiLink splits "target#fragment|alias"
+func parseWikiLink(inner string) Link {
+	link := Link{Wiki: true}
+	if i := strings.Index(inner, "|"); i >= 0 {
+		link.Alias = strings.TrimSpace(inner[i+1:])
+		inner = inner[:i]
+	}
+	if i := strings.Index(inner, "#"); i >= 0 {
+		link.Fragment = strings.TrimSpace(inner[i+1:])
+		inner = inner[:i]
+	}
+	link.Target = strings.TrimSpace(inner)
+	return link
+}
+
+// parseMarkdownLink parses the destination of a [text](dest) link,
+// rejecting external URLs and in-page anchors
+func parseMarkdownLink(text, dest string) (Link, bool) {
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	if dest == "" || strings.HasPrefix(dest, "#") || urlScheme.MatchString(dest) || strings.HasPrefix(dest, "//") {
+		return Link{}, false
+	}
+
+	link := Link{Alias: text}
+	if i := strings.Index(dest, "#"); i >= 0 {
+		link.Fragment = dest[i+1:]
+		dest = dest[:i]
+	}
+	if unescaped, err := url.PathUnescape(dest); err == nil {
+		dest = unescaped
+	}
+	link.Target = dest
+	return link, true
+}
+
+// Resolver maps link targets to vault files the way Obsidian does: by path
+// relative to the linking note, by path from the vault root, then by file
+// name, preferring the shortest path. Matching ignores case and a missing
+// ".md" extension.
+type Resolver struct {
+	paths map[string]string   // Lower-case path to file
+	names map[string][]string // Lower-case base name to files, shortest first
+}
+
+// NewResolver creates a resolver over vault-relative file paths
+func NewResolver(files []string) *Resolver {
+	r := &Resolver{
+		paths: make(map[string]string, len(files)),
+		names: make(map[string][]string, len(files)),
+	}
+	for _, file := range files {
+		slashed := path.Clean(strings.ReplaceAll(file, "\\", "/"))
+		key := strings.ToLower(slashed)
+		r.paths[key] = file
+		base := path.Base(key)
+		r.names[base] = append(r.names[base], file)
+	}
+	for _, candidates := range r.names {
+		sort.Slice(candidates, func(i, j int) bool {
+			if len(candidates[i]) != len(candidates[j]) {
+				return len(candidates[i]) < len(candidates[j])
+			}
+			return candidates[i] < candidates[j]
+		})
+	}
+	return r
+}
+
+// Resolve returns the file a link target in note "from" refers to. An empty
+// target refers to the note itself.
+func (r *Resolver) Resolve(from, target string) (string, bool) {
+	if target == "" {
+		return from, from != ""
+	}
+
+	target = strings.ToLower(strings.ReplaceAll(target, "\\", "/"))
+	variants := []string{target}
+	if !strings.HasSuffix(target, ".md") {
+		variants = []string{target + ".md", target}
+	}
+
+	dir := path.Dir(strings.ToLower(strings.ReplaceAll(from, "\\", "/")))
+	for _, v := range variants {
+		if !strings.HasPrefix(v, "/") {
+			if file, ok := r.paths[path.Join(dir, v)]; ok {
+				return file, true
+			}
+		}
+		if file, ok := r.paths[path.Clean(strings.TrimPrefix(v, "/"))]; ok {
+			return file, true
+		}
+	}
+
+	for _, v := range variants {
+		suffix := "/" + strings.TrimPrefix(path.Clean(v), "/")
+		for _, file := range r.names[path.Base(v)] {
+			if strings.HasSuffix("/"+strings.ToLower(strings.ReplaceAll(file, "\\", "/")), suffix) {
+				return file, true
+			}
+		}
+	}
+	return "", false
+}
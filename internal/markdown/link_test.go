@@ -0,0 +1,71 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLinks(t *testing.T) {
+	content := "---\nup: \"[[Parent]]\"\n---\n" +
+		"See [[Plan#Next Steps|the plan]] and ![[cat.png|200]]\n" +
+		"`[[not a link]]` but [doc](docs/My%20Doc.md#intro) and [web](https://example.com)\n" +
+		"```\n[[in code]]\n```\n" +
+		"![diagram](img/flow.svg) [[#Local]]\n"
+
+	expected := []Link{
+		{Target: "Plan", Fragment: "Next Steps", Alias: "the plan", Wiki: true, Line: 4, Start: 4, End: 32},
+		{Target: "cat.png", Alias: "200", Embed: true, Wiki: true, Line: 4, Start: 37, End: 53},
+		{Target: "docs/My Doc.md", Fragment: "intro", Alias: "doc", Line: 5, Start: 21, End: 50},
+		{Target: "img/flow.svg", Alias: "diagram", Embed: true, Line: 9, Start: 0, End: 24},
+		{Fragment: "Local", Wiki: true, Line: 9, Start: 25, End: 35},
+	}
+	if got := ParseLinks(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParseLinks() =\n%+v\nexpected\n%+v", got, expected)
+	}
+}
+
+func TestReplaceLinks(t *testing.T) {
+	content := "a [[One]] b `[[Two]]` c [x](three.md)\n```\n[[Four]]\n```\n"
+	got := ReplaceLinks(content, func(l Link) string { return "<" + l.Target + ">" })
+
+	expected := "a <One> b `[[Two]]` c <three.md>\n```\n[[Four]]\n```\n"
+	if got != expected {
+		t.Errorf("ReplaceLinks() = %q, expected %q", got, expected)
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	r := NewResolver([]string{
+		"Home.md",
+		"projects/Plan.md",
+		"archive/old/Plan.md",
+		"projects/notes/Plan.md",
+		"img/cat.png",
+		"v1.2 notes.md",
+	})
+
+	tests := []struct {
+		from, target string
+		expected     string
+		ok           bool
+	}{
+		{"Home.md", "home", "Home.md", true},
+		{"Home.md", "Plan", "projects/Plan.md", true},                     // Shortest path wins
+		{"projects/notes/Plan.md", "Plan", "projects/notes/Plan.md", true}, // Relative to the note first
+		{"Home.md", "old/Plan", "archive/old/Plan.md", true},
+		{"projects/Plan.md", "../Home.md", "Home.md", true},
+		{"Home.md", "cat.png", "img/cat.png", true},
+		{"Home.md", "v1.2 notes", "v1.2 notes.md", true},
+		{"Home.md", "", "Home.md", true},
+		{"Home.md", "Missing", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, ok := r.Resolve(tt.from, tt.target)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("Resolve(%q, %q) = %q, %v, expected %q, %v", tt.from, tt.target, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
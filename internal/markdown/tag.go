@@ -0,0 +1,76 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ParseTags returns the #tags in a line of text, without the leading '#',
+// in order of appearance. Headings ("# Title"), pure numbers ("#123") and
+// anchors inside words ("page#section") are not tags.
+func ParseTags(text string) []string {
+	var tags []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' || (i > 0 && !unicode.IsSpace(runes[i-1]) && !strings.ContainsRune("([,", runes[i-1])) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && isTagRune(runes[j]) {
+			j++
+		}
+		tag := strings.TrimRight(string(runes[i+1:j]), "/")
+		if tag != "" && strings.IndexFunc(tag, func(r rune) bool { return !unicode.IsDigit(r) }) >= 0 {
+			tags = append(tags, tag)
+		}
+		i = j - 1
+	}
+	return tags
+}
+
+// HasTag reports whether tags contains tag or one of its nested children
+// (e.g. "work" matches "work/meetings"). The comparison ignores case and a
+// leading '#'.
+func HasTag(tags []string, tag string) bool {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	for _, t := range tags {
+		t = strings.ToLower(t)
+		if t == tag || strings.HasPrefix(t, tag+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// NoteTags returns the tags of a note: frontmatter "tags" followed by inline
+// #tags outside code, without duplicates (ignoring case)
+func NoteTags(content string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	add := func(tag string) {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "#")
+		if key := strings.ToLower(tag); tag != "" && !seen[key] {
+			seen[key] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	if fm, err := Frontmatter(content); err == nil {
+		for _, tag := range StringList(fm, "tags") {
+			add(tag)
+		}
+	}
+	EachLine(content, func(n int, line string) {
+		for _, span := range codeFreeSpans(line) {
+			for _, tag := range ParseTags(line[span[0]:span[1]]) {
+				add(tag)
+			}
+		}
+	})
+	return tags
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '/'
+}
@@ -0,0 +1,55 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{"call Bob #work #urgent", []string{"work", "urgent"}},
+		{"#project/alpha kickoff", []string{"project/alpha"}},
+		{"see page#section and issue #123", nil},
+		{"# Heading", nil},
+		{"(#inline) and #trailing/", []string{"inline", "trailing"}},
+		{"#café #v2", []string{"café", "v2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := ParseTags(tt.text); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseTags(%q) = %q, expected %q", tt.text, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	tags := []string{"Work/meetings", "home"}
+
+	tests := map[string]bool{
+		"work":          true,
+		"#work":         true,
+		"work/meetings": true,
+		"home":          true,
+		"wor":           false,
+		"meetings":      false,
+	}
+	for tag, expected := range tests {
+		if got := HasTag(tags, tag); got != expected {
+			t.Errorf("HasTag(%q) = %v, expected %v", tag, got, expected)
+		}
+	}
+}
+
+func TestNoteTags(t *testing.T) {
+	content := "---\ntags: [work, \"#Project/alpha\"]\n---\nNotes #work #review `#code`\n```\n#fenced\n```\n"
+
+	expected := []string{"work", "Project/alpha", "review"}
+	if got := NoteTags(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("NoteTags() = %q, expected %q", got, expected)
+	}
+}
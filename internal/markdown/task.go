@@ -0,0 +1,214 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DateLayout is the date format used in task metadata and frontmatter
+const DateLayout = "2006-01-02"
+
+// Priority of a task, following the Obsidian Tasks plugin. Tasks without a
+// priority sort between medium and low.
+type Priority int
+
+const (
+	PriorityLowest Priority = iota - 2
+	PriorityLow
+	PriorityNone
+	PriorityMedium
+	PriorityHigh
+	PriorityHighest
+)
+
+// priorityEmojis in precedence order: the first one found wins
+var priorityEmojis = []struct {
+	emoji    string
+	priority Priority
+}{
+	{"🔺", PriorityHighest},
+	{"⏫", PriorityHigh},
+	{"🔼", PriorityMedium},
+	{"🔽", PriorityLow},
+	{"⏬", PriorityLowest},
+}
+
+// String returns the priority name
+func (p Priority) String() string {
+	switch p {
+	case PriorityHighest:
+		return "highest"
+	case PriorityHigh:
+		return "high"
+	case PriorityMedium:
+		return "medium"
+	case PriorityLow:
+		return "low"
+	case PriorityLowest:
+		return "lowest"
+	default:
+		return "none"
+	}
+}
+
+// Task is a markdown checkbox item such as "- [ ] call Bob 📅 2024-03-05"
+type Task struct {
+	File        string    // Note path relative to the vault
+	Line        int       // 1-based line number
+	Status      rune      // Checkbox character: ' ' open, 'x' done, '-' cancelled, ...
+	Description string    // Text without the Tasks plugin metadata
+	Due         time.Time // 📅 (zero when unset)
+	Scheduled   time.Time // ⏳
+	Start       time.Time // 🛫
+	Created     time.Time // ➕
+	Completed   time.Time // ✅
+	Recurrence  string    // 🔁 rule, e.g. "every week"
+	Priority    Priority
+	Tags        []string
+}
+
+// ID identifies a task by its location, e.g. "projects/plan.md:12"
+func (t Task) ID() string {
+	return fmt.Sprintf("%s:%d", t.File, t.Line)
+}
+
+// Done reports whether the task is checked off
+func (t Task) Done() bool {
+	return t.Status == 'x' || t.Status == 'X'
+}
+
+// Open reports whether the task still needs doing (not done or cancelled)
+func (t Task) Open() bool {
+	return !t.Done() && t.Status != '-'
+}
+
+var (
+	taskLine = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+\[)(.)(\]\s*)(.*)$`)
+	taskDate = regexp.MustCompile(`(📅|⏳|🛫|➕|✅)\x{FE0F}?\s*(\d{4}-\d{2}-\d{2})`)
+	taskRule = regexp.MustCompile(`🔁\x{FE0F}?\s*([^📅⏳🛫➕✅🔺⏫🔼🔽⏬#]*)`)
+	doneDate = regexp.MustCompile(`\s*✅\x{FE0F}?\s*\d{4}-\d{2}-\d{2}`)
+)
+
+// ParseTasks returns the tasks in a note. Frontmatter and fenced code
+// blocks are skipped.
+func ParseTasks(file, content string) []Task {
+	var tasks []Task
+	EachLine(content, func(n int, line string) {
+		if task, ok := ParseTask(line); ok {
+			task.File = file
+			task.Line = n
+			tasks = append(tasks, task)
+		}
+	})
+	return tasks
+}
+
+// ParseTask parses a single task line
+func ParseTask(line string) (Task, bool) {
+	m := taskLine.FindStringSubmatch(line)
+	if m == nil {
+		return Task{}, false
+	}
+
+	task := Task{Status: []rune(m[2])[0], Priority: PriorityNone}
+	text := m[4]
+
+	for _, dm := range taskDate.FindAllStringSubmatch(text, -1) {
+		date, err := time.ParseInLocation(DateLayout, dm[2], time.Local)
+		if err != nil {
+			continue
+		}
+		switch dm[1] {
+		case "📅":
+			task.Due = date
+		case "⏳":
+			task.Scheduled = date
+		case "🛫":
+			task.Start = date
+		case "➕":
+			task.Created = date
+		case "✅":
+			task.Completed = date
+		}
+	}
+	text = taskDate.ReplaceAllString(text, "")
+
+	if rm := taskRule.FindStringSubmatch(text); rm != nil {
+		task.Recurrence = strings.TrimSpace(rm[1])
+		text = taskRule.ReplaceAllString(text, "")
+	}
+
+	for _, p := range priorityEmojis {
+		if strings.Contains(text, p.emoji) {
+			if task.Priority == PriorityNone {
+				task.Priority = p.priority
+			}
+			text = strings.ReplaceAll(text, p.emoji, "")
+		}
+	}
+
+	task.Tags = ParseTags(text)
+	task.Description = strings.Join(strings.Fields(text), " ")
+	return task, true
+}
+
+// ToggleTask checks off an open task line, adding a ✅ completion date, or
+// reopens a done one, removing it. A line ending is kept at the end. It
+// reports false for non-task lines.
+func ToggleTask(line string, today time.Time) (string, bool) {
+	text := strings.TrimRight(line, "\r\n")
+	eol := line[len(text):]
+	m := taskLine.FindStringSubmatch(text)
+	if m == nil {
+		return line, false
+	}
+
+	if status := m[2]; status == "x" || status == "X" {
+		return m[1] + " " + m[3] + doneDate.ReplaceAllString(m[4], "") + eol, true
+	}
+
+	return m[1] + "x" + m[3] + strings.TrimRight(m[4], " ") + " ✅ " + today.Format(DateLayout) + eol, true
+}
+
+// EachLine calls fn with the 1-based number of every content line,
+// skipping YAML frontmatter and fenced code blocks
+func EachLine(content string, fn func(n int, line string)) {
+	lines := strings.Split(content, "\n")
+	start := 0
+	if end := frontmatterEnd(lines); end > 0 {
+		start = end
+	}
+
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+		fn(i+1, line)
+	}
+}
+
+// frontmatterEnd returns the index of the first line after a leading
+// "---" frontmatter block, or 0 when there is none
+func frontmatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r") != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if l := strings.TrimRight(lines[i], "\r"); l == "---" || l == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
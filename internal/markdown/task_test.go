@@ -0,0 +1,120 @@
+package markdown
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, _ := time.ParseInLocation(DateLayout, s, time.Local)
+	return d
+}
+
+func TestParseTask(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		ok       bool
+		expected Task
+	}{
+		{
+			name:     "plain open task",
+			line:     "- [ ] call Bob",
+			ok:       true,
+			expected: Task{Status: ' ', Description: "call Bob", Priority: PriorityNone},
+		},
+		{
+			name: "tasks plugin metadata",
+			line: "  * [ ] ship release ⏫ 🔁 every week 📅 2024-03-05 ⏳ 2024-03-01 #work",
+			ok:   true,
+			expected: Task{
+				Status:      ' ',
+				Description: "ship release #work",
+				Due:         date("2024-03-05"),
+				Scheduled:   date("2024-03-01"),
+				Recurrence:  "every week",
+				Priority:    PriorityHigh,
+				Tags:        []string{"work"},
+			},
+		},
+		{
+			name: "done with completion date",
+			line: "1. [x] write report 🔽 ✅ 2024-02-28",
+			ok:   true,
+			expected: Task{
+				Status:      'x',
+				Description: "write report",
+				Completed:   date("2024-02-28"),
+				Priority:    PriorityLow,
+			},
+		},
+		{
+			name: "not a task",
+			line: "- plain bullet [ ] later",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, ok := ParseTask(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ParseTask(%q) ok = %v, expected %v", tt.line, ok, tt.ok)
+			}
+			if ok && !reflect.DeepEqual(task, tt.expected) {
+				t.Errorf("ParseTask(%q) = %+v, expected %+v", tt.line, task, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseTasks(t *testing.T) {
+	content := "---\ntags: [x]\n- [ ] not a task\n---\n# Plan\n- [ ] first\n```\n- [ ] in code\n```\n- [-] cancelled\n\t- [/] nested in progress\n"
+
+	tasks := ParseTasks("plan.md", content)
+	if len(tasks) != 3 {
+		t.Fatalf("Expected 3 tasks, got %d: %+v", len(tasks), tasks)
+	}
+
+	expected := []struct {
+		id   string
+		open bool
+	}{
+		{"plan.md:6", true},
+		{"plan.md:10", false},
+		{"plan.md:11", true},
+	}
+	for i, e := range expected {
+		if tasks[i].ID() != e.id {
+			t.Errorf("Expected task %d id %s, got %s", i, e.id, tasks[i].ID())
+		}
+		if tasks[i].Open() != e.open {
+			t.Errorf("Expected task %s open = %v", e.id, e.open)
+		}
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	today := date("2024-03-05")
+
+	tests := []struct {
+		line     string
+		expected string
+		ok       bool
+	}{
+		{"- [ ] call Bob #work", "- [x] call Bob #work ✅ 2024-03-05", true},
+		{"  - [x] call Bob ✅ 2024-03-01 #work", "  - [ ] call Bob #work", true},
+		{"- [X] shouted", "- [ ] shouted", true},
+		{"- [ ] crlf \r", "- [x] crlf ✅ 2024-03-05\r", true},
+		{"- [x] crlf ✅ 2024-03-01\r", "- [ ] crlf\r", true},
+		{"- not a task", "- not a task", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ToggleTask(tt.line, today)
+		if ok != tt.ok || got != tt.expected {
+			t.Errorf("ToggleTask(%q) = %q, %v; expected %q, %v", tt.line, got, ok, tt.expected, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// protocolVersions are the MCP revisions the server speaks, newest first
+var protocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Tool is an MCP tool backed by a Go function. Call receives the raw
+// arguments object and returns text or a value rendered as JSON.
+type Tool struct {
+	Name        string
+	Description string
+	InputSchema map[string]interface{}
+	Call        func(args json.RawMessage) (interface{}, error)
+}
+
+// Server serves tools over the MCP stdio transport: newline-delimited
+// JSON-RPC 2.0 messages on stdin and stdout
+type Server struct {
+	name    string
+	version string
+	tools   []Tool
+	byName  map[string]Tool
+}
+
+// NewServer creates a server announcing itself with name and version
+func NewServer(name, version string, tools []Tool) *Server {
+	s := &Server{name: name, version: version, tools: tools, byName: map[string]Tool{}}
+	for _, tool := range tools {
+		s.byName[tool.Name] = tool
+	}
+	return s
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve handles requests from in until it is closed. Requests are handled
+// one at a time, so tools never run concurrently.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	enc := json.NewEncoder(out)
+	send := func(resp response) error { return enc.Encode(resp) }
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := send(errorResponse(json.RawMessage("null"), codeParseError, "parse error")); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.JSONRPC != "2.0" || req.Method == "" {
+			if req.ID == nil {
+				continue
+			}
+			if err := send(errorResponse(req.ID, codeInvalidRequest, "invalid request")); err != nil {
+				return err
+			}
+			continue
+		}
+		if req.ID == nil {
+			continue // Notifications such as notifications/initialized need no reply
+		}
+
+		result, rerr := s.handle(req)
+		resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+		if err := send(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle dispatches a request to its method
+func (s *Server) handle(req request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		json.Unmarshal(req.Params, &params)
+		version := protocolVersions[0]
+		for _, v := range protocolVersions {
+			if v == params.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]interface{}{
+			"protocolVersion": version,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{"listChanged": false}},
+			"serverInfo":      map[string]interface{}{"name": s.name, "version": s.version},
+		}, nil
+
+	case "ping":
+		return struct{}{}, nil
+
+	case "tools/list":
+		tools := make([]map[string]interface{}, len(s.tools))
+		for i, tool := range s.tools {
+			schema := tool.InputSchema
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object"}
+			}
+			tools[i] = map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"inputSchema": schema,
+			}
+		}
+		return map[string]interface{}{"tools": tools}, nil
+
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid params"}
+		}
+		tool, ok := s.byName[params.Name]
+		if !ok {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", params.Name)}
+		}
+		if len(params.Arguments) == 0 {
+			params.Arguments = json.RawMessage("{}")
+		}
+		return callResult(tool.Call(params.Arguments)), nil
+	}
+
+	return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+}
+
+// callResult wraps a tool's output as MCP text content. Tool failures are
+// reported in the result with isError so the model can see them.
+func callResult(value interface{}, err error) map[string]interface{} {
+	if err != nil {
+		return map[string]interface{}{
+			"content": []map[string]string{{"type": "text", "text": err.Error()}},
+			"isError": true,
+		}
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return callResult(nil, err)
+		}
+		text = string(data)
+	}
+	return map[string]interface{}{
+		"content": []map[string]string{{"type": "text", "text": text}},
+		"isError": false,
+	}
+}
+
+func errorResponse(id json.RawMessage, code int, message string) response {
+	return response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: code, Message: message}}
+}
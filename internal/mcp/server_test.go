@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func serve(t *testing.T, s *Server, messages ...string) []map[string]interface{} {
+	t.Helper()
+	var out bytes.Buffer
+	if err := s.Serve(strings.NewReader(strings.Join(messages, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	var responses []map[string]interface{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]interface{}
+		if err := dec.Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func testServer() *Server {
+	return NewServer("test", "1.0", []Tool{
+		{
+			Name:        "echo",
+			Description: "Echo the text argument",
+			Call: func(args json.RawMessage) (interface{}, error) {
+				var a struct{ Text string }
+				json.Unmarshal(args, &a)
+				return a.Text, nil
+			},
+		},
+		{
+			Name: "list",
+			Call: func(json.RawMessage) (interface{}, error) { return []string{"a", "b"}, nil },
+		},
+		{
+			Name: "fail",
+			Call: func(json.RawMessage) (interface{}, error) { return nil, errors.New("boom") },
+		},
+	})
+}
+
+func TestServer_Initialize(t *testing.T) {
+	responses := serve(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`,
+		`{"jsonrpc":"2.0","id":"p","method":"ping"}`,
+	)
+
+	if len(responses) != 3 {
+		t.Fatalf("got %d responses, expected 3 (notifications get none)", len(responses))
+	}
+	result := responses[0]["result"].(map[string]interface{})
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("protocolVersion = %v, expected the client's version", result["protocolVersion"])
+	}
+	if info := result["serverInfo"].(map[string]interface{}); info["name"] != "test" || info["version"] != "1.0" {
+		t.Errorf("serverInfo = %v", info)
+	}
+	if v := responses[1]["result"].(map[string]interface{})["protocolVersion"]; v != protocolVersions[0] {
+		t.Errorf("unsupported version negotiated to %v, expected %s", v, protocolVersions[0])
+	}
+	if responses[2]["id"] != "p" {
+		t.Errorf("ping id = %v, expected p", responses[2]["id"])
+	}
+}
+
+func TestServer_Tools(t *testing.T) {
+	responses := serve(t, testServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"text":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"list"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"fail","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"missing"}}`,
+	)
+
+	tools := responses[0]["result"].(map[string]interface{})["tools"].([]interface{})
+	if len(tools) != 3 || tools[0].(map[string]interface{})["inputSchema"] == nil {
+		t.Errorf("tools/list = %v", tools)
+	}
+
+	text := func(resp map[string]interface{}) (string, bool) {
+		result := resp["result"].(map[string]interface{})
+		content := result["content"].([]interface{})[0].(map[string]interface{})
+		return content["text"].(string), result["isError"].(bool)
+	}
+	if got, isErr := text(responses[1]); got != "hi" || isErr {
+		t.Errorf("echo = %q, %v", got, isErr)
+	}
+	if got, _ := text(responses[2]); !strings.Contains(got, `"a"`) {
+		t.Errorf("list = %q, expected JSON", got)
+	}
+	if got, isErr := text(responses[3]); got != "boom" || !isErr {
+		t.Errorf("fail = %q, %v, expected a tool error", got, isErr)
+	}
+	if e := responses[4]["error"].(map[string]interface{}); e["code"] != float64(codeInvalidParams) {
+		t.Errorf("unknown tool error = %v", e)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	responses := serve(t, testServer(),
+		`{not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"1.0","id":2,"method":"ping"}`,
+	)
+
+	codes := []float64{codeParseError, codeMethodNotFound, codeInvalidRequest}
+	if len(responses) != len(codes) {
+		t.Fatalf("got %d responses, expected %d", len(responses), len(codes))
+	}
+	for i, code := range codes {
+		if e, ok := responses[i]["error"].(map[string]interface{}); !ok || e["code"] != code {
+			t.Errorf("response %d = %v, expected error code %v", i, responses[i], code)
+		}
+	}
+}
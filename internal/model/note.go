@@ -0,0 +1,70 @@
+// Package model holds the data types shared by services, commands and the
+// APIs built on them.
+package model
+
+import "time"
+
+// Git states of a note, from git status
+const (
+	GitClean      = "clean"
+	GitModified   = "modified"
+	GitAdded      = "added"
+	GitDeleted    = "deleted"
+	GitRenamed    = "renamed"
+	GitUntracked  = "untracked"
+	GitConflicted = "conflicted"
+)
+
+// NoteInfo describes a note, or another vault file, in listings. Producers
+// fill in what they know: a vault walk gives the path and modification
+// time, frecency adds the score, and reading the note adds its title, tags
+// and type.
+type NoteInfo struct {
+	Path     string    `json:"path"` // Vault-relative
+	Title    string    `json:"title,omitempty"`
+	Modified time.Time `json:"mtime"`
+	Score    float64   `json:"score"` // Frecency; higher is more relevant
+	Tags     []string  `json:"tags,omitempty"`
+	Git      string    `json:"git,omitempty"`     // One of the Git states; empty outside git
+	Type     string    `json:"type,omitempty"`    // File type, e.g. md or canvas
+	Summary  string    `json:"summary,omitempty"` // Canvases: their cards and links
+}
+
+// TaskInfo is a checkbox task in listings. Its NoteInfo describes the note
+// the task is in, except for Tags, which are the task's own.
+type TaskInfo struct {
+	NoteInfo
+	Line      int      `json:"line,omitempty"`   // 1-based line in the note
+	Status    string   `json:"status,omitempty"` // Checkbox character, e.g. " " or "x"
+	Text      string   `json:"text,omitempty"`   // Description without the metadata
+	Due       string   `json:"due,omitempty"`    // Dates as YYYY-MM-DD
+	Scheduled string   `json:"scheduled,omitempty"`
+	Start     string   `json:"start,omitempty"`
+	Completed string   `json:"completed,omitempty"`
+	Priority  string   `json:"priority,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
+
+// Agenda item kinds
+const (
+	AgendaOverdue   = "overdue"
+	AgendaDue       = "due"
+	AgendaScheduled = "scheduled"
+	AgendaNote      = "note"
+)
+
+// AgendaItem is an agenda entry: a task due or scheduled on Day, or a note
+// dated on it. Notes leave the task fields empty.
+type AgendaItem struct {
+	TaskInfo
+	Day  string `json:"day"`  // YYYY-MM-DD; the due date of overdue tasks
+	Kind string `json:"kind"` // One of the agenda item kinds
+}
+
+// MatchInfo is a note matching a search, with its first matching line
+// when the match is in the content rather than the path
+type MatchInfo struct {
+	NoteInfo
+	Line int    `json:"line,omitempty"`
+	Text string `json:"text,omitempty"`
+}
@@ -0,0 +1,135 @@
+// Package plugin finds and runs external ob-cli commands: executables
+// named ob-cli-<name> on PATH, which run as "ob-cli <name>" the way git
+// runs git-<name>.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shalomb/ob-cli/internal/diag"
+)
+
+// Prefix starts the file name of every plugin
+const Prefix = "ob-cli-"
+
+// Plugin is an external command
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Context is what a plugin is told about the vault it runs for
+type Context struct {
+	Vault   string `json:"vault"`
+	Mode    string `json:"mode"`
+	GitRoot string `json:"git_root,omitempty"`
+	Editor  string `json:"editor,omitempty"`
+	CLI     string `json:"cli,omitempty"` // The ob-cli executable, to call back
+	Version string `json:"version,omitempty"`
+}
+
+// Environ returns the context as OB_* environment variables. Unset fields
+// are left out.
+func (c Context) Environ() []string {
+	var env []string
+	for _, v := range []struct{ name, value string }{
+		{"OB_VAULT", c.Vault},
+		{"OB_MODE", c.Mode},
+		{"OB_GIT_ROOT", c.GitRoot},
+		{"OB_EDITOR", c.Editor},
+		{"OB_CLI", c.CLI},
+	} {
+		if v.value != "" {
+			env = append(env, v.name+"="+v.value)
+		}
+	}
+	return env
+}
+
+// ExitError reports a plugin that exited with a non-zero status, which
+// ob-cli exits with too
+type ExitError struct {
+	Name string
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("plugin %s exited with status %d", e.Name, e.Code)
+}
+
+// Discover lists the plugins in the directories of path, a PATH-style
+// list, by name. A plugin found earlier in path hides later ones of the
+// same name.
+func Discover(path string) []Plugin {
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(path) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), Prefix)
+			if !ok || name == "" || seen[name] {
+				continue
+			}
+			file := filepath.Join(dir, entry.Name())
+			if isExecutable(file) {
+				seen[name] = true
+				plugins = append(plugins, Plugin{Name: name, Path: file})
+			}
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Find looks up the plugin called name in path
+func Find(path, name string) (Plugin, bool) {
+	if name == "" || strings.ContainsRune(name, filepath.Separator) {
+		return Plugin{}, false
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if file := filepath.Join(dir, Prefix+name); dir != "" && isExecutable(file) {
+			return Plugin{Name: name, Path: file}, true
+		}
+	}
+	return Plugin{}, false
+}
+
+func isExecutable(file string) bool {
+	info, err := os.Stat(file)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// Run runs p with args and the standard streams of ob-cli, adding c to its
+// environment. The plugin handles interrupts itself, so ctx only carries
+// diagnostics.
+func Run(ctx context.Context, p Plugin, c Context, args []string) error {
+	cmd := exec.Command(p.Path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), c.Environ()...)
+
+	err := diag.Run(ctx, cmd)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			code = 1 // Killed by a signal
+		}
+		return &ExitError{Name: p.Name, Code: code}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to run plugin %s: %w", p.Name, err)
+	}
+	return nil
+}
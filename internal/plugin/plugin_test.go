@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func writeScript(t *testing.T, dir, name, body string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body), mode); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	standup := writeScript(t, first, "ob-cli-standup", "", 0755)
+	writeScript(t, second, "ob-cli-standup", "", 0755) // Hidden by the first
+	review := writeScript(t, second, "ob-cli-weekly-review", "", 0755)
+	writeScript(t, first, "ob-cli-notes.txt", "", 0644) // Not executable
+	writeScript(t, first, "other-tool", "", 0755)
+	os.Mkdir(filepath.Join(first, "ob-cli-dir"), 0755)
+
+	path := strings.Join([]string{first, filepath.Join(first, "missing"), second}, string(filepath.ListSeparator))
+	want := []Plugin{{"standup", standup}, {"weekly-review", review}}
+	if got := Discover(path); !reflect.DeepEqual(got, want) {
+		t.Errorf("Discover() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"standup", standup},
+		{"weekly-review", review},
+		{"notes.txt", ""},
+		{"dir", ""},
+		{"missing", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		p, ok := Find(path, tt.name)
+		if ok != (tt.want != "") || p.Path != tt.want {
+			t.Errorf("Find(%q) = %v, %v, want %q", tt.name, p, ok, tt.want)
+		}
+	}
+}
+
+func TestContext_Environ(t *testing.T) {
+	c := Context{Vault: "/notes", Mode: "obsidian", Editor: "nvim"}
+	want := []string{"OB_VAULT=/notes", "OB_MODE=obsidian", "OB_EDITOR=nvim"}
+	if got := c.Environ(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() = %v, want %v", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a POSIX shell")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	p := Plugin{Name: "standup", Path: writeScript(t, dir, "ob-cli-standup",
+		`echo "$OB_VAULT $OB_MODE $OB_GIT_ROOT $*" > "`+out+`"; exit 3`, 0755)}
+
+	err := Run(context.Background(), p, Context{Vault: "/notes", Mode: "tips", GitRoot: "/notes"}, []string{"--since", "monday"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 3 {
+		t.Fatalf("Run() error = %v, want exit status 3", err)
+	}
+	if data, _ := os.ReadFile(out); string(data) != "/notes tips /notes --since monday\n" {
+		t.Errorf("plugin saw %q", data)
+	}
+
+	if err := Run(context.Background(), Plugin{Name: "gone", Path: filepath.Join(dir, "gone")}, Context{}, nil); err == nil || errors.As(err, &exitErr) {
+		t.Errorf("Run() of a missing plugin error = %v", err)
+	}
+}
@@ -0,0 +1,431 @@
+// Package trash deletes vault files the way Obsidian does: into the
+// system trash, the vault's .trash folder or permanently, following the
+// "trashOption" setting in .obsidian/app.json
+package trash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// Obsidian's trashOption values
+const (
+	OptionSystem = "system" // The operating system's trash (Obsidian's default)
+	OptionLocal  = "local"  // The vault's .trash folder
+	OptionNone   = "none"   // Delete permanently
+)
+
+// LocalDir is the vault's trash folder, relative to the vault
+const LocalDir = ".trash"
+
+// metadataFile records where files in the local trash came from. Obsidian
+// does not record this, so its own trashed files restore to the vault root.
+const metadataFile = ".ob-cli.json"
+
+// Item is a trashed file
+type Item struct {
+	Name    string    `json:"name"`    // Name in the trash
+	Path    string    `json:"path"`    // Original vault-relative path
+	Deleted time.Time `json:"deleted"`
+	System  bool      `json:"system"` // In the system trash rather than .trash
+
+	file string // Absolute path of the trashed file
+	info string // Absolute path of the freedesktop .trashinfo file
+}
+
+// Trash manages the trashed files of one vault
+type Trash struct {
+	root      string
+	systemDir string // freedesktop trash directory; empty when unsupported
+}
+
+// New creates the trash of the vault at root, using the freedesktop.org
+// trash ($XDG_DATA_HOME/Trash) as system trash where there is one
+func New(root string) *Trash {
+	return &Trash{root: root, systemDir: freedesktopDir()}
+}
+
+// Option returns the vault's trashOption setting
+func (t *Trash) Option() string {
+	var settings struct {
+		TrashOption string `json:"trashOption"`
+	}
+	if data, err := os.ReadFile(filepath.Join(t.root, ".obsidian", "app.json")); err == nil {
+		json.Unmarshal(data, &settings) // Fall back to the default on bad JSON
+	}
+	switch settings.TrashOption {
+	case OptionLocal, OptionNone:
+		return settings.TrashOption
+	}
+	return OptionSystem
+}
+
+// Delete removes a vault file according to the trashOption setting and
+// returns the option applied. When the system trash is unavailable, the
+// file goes to .trash instead.
+func (t *Trash) Delete(rel string, now time.Time) (string, error) {
+	switch t.Option() {
+	case OptionNone:
+		if err := os.Remove(filepath.Join(t.root, rel)); err != nil {
+			return "", err
+		}
+		return OptionNone, nil
+	case OptionSystem:
+		if _, err := t.MoveToSystem(rel, now); err == nil {
+			return OptionSystem, nil
+		} else if errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	if _, err := t.MoveToLocal(rel, now); err != nil {
+		return "", err
+	}
+	return OptionLocal, nil
+}
+
+// MoveToLocal moves a vault file into the vault's .trash folder
+func (t *Trash) MoveToLocal(rel string, now time.Time) (Item, error) {
+	dir := filepath.Join(t.root, LocalDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return Item{}, err
+	}
+	name := freeName(dir, filepath.Base(rel))
+	item := Item{Name: name, Path: rel, Deleted: now, file: filepath.Join(dir, name)}
+	if err := os.Rename(filepath.Join(t.root, rel), item.file); err != nil {
+		return Item{}, err
+	}
+
+	meta := t.loadMetadata()
+	meta[name] = item
+	return item, t.saveMetadata(meta)
+}
+
+// MoveToSystem moves a vault file into the system trash
+func (t *Trash) MoveToSystem(rel string, now time.Time) (Item, error) {
+	src := filepath.Join(t.root, rel)
+	if _, err := os.Lstat(src); err != nil {
+		return Item{}, err
+	}
+	if runtime.GOOS == "darwin" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Item{}, err
+		}
+		dir := filepath.Join(home, ".Trash")
+		name := freeName(dir, filepath.Base(rel))
+		return Item{Name: name, Path: rel, Deleted: now, System: true}, os.Rename(src, filepath.Join(dir, name))
+	}
+	if t.systemDir == "" {
+		return Item{}, fmt.Errorf("no system trash on %s", runtime.GOOS)
+	}
+
+	files, infos := filepath.Join(t.systemDir, "files"), filepath.Join(t.systemDir, "info")
+	for _, dir := range []string{files, infos} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return Item{}, err
+		}
+	}
+
+	// Reserve the name by creating the info file exclusively, as the
+	// freedesktop.org trash specification asks
+	abs, err := filepath.Abs(src)
+	if err != nil {
+		return Item{}, err
+	}
+	base := filepath.Base(rel)
+	for i := 0; ; i++ {
+		name := numbered(base, i)
+		info := filepath.Join(infos, name+".trashinfo")
+		f, err := os.OpenFile(info, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return Item{}, err
+		}
+		_, err = fmt.Fprintf(f, "[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+			(&url.URL{Path: abs}).EscapedPath(), now.Format("2006-01-02T15:04:05"))
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(src, filepath.Join(files, name))
+		}
+		if err != nil {
+			os.Remove(info) // E.g. the vault is on another filesystem
+			return Item{}, err
+		}
+		return Item{Name: name, Path: rel, Deleted: now, System: true, file: filepath.Join(files, name), info: info}, nil
+	}
+}
+
+// List returns the files in the vault's .trash and the system trash files
+// that came from this vault, most recently deleted first
+func (t *Trash) List() ([]Item, error) {
+	var items []Item
+
+	dir, err := t.localDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	meta := t.loadMetadata()
+	for _, entry := range entries {
+		if entry.Name() == metadataFile {
+			continue
+		}
+		item, ok := meta[entry.Name()]
+		if !ok {
+			item = Item{Name: entry.Name(), Path: entry.Name()}
+			if info, err := entry.Info(); err == nil {
+				item.Deleted = info.ModTime()
+			}
+		}
+		item.file = filepath.Join(dir, entry.Name())
+		items = append(items, item)
+	}
+
+	if t.systemDir != "" {
+		system, err := t.listSystem()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, system...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Deleted.After(items[j].Deleted) })
+	return items, nil
+}
+
+// listSystem returns the freedesktop trash entries from this vault
+func (t *Trash) listSystem() ([]Item, error) {
+	root, err := filepath.Abs(t.root)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := filepath.Glob(filepath.Join(t.systemDir, "info", "*.trashinfo"))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	for _, info := range infos {
+		path, deleted, err := readTrashInfo(info)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // Another vault's or any other file
+		}
+		name := strings.TrimSuffix(filepath.Base(info), ".trashinfo")
+		items = append(items, Item{
+			Name:    name,
+			Path:    rel,
+			Deleted: deleted,
+			System:  true,
+			file:    filepath.Join(t.systemDir, "files", name),
+			info:    info,
+		})
+	}
+	return items, nil
+}
+
+// Restore moves a trashed file back to its original path, refusing to
+// overwrite a file that has taken its place or to restore outside the
+// vault
+func (t *Trash) Restore(item Item) error {
+	if err := t.check(item); err != nil {
+		return err
+	}
+	dst, err := t.vaultPath(item.Path)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Lstat(dst); err == nil {
+		return fmt.Errorf("%s already exists", item.Path)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(item.file, dst); err != nil {
+		return err
+	}
+	return t.forget(item)
+}
+
+// Empty permanently deletes every item List returns
+func (t *Trash) Empty() (int, error) {
+	items, err := t.List()
+	if err != nil {
+		return 0, err
+	}
+	for i, item := range items {
+		if err := t.check(item); err != nil {
+			return i, err
+		}
+		if err := os.RemoveAll(item.file); err != nil {
+			return i, err
+		}
+		if err := t.forget(item); err != nil {
+			return i, err
+		}
+	}
+	return len(items), nil
+}
+
+// localDir returns the vault's .trash folder, refusing one that is a
+// symlink: emptying it would delete files outside the vault
+func (t *Trash) localDir() (string, error) {
+	dir := filepath.Join(t.root, LocalDir)
+	if info, err := os.Lstat(dir); err == nil && !info.IsDir() {
+		return "", errs.New(errs.ErrInvalidPath, "%s is not a folder", LocalDir)
+	}
+	return dir, nil
+}
+
+// check refuses items that are not files of this vault's trash, such as
+// an Item built by hand
+func (t *Trash) check(item Item) error {
+	dir := filepath.Join(t.root, LocalDir)
+	if item.System {
+		dir = filepath.Join(t.systemDir, "files")
+	}
+	if item.file == "" || !filepath.IsLocal(item.Name) || item.file != filepath.Join(dir, item.Name) || (item.System && t.systemDir == "") {
+		return errs.New(errs.ErrInvalidPath, "%s is not in the trash", item.Name)
+	}
+	return nil
+}
+
+// vaultPath resolves a trashed file's original path in the vault, refusing
+// paths that leave the vault, directly or through a symlinked folder
+func (t *Trash) vaultPath(rel string) (string, error) {
+	outside := errs.New(errs.ErrInvalidPath, "%s is outside the vault", rel)
+	if !filepath.IsLocal(rel) {
+		return "", outside
+	}
+	root, err := filepath.EvalSymlinks(t.root)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(t.root, rel)
+	// The nearest existing folder, as missing ones are created
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		resolved, err := filepath.EvalSymlinks(dir)
+		if os.IsNotExist(err) && dir != t.root {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if r, err := filepath.Rel(root, resolved); err != nil || !filepath.IsLocal(r) {
+			return "", outside
+		}
+		return path, nil
+	}
+}
+
+// forget drops the record of an item that left the trash
+func (t *Trash) forget(item Item) error {
+	if item.info != "" {
+		return os.Remove(item.info)
+	}
+	if item.System {
+		return nil
+	}
+	meta := t.loadMetadata()
+	if _, ok := meta[item.Name]; !ok {
+		return nil
+	}
+	delete(meta, item.Name)
+	return t.saveMetadata(meta)
+}
+
+func (t *Trash) loadMetadata() map[string]Item {
+	meta := map[string]Item{}
+	if data, err := os.ReadFile(filepath.Join(t.root, LocalDir, metadataFile)); err == nil {
+		json.Unmarshal(data, &meta) // A corrupt record only loses original paths
+	}
+	return meta
+}
+
+func (t *Trash) saveMetadata(meta map[string]Item) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(t.root, LocalDir, metadataFile), data, 0644)
+}
+
+// readTrashInfo parses a freedesktop .trashinfo file
+func readTrashInfo(file string) (string, time.Time, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var path string
+	var deleted time.Time
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), "=")
+		switch key {
+		case "Path":
+			if path, err = url.PathUnescape(value); err != nil {
+				return "", time.Time{}, err
+			}
+		case "DeletionDate":
+			deleted, _ = time.ParseInLocation("2006-01-02T15:04:05", value, time.Local)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return "", time.Time{}, fmt.Errorf("no absolute path in %s", file)
+	}
+	return filepath.FromSlash(path), deleted, nil
+}
+
+// freedesktopDir returns the user's freedesktop.org trash directory on
+// systems that use one
+func freedesktopDir() string {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return ""
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "Trash")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", "Trash")
+	}
+	return ""
+}
+
+// freeName returns name, or name with a number before its extension, that
+// does not exist in dir yet
+func freeName(dir, name string) string {
+	for i := 0; ; i++ {
+		candidate := numbered(name, i)
+		if _, err := os.Lstat(filepath.Join(dir, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// numbered returns "name.md" for 0 and "name 1.md", "name 2.md", ... after
+func numbered(name string, i int) string {
+	if i == 0 {
+		return name
+	}
+	ext := filepath.Ext(name)
+	return fmt.Sprintf("%s %d%s", strings.TrimSuffix(name, ext), i, ext)
+}
@@ -0,0 +1,169 @@
+package trash
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shalomb/ob-cli/internal/errs"
+)
+
+// newTestVault creates a vault with notes and the given trashOption, and a
+// trash with its own system trash directory
+func newTestVault(t *testing.T, option string, notes ...string) (string, *Trash) {
+	t.Helper()
+	root := t.TempDir()
+	for _, note := range notes {
+		path := filepath.Join(root, note)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(note), 0644)
+	}
+	if option != "" {
+		os.MkdirAll(filepath.Join(root, ".obsidian"), 0755)
+		os.WriteFile(filepath.Join(root, ".obsidian", "app.json"), []byte(`{"trashOption":"`+option+`"}`), 0644)
+	}
+	return root, &Trash{root: root, systemDir: filepath.Join(t.TempDir(), "Trash")}
+}
+
+func TestTrash_Option(t *testing.T) {
+	for option, expected := range map[string]string{"": OptionSystem, "local": OptionLocal, "none": OptionNone, "bogus": OptionSystem} {
+		_, trash := newTestVault(t, option)
+		if got := trash.Option(); got != expected {
+			t.Errorf("Option() with %q = %q, expected %q", option, got, expected)
+		}
+	}
+}
+
+func TestTrash_LocalRoundTrip(t *testing.T) {
+	root, trash := newTestVault(t, "local", "a/note.md", "b/note.md")
+	now := time.Date(2024, 3, 5, 9, 0, 0, 0, time.Local)
+
+	for i, note := range []string{"a/note.md", "b/note.md"} {
+		applied, err := trash.Delete(note, now.Add(time.Duration(i)*time.Minute))
+		if err != nil || applied != OptionLocal {
+			t.Fatalf("Delete(%s) = %q, %v", note, applied, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, ".trash", "note 1.md")); err != nil {
+		t.Errorf("second note.md not renamed in .trash: %v", err)
+	}
+
+	items, err := trash.List()
+	if err != nil || len(items) != 2 {
+		t.Fatalf("List() = %+v, %v", items, err)
+	}
+	if items[0].Name != "note 1.md" || items[0].Path != "b/note.md" || items[0].System {
+		t.Errorf("newest item = %+v", items[0])
+	}
+
+	if err := trash.Restore(items[0]); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "b", "note.md")); string(data) != "b/note.md" {
+		t.Errorf("restored content = %q", data)
+	}
+
+	os.WriteFile(filepath.Join(root, "a", "note.md"), []byte("new"), 0644)
+	if err := trash.Restore(items[1]); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Restore() over an existing note error = %v", err)
+	}
+
+	if n, err := trash.Empty(); err != nil || n != 1 {
+		t.Errorf("Empty() = %d, %v", n, err)
+	}
+	if items, _ := trash.List(); len(items) != 0 {
+		t.Errorf("List() after Empty() = %+v", items)
+	}
+}
+
+func TestTrash_System(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("macOS uses ~/.Trash")
+	}
+	root, trash := newTestVault(t, "", "my note.md", "other.md")
+	now := time.Date(2024, 3, 5, 9, 0, 0, 0, time.Local)
+
+	if applied, err := trash.Delete("my note.md", now); err != nil || applied != OptionSystem {
+		t.Fatalf("Delete() = %q, %v", applied, err)
+	}
+	info, err := os.ReadFile(filepath.Join(trash.systemDir, "info", "my note.md.trashinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs(filepath.Join(root, "my note.md"))
+	expected := "[Trash Info]\nPath=" + strings.ReplaceAll(abs, " ", "%20") + "\nDeletionDate=2024-03-05T09:00:00\n"
+	if string(info) != expected {
+		t.Errorf("trashinfo = %q, expected %q", info, expected)
+	}
+
+	// Entries from elsewhere are not this vault's
+	os.WriteFile(filepath.Join(trash.systemDir, "info", "x.trashinfo"), []byte("[Trash Info]\nPath=/elsewhere/x.md\n"), 0600)
+
+	items, err := trash.List()
+	if err != nil || len(items) != 1 || items[0].Path != "my note.md" || !items[0].System || !items[0].Deleted.Equal(now) {
+		t.Fatalf("List() = %+v, %v", items, err)
+	}
+	if err := trash.Restore(items[0]); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(trash.systemDir, "info", "my note.md.trashinfo")); !os.IsNotExist(err) {
+		t.Errorf("trashinfo left behind after restore: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "my note.md")); err != nil {
+		t.Errorf("note not restored: %v", err)
+	}
+}
+
+func TestTrash_DeletePermanently(t *testing.T) {
+	root, trash := newTestVault(t, "none", "note.md")
+
+	if applied, err := trash.Delete("note.md", time.Now()); err != nil || applied != OptionNone {
+		t.Fatalf("Delete() = %q, %v", applied, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "note.md")); !os.IsNotExist(err) {
+		t.Errorf("note still exists: %v", err)
+	}
+	if _, err := trash.Delete("missing.md", time.Now()); err == nil {
+		t.Error("Delete() of a missing note succeeded")
+	}
+}
+
+func TestTrash_Unsafe(t *testing.T) {
+	root, trash := newTestVault(t, "local", "note.md")
+	if _, err := trash.Delete("note.md", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(root, ".trash", metadataFile), []byte(`{"note.md":{"name":"note.md","path":"../escaped.md"}}`), 0644)
+
+	items, err := trash.List()
+	if err != nil || len(items) != 1 {
+		t.Fatalf("List() = %+v, %v", items, err)
+	}
+	if err := trash.Restore(items[0]); !errors.Is(err, errs.ErrInvalidPath) {
+		t.Errorf("Restore() outside the vault error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escaped.md")); !os.IsNotExist(err) {
+		t.Errorf("note restored outside the vault: %v", err)
+	}
+	if err := trash.Restore(Item{Name: "note.md", Path: "note.md"}); !errors.Is(err, errs.ErrInvalidPath) {
+		t.Errorf("Restore() of an item not from List error = %v", err)
+	}
+
+	// A .trash symlink must not let Empty delete what it points at
+	_, linked := newTestVault(t, "local")
+	outside := t.TempDir()
+	os.WriteFile(filepath.Join(outside, "keep.md"), []byte("keep"), 0644)
+	if err := os.Symlink(outside, filepath.Join(linked.root, LocalDir)); err != nil {
+		t.Skip("symlinks unsupported")
+	}
+	if _, err := linked.Empty(); !errors.Is(err, errs.ErrInvalidPath) {
+		t.Errorf("Empty() through a symlinked .trash error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "keep.md")); err != nil {
+		t.Errorf("file outside the vault deleted: %v", err)
+	}
+}
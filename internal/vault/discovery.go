@@ -1,13 +1,17 @@
 package vault
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/shalomb/ob-cli/internal/errs"
 )
 
-// Discoverer handles vault discovery
+// Discoverer handles vault discovery. Its searches stop with ctx's error
+// when ctx is done.
 type Discoverer struct{}
 
 // NewDiscoverer creates a new vault discoverer
@@ -16,21 +20,21 @@ func NewDiscoverer() *Discoverer {
 }
 
 // DiscoverObsidianVault finds the Obsidian vault location (12-factor approach)
-func (d *Discoverer) DiscoverObsidianVault() (string, error) {
+func (d *Discoverer) DiscoverObsidianVault(ctx context.Context) (string, error) {
 	// 1. Environment variable (12-factor app principle)
 	if vaultPath := os.Getenv("OBSIDIAN_VAULT"); vaultPath != "" {
 		if d.IsValidVault(vaultPath) {
 			return vaultPath, nil
 		}
-		return "", fmt.Errorf("invalid vault path in OBSIDIAN_VAULT: %s", vaultPath)
+		return "", errs.New(errs.ErrVaultNotFound, "invalid vault path in OBSIDIAN_VAULT: %s", vaultPath)
 	}
 
 	// 2. Fast fallback discovery (2-level deep only)
-	return d.FastObsidianDiscovery()
+	return d.FastObsidianDiscovery(ctx)
 }
 
 // FastObsidianDiscovery performs fast vault discovery
-func (d *Discoverer) FastObsidianDiscovery() (string, error) {
+func (d *Discoverer) FastObsidianDiscovery(ctx context.Context) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -44,6 +48,9 @@ func (d *Discoverer) FastObsidianDiscovery() (string, error) {
 	}
 
 	for _, path := range commonPaths {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		if d.IsValidVault(path) {
 			return path, nil
 		}
@@ -54,21 +61,21 @@ func (d *Discoverer) FastObsidianDiscovery() (string, error) {
 }
 
 // DiscoverTipsVault finds the Tips vault location (12-factor approach)
-func (d *Discoverer) DiscoverTipsVault() (string, error) {
+func (d *Discoverer) DiscoverTipsVault(ctx context.Context) (string, error) {
 	// 1. Environment variable (12-factor app principle)
 	if vaultPath := os.Getenv("TIPS_VAULT"); vaultPath != "" {
 		if d.IsValidVault(vaultPath) {
 			return vaultPath, nil
 		}
-		return "", fmt.Errorf("invalid vault path in TIPS_VAULT: %s", vaultPath)
+		return "", errs.New(errs.ErrVaultNotFound, "invalid vault path in TIPS_VAULT: %s", vaultPath)
 	}
 
 	// 2. Fast fallback discovery (2-level deep only)
-	return d.FastTipsDiscovery()
+	return d.FastTipsDiscovery(ctx)
 }
 
 // FastTipsDiscovery performs fast Tips vault discovery
-func (d *Discoverer) FastTipsDiscovery() (string, error) {
+func (d *Discoverer) FastTipsDiscovery(ctx context.Context) (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -84,6 +91,9 @@ func (d *Discoverer) FastTipsDiscovery() (string, error) {
 	}
 
 	for _, path := range commonPaths {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		if d.IsValidVault(path) {
 			return path, nil
 		}
@@ -94,9 +104,9 @@ func (d *Discoverer) FastTipsDiscovery() (string, error) {
 }
 
 // FindVaultInDirectory searches for vaults in a directory
-func (d *Discoverer) FindVaultInDirectory(basePath string) (string, error) {
+func (d *Discoverer) FindVaultInDirectory(ctx context.Context, basePath string) (string, error) {
 	if _, err := os.Stat(basePath); os.IsNotExist(err) {
-		return "", fmt.Errorf("directory does not exist")
+		return "", errs.New(errs.ErrVaultNotFound, "directory does not exist")
 	}
 
 	entries, err := os.ReadDir(basePath)
@@ -106,6 +116,9 @@ func (d *Discoverer) FindVaultInDirectory(basePath string) (string, error) {
 
 	// Look for directories that might be vaults
 	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
 		if !entry.IsDir() {
 			continue
 		}
@@ -116,14 +129,48 @@ func (d *Discoverer) FindVaultInDirectory(basePath string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("no vault found in directory")
+	return "", errs.New(errs.ErrVaultNotFound, "no vault found in directory")
+}
+
+// FindVaultRoot returns the vault containing path: the nearest ancestor
+// with an .obsidian directory, else the nearest git work tree that is a
+// valid vault
+func (d *Discoverer) FindVaultRoot(ctx context.Context, path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	gitRoot := ""
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if info, err := os.Stat(filepath.Join(dir, ".obsidian")); err == nil && info.IsDir() {
+			return dir, nil
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil && gitRoot == "" && d.isValidVault(dir) {
+			gitRoot = dir
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+
+	if gitRoot != "" {
+		return gitRoot, nil
+	}
+	return "", errs.New(errs.ErrVaultNotFound, "no vault found containing %s", path)
 }
 
 // searchForObsidianConfig searches for .obsidian directories
-func (d *Discoverer) searchForObsidianConfig(startPath string) (string, error) {
+func (d *Discoverer) searchForObsidianConfig(ctx context.Context, startPath string) (string, error) {
 	var foundVault string
 	
 	err := filepath.Walk(startPath, func(path string, info os.FileInfo, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 		if err != nil {
 			return nil // Skip errors, continue searching
 		}
@@ -156,7 +203,7 @@ func (d *Discoverer) searchForObsidianConfig(startPath string) (string, error) {
 		return foundVault, nil
 	}
 
-	return "", fmt.Errorf("no Obsidian vault found")
+	return "", errs.New(errs.ErrVaultNotFound, "no Obsidian vault found")
 }
 
 // isValidVault checks if a path is a valid vault
@@ -1,6 +1,7 @@
 package vault
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -25,7 +26,7 @@ func TestDiscoverer_DiscoverObsidianVault(t *testing.T) {
 		os.Setenv("OBSIDIAN_VAULT", tempDir)
 		defer os.Unsetenv("OBSIDIAN_VAULT")
 		
-		vaultPath, err := discoverer.DiscoverObsidianVault()
+		vaultPath, err := discoverer.DiscoverObsidianVault(context.Background())
 		if err != nil {
 			t.Errorf("DiscoverObsidianVault failed: %v", err)
 		}
@@ -40,7 +41,7 @@ func TestDiscoverer_DiscoverObsidianVault(t *testing.T) {
 		os.Setenv("OBSIDIAN_VAULT", "/non/existent/path")
 		defer os.Unsetenv("OBSIDIAN_VAULT")
 		
-		_, err := discoverer.DiscoverObsidianVault()
+		_, err := discoverer.DiscoverObsidianVault(context.Background())
 		if err == nil {
 			t.Error("Expected error for invalid vault path, got nil")
 		}
@@ -70,7 +71,7 @@ func TestDiscoverer_DiscoverTipsVault(t *testing.T) {
 		os.Setenv("TIPS_VAULT", tempDir)
 		defer os.Unsetenv("TIPS_VAULT")
 		
-		vaultPath, err := discoverer.DiscoverTipsVault()
+		vaultPath, err := discoverer.DiscoverTipsVault(context.Background())
 		if err != nil {
 			t.Errorf("DiscoverTipsVault failed: %v", err)
 		}
@@ -85,7 +86,7 @@ func TestDiscoverer_DiscoverTipsVault(t *testing.T) {
 		os.Setenv("TIPS_VAULT", "/non/existent/path")
 		defer os.Unsetenv("TIPS_VAULT")
 		
-		_, err := discoverer.DiscoverTipsVault()
+		_, err := discoverer.DiscoverTipsVault(context.Background())
 		if err == nil {
 			t.Error("Expected error for invalid vault path, got nil")
 		}
@@ -169,7 +170,7 @@ func TestDiscoverer_FindVaultInDirectory(t *testing.T) {
 			t.Fatalf("Failed to create .obsidian directory: %v", err)
 		}
 		
-		vaultPath, err := discoverer.FindVaultInDirectory(baseDir)
+		vaultPath, err := discoverer.FindVaultInDirectory(context.Background(), baseDir)
 		if err != nil {
 			t.Errorf("FindVaultInDirectory failed: %v", err)
 		}
@@ -183,9 +184,49 @@ func TestDiscoverer_FindVaultInDirectory(t *testing.T) {
 		// Create empty directory
 		baseDir := t.TempDir()
 		
-		_, err := discoverer.FindVaultInDirectory(baseDir)
+		_, err := discoverer.FindVaultInDirectory(context.Background(), baseDir)
 		if err == nil {
 			t.Error("Expected error when no vault found, got nil")
 		}
 	})
-}
\ No newline at end of file
+}
+
+func TestDiscoverer_FindVaultRoot(t *testing.T) {
+	discoverer := NewDiscoverer()
+	tempDir := t.TempDir()
+
+	obsidianVault := filepath.Join(tempDir, "repo", "vault")
+	os.MkdirAll(filepath.Join(obsidianVault, ".obsidian"), 0755)
+	os.MkdirAll(filepath.Join(obsidianVault, "notes", "deep"), 0755)
+	os.MkdirAll(filepath.Join(tempDir, "repo", ".git"), 0755)
+	os.WriteFile(filepath.Join(tempDir, "repo", "README.md"), []byte("# Repo"), 0644)
+
+	tipsVault := filepath.Join(tempDir, "tips")
+	os.MkdirAll(filepath.Join(tipsVault, ".git"), 0755)
+	os.MkdirAll(filepath.Join(tipsVault, "topics"), 0755)
+	os.WriteFile(filepath.Join(tipsVault, "index.md"), []byte("# Tips"), 0644)
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+		wantErr  bool
+	}{
+		{"obsidian vault wins over enclosing git repo", filepath.Join(obsidianVault, "notes", "deep", "a.md"), obsidianVault, false},
+		{"git work tree", filepath.Join(tipsVault, "topics", "go.md"), tipsVault, false},
+		{"git root outside obsidian vault", filepath.Join(tempDir, "repo", "README.md"), filepath.Join(tempDir, "repo"), false},
+		{"no vault", filepath.Join(tempDir, "elsewhere", "x.md"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := discoverer.FindVaultRoot(context.Background(), tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindVaultRoot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if root != tt.expected {
+				t.Errorf("FindVaultRoot() = %q, expected %q", root, tt.expected)
+			}
+		})
+	}
+}
@@ -57,7 +57,7 @@ func TestAppIntegration_CompleteWorkflow(t *testing.T) {
 	
 	// Test ListFiles
 	t.Run("ListFiles", func(t *testing.T) {
-		err := obApp.ListFiles()
+		err := obApp.ListFiles(app.Output{})
 		if err != nil {
 			t.Errorf("ListFiles failed: %v", err)
 		}
@@ -65,7 +65,7 @@ func TestAppIntegration_CompleteWorkflow(t *testing.T) {
 	
 	// Test ShowGitStatus (should work even without git repo)
 	t.Run("ShowGitStatus", func(t *testing.T) {
-		err := obApp.ShowGitStatus()
+		err := obApp.ShowGitStatus(app.Output{})
 		// This might fail if no git repo, which is expected
 		if err != nil {
 			t.Logf("ShowGitStatus failed (expected for non-git directory): %v", err)